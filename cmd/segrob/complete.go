@@ -9,6 +9,7 @@ var commands = []string{
 	"corpus",
 	"env",
 	"live",
+	"serve",
 	"bash",
 	"version",
 	"help",
@@ -26,6 +27,7 @@ var corpusSubcommands = []string{
 	"dump-txt",
 	"dump-nlp",
 	"ingest-nlp",
+	"ingest-conllu",
 	"ingest-meta",
 	"push-txt",
 	"ls-label",
@@ -51,6 +53,20 @@ var liveSubcommands = []string{
 	"show-topic",
 	"dump-topic",
 	"query",
+	"report",
+	"export-cloze",
+	"drill",
+	"dispersion",
+	"collocates",
+	"keyness",
+	"lemmas",
+	"export",
+	"graded",
+	"paradigm",
+	"stats",
+	"reindex-topics",
+	"topic-stats",
+	"timeline",
 }
 
 // completeCommand handles the autocompletion requests triggered by the bash completion script.
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"container/heap"
+	"context"
+	"sort"
+
+	"github.com/revelaction/segrob/match"
+	"github.com/revelaction/segrob/search"
+	"github.com/revelaction/segrob/stat"
+)
+
+// hasComplexityOptions reports whether the matches of live find must be
+// filtered or sorted by their complexity.
+func hasComplexityOptions(opts LiveFindOptions) bool {
+	return opts.MinTokens > 0 || opts.MaxTokens > 0 || opts.MaxClauses > 0 || opts.MaxDepth > 0 ||
+		opts.MinReadability != nil || opts.MaxReadability != nil || opts.Sort != "rowid"
+}
+
+// withinComplexity reports whether a sentence is within the complexity
+// bounds of the options.
+func withinComplexity(c stat.Complexity, opts LiveFindOptions) bool {
+	switch {
+	case opts.MinTokens > 0 && c.Tokens < opts.MinTokens:
+		return false
+	case opts.MaxTokens > 0 && c.Tokens > opts.MaxTokens:
+		return false
+	case opts.MaxClauses > 0 && c.Clauses > opts.MaxClauses:
+		return false
+	case opts.MaxDepth > 0 && c.Depth > opts.MaxDepth:
+		return false
+	case opts.MinReadability != nil && c.Readability < *opts.MinReadability:
+		return false
+	case opts.MaxReadability != nil && c.Readability > *opts.MaxReadability:
+		return false
+	}
+	return true
+}
+
+// complexMatch is a match within the complexity bounds and its sort key.
+type complexMatch struct {
+	*match.SentenceMatch
+	key float64
+	seq int // scan order, for stable ties
+}
+
+// complexBefore reports whether a sorts before b: a lower key, then scan
+// order.
+func complexBefore(a, b complexMatch) bool {
+	if a.key != b.key {
+		return a.key < b.key
+	}
+	return a.seq < b.seq
+}
+
+// complexHeap keeps the simplest matches seen so far, the most complex at
+// the root so that it is dropped when a simpler one comes.
+type complexHeap []complexMatch
+
+func (h complexHeap) Len() int           { return len(h) }
+func (h complexHeap) Less(i, j int) bool { return complexBefore(h[j], h[i]) }
+func (h complexHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *complexHeap) Push(x any)        { *h = append(*h, x.(complexMatch)) }
+
+func (h *complexHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// complexityKey is the sort key of a sentence, lower is simpler: fewer
+// tokens, clauses or depth, or a higher readability.
+func complexityKey(c stat.Complexity, sort string) float64 {
+	switch sort {
+	case "tokens":
+		return float64(c.Tokens)
+	case "clauses":
+		return float64(c.Clauses)
+	case "depth":
+		return float64(c.Depth)
+	default:
+		return -c.Readability
+	}
+}
+
+// collectComplexity collects the matches within the complexity bounds, at
+// most opts.Limit. Sorted results are sorted simplest first; only the
+// opts.Limit simplest matches are kept while scanning.
+func collectComplexity(ctx context.Context, s *search.Search, opts LiveFindOptions) ([]*match.SentenceMatch, error) {
+	var results []*match.SentenceMatch
+	var simplest complexHeap
+	seq := 0
+	for sm, err := range s.All(ctx) {
+		if err != nil {
+			return nil, err
+		}
+
+		c := stat.Measure(sm.Sentence.Tokens)
+		if !withinComplexity(c, opts) {
+			continue
+		}
+
+		if opts.Sort == "rowid" {
+			results = append(results, sm)
+			if opts.Limit > 0 && len(results) == opts.Limit {
+				break
+			}
+			continue
+		}
+
+		seq++
+		heap.Push(&simplest, complexMatch{SentenceMatch: sm, key: complexityKey(c, opts.Sort), seq: seq})
+		if opts.Limit > 0 && simplest.Len() > opts.Limit {
+			heap.Pop(&simplest)
+		}
+	}
+
+	if opts.Sort != "rowid" {
+		sorted := []complexMatch(simplest)
+		sort.Slice(sorted, func(i, j int) bool { return complexBefore(sorted[i], sorted[j]) })
+		for _, m := range sorted {
+			results = append(results, m.SentenceMatch)
+		}
+	}
+
+	return results, nil
+}
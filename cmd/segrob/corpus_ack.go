@@ -1,17 +1,18 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/revelaction/segrob/storage"
 )
 
-func corpusAckCommand(repo storage.CorpusRepository, opts CorpusAckOptions, ui UI) error {
+func corpusAckCommand(ctx context.Context, repo storage.CorpusRepository, opts CorpusAckOptions, ui UI) error {
 	var err error
 	if opts.Nlp {
-		err = repo.AckNlp(opts.ID, opts.By)
+		err = repo.AckNlp(ctx, opts.ID, opts.By)
 	} else {
-		err = repo.AckTxt(opts.ID, opts.By)
+		err = repo.AckTxt(ctx, opts.ID, opts.By)
 	}
 
 	if err != nil {
@@ -2,6 +2,7 @@ package main
 
 import (
 	"compress/gzip"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -14,7 +15,7 @@ import (
 
 // corpusBackupCommand populates the destination backup database using source interfaces.
 // It expects the dstMgr to have initialized the schema.
-func corpusBackupCommand(
+func corpusBackupCommand(ctx context.Context,
 	srcRepo storage.CorpusReader,
 	srcTopics storage.TopicReader,
 	dstMgr storage.SchemaManager,
@@ -28,13 +29,13 @@ func corpusBackupCommand(
 		err = errors.Join(err, os.Remove(tempPath))
 	}()
 
-	err = dstMgr.Create("corpus.sql")
+	err = dstMgr.Create(ctx, "corpus.sql")
 	if err != nil {
 		return fmt.Errorf("failed to create backup schemas: %w", err)
 	}
 
 	// List source corpus and copy rows via WriteStream + backupIterator
-	metas, lErr := srcRepo.List()
+	metas, lErr := srcRepo.List(ctx)
 	if lErr != nil {
 		return fmt.Errorf("failed to list corpus: %w", lErr)
 	}
@@ -44,20 +45,20 @@ func corpusBackupCommand(
 		return pErr
 	}
 
-	seq := backupIterator(srcRepo, metas, opts.WithNlp)
-	err = dstRepo.WriteStream(seq)
+	seq := backupIterator(ctx, srcRepo, metas, opts.WithNlp)
+	err = dstRepo.WriteStream(ctx, seq)
 	if err != nil {
 		return err
 	}
 
 	// Copy topics
-	topics, rErr := srcTopics.ReadAll("")
+	topics, rErr := srcTopics.ReadAll(ctx, "")
 	if rErr != nil {
 		return fmt.Errorf("failed to read topics: %w", rErr)
 	}
 
 	for _, tp := range topics {
-		_, wErr := dstTopics.Upsert("", tp, nil)
+		_, wErr := dstTopics.Upsert(ctx, "", tp, nil)
 		if wErr != nil {
 			return fmt.Errorf("failed to write topic %s: %w", tp.Name, wErr)
 		}
@@ -95,10 +96,10 @@ func corpusBackupCommand(
 // backupIterator returns an iter.Seq2 that yields CorpusRecord values
 // for each meta. It reads the txt field from the source repo and combines
 // it with the metadata. On error, it yields the error and halts.
-func backupIterator(srcRepo storage.CorpusReader, metas []storage.CorpusMeta, withNlp bool) func(yield func(storage.CorpusRecord, error) bool) {
+func backupIterator(ctx context.Context, srcRepo storage.CorpusReader, metas []storage.CorpusMeta, withNlp bool) func(yield func(storage.CorpusRecord, error) bool) {
 	return func(yield func(storage.CorpusRecord, error) bool) {
 		for _, m := range metas {
-			txt, err := srcRepo.ReadTxt(m.ID)
+			txt, err := srcRepo.ReadTxt(ctx, m.ID)
 			if err != nil {
 				yield(storage.CorpusRecord{}, fmt.Errorf("failed to read txt for %s: %w", m.ID, err))
 				return
@@ -107,7 +108,7 @@ func backupIterator(srcRepo storage.CorpusReader, metas []storage.CorpusMeta, wi
 			record := storage.CorpusRecord{CorpusMeta: m, Txt: string(txt)}
 
 			if withNlp {
-				nlp, err := srcRepo.ReadNlp(m.ID)
+				nlp, err := srcRepo.ReadNlp(ctx, m.ID)
 				if err != nil {
 					yield(storage.CorpusRecord{}, fmt.Errorf("failed to read nlp for %s: %w", m.ID, err))
 					return
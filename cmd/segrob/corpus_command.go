@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -28,6 +29,7 @@ func printCorpusUsage(w io.Writer) {
 
 	_, _ = fmt.Fprintf(w, "\nSubcommands: Ingest\n")
 	_, _ = fmt.Fprintf(w, helpCmdFmt, "ingest-nlp", "Process document text with NLP and store in corpus.")
+	_, _ = fmt.Fprintf(w, helpCmdFmt, "ingest-conllu", "Store CoNLL-U annotations as a document's NLP data.")
 	_, _ = fmt.Fprintf(w, helpCmdFmt, "ingest-meta", "Scan a directory for epub files and build a corpus database.")
 	_, _ = fmt.Fprintf(w, helpCmdFmt, "push-txt", "Update a corpus document text from a file.")
 	_, _ = fmt.Fprintf(w, helpCmdFmt, "ingest-topic", "Ingest topics from a JSON file into the corpus database.")
@@ -50,7 +52,7 @@ func printCorpusUsage(w io.Writer) {
 	_, _ = fmt.Fprintf(w, helpCmdFmt, "edit", "Enter interactive edit mode.")
 }
 
-func runCorpusCommand(args []string, setup *Setup, ui UI) error {
+func runCorpusCommand(ctx context.Context, args []string, setup *Setup, ui UI) error {
 	if len(args) < 1 {
 		printCorpusUsage(ui.Err)
 		return fmt.Errorf("corpus requires a subcommand")
@@ -74,7 +76,7 @@ func runCorpusCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return corpusInitCommand(mgr, opts, ui)
+		return corpusInitCommand(ctx, mgr, opts, ui)
 
 	case "ls":
 		opts, err := parseCorpusLsArgs(subArgs, ui)
@@ -85,7 +87,7 @@ func runCorpusCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return corpusLsCommand(repo, opts, ui)
+		return corpusLsCommand(ctx, repo, opts, ui)
 
 	case "show":
 		opts, id, err := parseCorpusShowArgs(subArgs, ui)
@@ -96,7 +98,7 @@ func runCorpusCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return corpusShowCommand(repo, opts, id, ui)
+		return corpusShowCommand(ctx, repo, opts, id, ui)
 
 	case "ack":
 		opts, err := parseCorpusAckArgs(subArgs, ui)
@@ -107,7 +109,7 @@ func runCorpusCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return corpusAckCommand(repo, opts, ui)
+		return corpusAckCommand(ctx, repo, opts, ui)
 
 	case "rm":
 		opts, err := parseCorpusRmArgs(subArgs, ui)
@@ -118,7 +120,7 @@ func runCorpusCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return corpusRmCommand(repo, opts, ui)
+		return corpusRmCommand(ctx, repo, opts, ui)
 
 	case "publish":
 		opts, err := parseCorpusPublishArgs(subArgs, ui)
@@ -133,7 +135,15 @@ func runCorpusCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return corpusPublishCommand(corpusRepo, docRepo, opts, ui)
+		topicRepo, err := setup.NewLiveTopicRepository(opts.To)
+		if err != nil {
+			return err
+		}
+		indexRepo, err := setup.NewTopicIndexRepository(opts.To)
+		if err != nil {
+			return err
+		}
+		return corpusPublishCommand(ctx, corpusRepo, docRepo, topicRepo, indexRepo, opts, ui)
 
 	case "publish-label":
 		opts, err := parseCorpusPublishLabelArgs(subArgs, ui)
@@ -148,7 +158,7 @@ func runCorpusCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return corpusPublishLabelCommand(corpusRepo, docRepo, opts, ui)
+		return corpusPublishLabelCommand(ctx, corpusRepo, docRepo, opts, ui)
 
 	case "publish-topic":
 		opts, err := parseCorpusPublishTopicArgs(subArgs, ui)
@@ -163,7 +173,7 @@ func runCorpusCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return corpusPublishTopicCommand(corpusTopics, liveTopics, opts, ui)
+		return corpusPublishTopicCommand(ctx, corpusTopics, liveTopics, opts, ui)
 
 	case "backup":
 		opts, err := parseCorpusBackupArgs(subArgs, ui)
@@ -195,7 +205,7 @@ func runCorpusCommand(args []string, setup *Setup, ui UI) error {
 			return err
 		}
 
-		err = corpusBackupCommand(srcRepo, srcTopicsRepo, dstMgr, dstRepo, dstTopicsRepo, tempPath, opts, ui)
+		err = corpusBackupCommand(ctx, srcRepo, srcTopicsRepo, dstMgr, dstRepo, dstTopicsRepo, tempPath, opts, ui)
 		if err != nil {
 			return err
 		}
@@ -211,7 +221,7 @@ func runCorpusCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return corpusDumpTxtCommand(repo, opts, ui)
+		return corpusDumpTxtCommand(ctx, repo, opts, ui)
 
 	case "dump-nlp":
 		opts, err := parseCorpusDumpNlpArgs(subArgs, ui)
@@ -222,7 +232,7 @@ func runCorpusCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return corpusDumpNlpCommand(repo, opts, ui)
+		return corpusDumpNlpCommand(ctx, repo, opts, ui)
 
 	case "ingest-nlp":
 		opts, err := parseCorpusIngestNlpArgs(subArgs, ui)
@@ -233,7 +243,18 @@ func runCorpusCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return corpusIngestNlpCommand(repo, opts, ui)
+		return corpusIngestNlpCommand(ctx, repo, opts, ui)
+
+	case "ingest-conllu":
+		opts, err := parseCorpusIngestConlluArgs(subArgs, ui)
+		if err != nil {
+			return err
+		}
+		repo, err := setup.NewCorpusRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		return corpusIngestConlluCommand(ctx, repo, opts, ui)
 
 	case "ingest-meta":
 		opts, err := parseCorpusIngestMetaArgs(subArgs, ui)
@@ -244,7 +265,7 @@ func runCorpusCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return corpusIngestMetaCommand(repo, opts, ui)
+		return corpusIngestMetaCommand(ctx, repo, opts, ui)
 
 	case "push-txt":
 		opts, err := parseCorpusPushTxtArgs(subArgs, ui)
@@ -255,7 +276,7 @@ func runCorpusCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return corpusPushTxtCommand(repo, opts, ui)
+		return corpusPushTxtCommand(ctx, repo, opts, ui)
 
 	case "ls-label":
 		opts, err := parseCorpusLsLabelArgs(subArgs, ui)
@@ -266,7 +287,7 @@ func runCorpusCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return corpusLsLabelCommand(repo, opts, ui)
+		return corpusLsLabelCommand(ctx, repo, opts, ui)
 
 	case "set-label":
 		opts, err := parseCorpusSetLabelArgs(subArgs, ui)
@@ -277,7 +298,7 @@ func runCorpusCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return corpusSetLabelCommand(repo, opts, ui)
+		return corpusSetLabelCommand(ctx, repo, opts, ui)
 
 	case "ls-topic":
 		opts, err := parseCorpusLsTopicArgs(subArgs, ui)
@@ -288,7 +309,7 @@ func runCorpusCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return corpusLsTopicCommand(repo, opts, ui)
+		return corpusLsTopicCommand(ctx, repo, opts, ui)
 
 	case "show-topic":
 		opts, name, err := parseCorpusShowTopicArgs(subArgs, ui)
@@ -299,7 +320,7 @@ func runCorpusCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return corpusShowTopicCommand(repo, opts, name, ui)
+		return corpusShowTopicCommand(ctx, repo, opts, name, ui)
 
 	case "ingest-topic":
 		opts, err := parseCorpusIngestTopicArgs(subArgs, ui)
@@ -310,7 +331,7 @@ func runCorpusCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return corpusIngestTopicCommand(dst, opts, ui)
+		return corpusIngestTopicCommand(ctx, dst, opts, ui)
 
 	case "dump-topic":
 		opts, err := parseCorpusDumpTopicArgs(subArgs, ui)
@@ -321,7 +342,7 @@ func runCorpusCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return corpusDumpTopicCommand(src, opts, ui)
+		return corpusDumpTopicCommand(ctx, src, opts, ui)
 
 	case "edit":
 		opts, err := parseCorpusEditArgs(subArgs, ui)
@@ -332,7 +353,7 @@ func runCorpusCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return corpusEditCommand(repo, opts, ui)
+		return corpusEditCommand(ctx, repo, opts, ui)
 
 	default:
 		printCorpusUsage(ui.Err)
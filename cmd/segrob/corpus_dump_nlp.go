@@ -1,10 +1,14 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/revelaction/segrob/conllu"
+	sent "github.com/revelaction/segrob/sentence"
 	"github.com/revelaction/segrob/storage"
 )
 
@@ -17,12 +21,16 @@ type nlpPayload struct {
 	Sentences []nlpSentence `json:"sentences"`
 }
 
-func corpusDumpNlpCommand(repo storage.CorpusRepository, opts CorpusDumpNlpOptions, ui UI) error {
-	nlpData, err := repo.ReadNlp(opts.ID)
+func corpusDumpNlpCommand(ctx context.Context, repo storage.CorpusRepository, opts CorpusDumpNlpOptions, ui UI) error {
+	nlpData, err := repo.ReadNlp(ctx, opts.ID)
 	if err != nil {
 		return fmt.Errorf("failed to read nlp for %s: %w", opts.ID, err)
 	}
 
+	if opts.Format == "conllu" {
+		return dumpNlpConllu(nlpData, opts, ui)
+	}
+
 	if opts.NoLemmas {
 		var payload nlpPayload
 		if err := json.Unmarshal(nlpData, &payload); err != nil {
@@ -50,3 +58,37 @@ func corpusDumpNlpCommand(repo storage.CorpusRepository, opts CorpusDumpNlpOptio
 	_, err = ui.Out.Write([]byte("\n"))
 	return err
 }
+
+// dumpNlpConllu writes the sentences of the nlp payload as CoNLL-U.
+// Sentences whose heads do not make a tree are skipped and reported.
+func dumpNlpConllu(nlpData []byte, opts CorpusDumpNlpOptions, ui UI) (err error) {
+	var payload nlpResponse
+	if err := json.Unmarshal(nlpData, &payload); err != nil {
+		return fmt.Errorf("failed to parse nlp json: %w", err)
+	}
+
+	out := ui.Out
+	if opts.Output != "" {
+		f, cErr := os.Create(opts.Output)
+		if cErr != nil {
+			return fmt.Errorf("failed to create output file %s: %w", opts.Output, cErr)
+		}
+		defer func() {
+			err = errors.Join(err, f.Close())
+		}()
+		out = f
+	}
+
+	w := conllu.NewWriter(out)
+	for _, s := range payload.Sentences {
+		s.DocId = opts.ID
+		if err := w.Write(s); err != nil {
+			if !errors.Is(err, sent.ErrTree) {
+				return err
+			}
+			_, _ = fmt.Fprintf(ui.Err, "skipped %s\n", err)
+		}
+	}
+
+	return w.Flush()
+}
@@ -1,9 +1,10 @@
 package main
 
 import (
+	"context"
 	"github.com/revelaction/segrob/storage"
 )
 
-func corpusDumpTopicCommand(src storage.TopicReader, opts CorpusDumpTopicOptions, ui UI) error {
-	return dumpTopics(src, "", ui)
+func corpusDumpTopicCommand(ctx context.Context, src storage.TopicReader, opts CorpusDumpTopicOptions, ui UI) error {
+	return dumpTopics(ctx, src, "", ui)
 }
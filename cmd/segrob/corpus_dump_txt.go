@@ -1,15 +1,16 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"github.com/revelaction/segrob/storage"
 )
 
-func corpusDumpTxtCommand(repo storage.CorpusRepository, opts CorpusDumpTxtOptions, ui UI) error {
+func corpusDumpTxtCommand(ctx context.Context, repo storage.CorpusRepository, opts CorpusDumpTxtOptions, ui UI) error {
 
-	txt, err := repo.ReadTxt(opts.ID)
+	txt, err := repo.ReadTxt(ctx, opts.ID)
 	if err != nil {
 		return fmt.Errorf("failed to read txt for %s: %w", opts.ID, err)
 	}
@@ -1,15 +1,17 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"os"
 
 	"github.com/revelaction/segrob/edit"
+	"github.com/revelaction/segrob/history"
 	"github.com/revelaction/segrob/storage"
 	"golang.org/x/term"
 )
 
-func corpusEditCommand(tr storage.TopicRepository, opts CorpusEditOptions, ui UI) (err error) {
+func corpusEditCommand(ctx context.Context, tr storage.TopicRepository, opts CorpusEditOptions, ui UI) (err error) {
 	fd := int(os.Stdin.Fd())
 	state, gErr := term.GetState(fd)
 	if gErr == nil {
@@ -18,13 +20,18 @@ func corpusEditCommand(tr storage.TopicRepository, opts CorpusEditOptions, ui UI
 		}()
 	}
 
-	topicLib, rErr := tr.ReadAll("")
+	topicLib, rErr := tr.ReadAll(ctx, "")
 	if rErr != nil {
 		return rErr
 	}
 
 	hdl := edit.NewHandler(topicLib, tr, tr)
-	hdlErr := hdl.Run()
+	hdl.HistoryPath, err = history.Path("edit", "")
+	if err != nil {
+		return err
+	}
+
+	hdlErr := hdl.Run(ctx)
 	if hdlErr != nil {
 		return hdlErr
 	}
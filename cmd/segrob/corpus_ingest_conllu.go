@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/revelaction/segrob/conllu"
+	sent "github.com/revelaction/segrob/sentence"
+	"github.com/revelaction/segrob/storage"
+)
+
+func corpusIngestConlluCommand(ctx context.Context, corpusRepo storage.CorpusRepository, opts CorpusIngestConlluOptions, ui UI) error {
+	// Check TxtAck status unless forced
+	if !opts.Force {
+		meta, err := corpusRepo.ReadMeta(ctx, opts.ID)
+		if err != nil {
+			return fmt.Errorf("failed to read corpus meta: %w", err)
+		}
+		if !meta.TxtAck {
+			return fmt.Errorf("text not acknowledged for doc ID %s (use -f/--force to override)", opts.ID)
+		}
+	}
+
+	var r io.Reader = os.Stdin
+	if opts.File != "-" {
+		f, err := os.Open(opts.File)
+		if err != nil {
+			return fmt.Errorf("failed to open file %s: %w", opts.File, err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	sentences, err := conllu.Read(r)
+	if err != nil {
+		return fmt.Errorf("failed to parse CoNLL-U %s: %w", opts.File, err)
+	}
+	if len(sentences) == 0 {
+		return fmt.Errorf("no sentences in %s", opts.File)
+	}
+
+	// Build the payload of the NLP script, as publish expects it
+	var doc struct {
+		Sentences []storage.SentenceIngest `json:"sentences"`
+	}
+	for _, s := range sentences {
+		if err := checkIngestSentence(s); err != nil {
+			return fmt.Errorf("sentence %d: %w", s.SentenceId, err)
+		}
+
+		tokens, err := json.Marshal(s.Tokens)
+		if err != nil {
+			return fmt.Errorf("sentence %d: %w", s.SentenceId, err)
+		}
+
+		var lemmas []string
+		seen := make(map[string]bool)
+		for _, t := range s.Tokens {
+			if t.Lemma != "" && !seen[t.Lemma] {
+				seen[t.Lemma] = true
+				lemmas = append(lemmas, t.Lemma)
+			}
+		}
+
+		doc.Sentences = append(doc.Sentences, storage.SentenceIngest{ID: s.SentenceId, Lemmas: lemmas, Tokens: tokens})
+	}
+
+	nlp, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	if err := corpusRepo.WriteNlp(ctx, opts.ID, nlp); err != nil {
+		return fmt.Errorf("failed to write NLP data to corpus: %w", err)
+	}
+
+	_, err = fmt.Fprintf(ui.Err, "NLP data stored for doc ID %s (%d sentences, %d bytes)\n", opts.ID, len(doc.Sentences), len(nlp))
+	return err
+}
+
+// checkIngestSentence rejects the sentences that publish and the live
+// commands cannot use: empty, with a token without lemma, or whose heads do
+// not make a tree.
+func checkIngestSentence(s sent.Sentence) error {
+	if len(s.Tokens) == 0 {
+		return errors.New("no tokens")
+	}
+	for _, t := range s.Tokens {
+		if t.Lemma == "" {
+			return fmt.Errorf("token %d (%q) has no lemma", t.Index, t.Text)
+		}
+	}
+	return sent.CheckHeads(sent.Heads(s.Tokens))
+}
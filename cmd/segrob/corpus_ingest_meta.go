@@ -3,6 +3,7 @@ package main
 import (
 	"archive/zip"
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -64,7 +65,7 @@ func finalizeText(record *storage.CorpusRecord, rawTxt string) {
 	record.TxtHash = sha256Hex([]byte(record.Txt), 32)
 }
 
-func corpusIngestMetaCommand(repo storage.CorpusRepository, opts CorpusIngestMetaOptions, ui UI) error {
+func corpusIngestMetaCommand(ctx context.Context, repo storage.CorpusRepository, opts CorpusIngestMetaOptions, ui UI) error {
 	// Select processor once here. Nothing below this point knows about the flag.
 	process := processEpubGo
 	if opts.Pandoc {
@@ -86,8 +87,8 @@ func corpusIngestMetaCommand(repo storage.CorpusRepository, opts CorpusIngestMet
 	}
 
 	// Build iterator and write stream
-	seq := corpusIterator(repo, paths, process, ui)
-	if err := repo.WriteStream(seq); err != nil {
+	seq := corpusIterator(ctx, repo, paths, process, ui)
+	if err := repo.WriteStream(ctx, seq); err != nil {
 		return err
 	}
 
@@ -130,7 +131,7 @@ func processEpubPandoc(epubBytes []byte, name, id string) (storage.CorpusRecord,
 // each epub path. It checks existence in the store (idempotency) and
 // prints a summary line per processed epub. On error, it yields the error
 // and halts.
-func corpusIterator(repo storage.CorpusRepository, epubPaths []string, process func([]byte, string, string) (storage.CorpusRecord, error), ui UI) func(yield func(storage.CorpusRecord, error) bool) {
+func corpusIterator(ctx context.Context, repo storage.CorpusRepository, epubPaths []string, process func([]byte, string, string) (storage.CorpusRecord, error), ui UI) func(yield func(storage.CorpusRecord, error) bool) {
 	seen := make(map[string]bool)
 	return func(yield func(storage.CorpusRecord, error) bool) {
 		for _, epubPath := range epubPaths {
@@ -151,7 +152,7 @@ func corpusIterator(repo storage.CorpusRepository, epubPaths []string, process f
 				continue
 			}
 
-			exists, err := repo.Exists(id)
+			exists, err := repo.Exists(ctx, id)
 			if err != nil {
 				yield(storage.CorpusRecord{}, fmt.Errorf("failed to check existence for %s: %w", epubPath, err))
 				return
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os/exec"
@@ -10,10 +11,10 @@ import (
 	"github.com/revelaction/segrob/storage"
 )
 
-func corpusIngestNlpCommand(corpusRepo storage.CorpusRepository, opts CorpusIngestNlpOptions, ui UI) error {
+func corpusIngestNlpCommand(ctx context.Context, corpusRepo storage.CorpusRepository, opts CorpusIngestNlpOptions, ui UI) error {
 	// Check TxtAck status unless forced
 	if !opts.Force {
-		meta, err := corpusRepo.ReadMeta(opts.ID)
+		meta, err := corpusRepo.ReadMeta(ctx, opts.ID)
 		if err != nil {
 			return fmt.Errorf("failed to read corpus meta: %w", err)
 		}
@@ -23,7 +24,7 @@ func corpusIngestNlpCommand(corpusRepo storage.CorpusRepository, opts CorpusInge
 	}
 
 	// Read raw text
-	txtBytes, err := corpusRepo.ReadTxt(opts.ID)
+	txtBytes, err := corpusRepo.ReadTxt(ctx, opts.ID)
 	if err != nil {
 		return err
 	}
@@ -34,7 +35,7 @@ func corpusIngestNlpCommand(corpusRepo storage.CorpusRepository, opts CorpusInge
 		return fmt.Errorf("NLP script command is empty")
 	}
 	cmdArgs := append(parts[1:], "-")
-	cmd := exec.Command(parts[0], cmdArgs...)
+	cmd := exec.CommandContext(ctx, parts[0], cmdArgs...)
 	cmd.Stdin = bytes.NewReader(txtBytes)
 
 	var out bytes.Buffer
@@ -52,7 +53,7 @@ func corpusIngestNlpCommand(corpusRepo storage.CorpusRepository, opts CorpusInge
 	}
 
 	// Store raw JSON in corpus.nlp
-	if err := corpusRepo.WriteNlp(opts.ID, out.Bytes()); err != nil {
+	if err := corpusRepo.WriteNlp(ctx, opts.ID, out.Bytes()); err != nil {
 		return fmt.Errorf("failed to write NLP data to corpus: %w", err)
 	}
 
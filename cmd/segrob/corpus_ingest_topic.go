@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -9,7 +10,7 @@ import (
 	tpc "github.com/revelaction/segrob/topic"
 )
 
-func corpusIngestTopicCommand(dst storage.TopicWriter, opts CorpusIngestTopicOptions, ui UI) error {
+func corpusIngestTopicCommand(ctx context.Context, dst storage.TopicWriter, opts CorpusIngestTopicOptions, ui UI) error {
 	data, err := os.ReadFile(opts.File)
 	if err != nil {
 		return fmt.Errorf("failed to read topics file %s: %w", opts.File, err)
@@ -27,7 +28,7 @@ func corpusIngestTopicCommand(dst storage.TopicWriter, opts CorpusIngestTopicOpt
 
 	for _, tp := range topics {
 		tp.Exprs = tpc.Deduplicate(tp.Exprs)
-		_, err = dst.Upsert("", tp, nil)
+		_, err = dst.Upsert(ctx, "", tp, nil)
 		if err != nil {
 			return fmt.Errorf("failed to ingest topic %s: %w", tp.Name, err)
 		}
@@ -1,14 +1,15 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/revelaction/segrob/storage"
 )
 
-func corpusInitCommand(mgr storage.SchemaManager, opts CorpusInitOptions, ui UI) error {
+func corpusInitCommand(ctx context.Context, mgr storage.SchemaManager, opts CorpusInitOptions, ui UI) error {
 
-	if err := mgr.Create("corpus.sql"); err != nil {
+	if err := mgr.Create(ctx, "corpus.sql"); err != nil {
 		return err
 	}
 
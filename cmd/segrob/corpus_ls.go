@@ -1,14 +1,15 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/revelaction/segrob/storage"
 )
 
-func corpusLsCommand(repo storage.CorpusReader, opts CorpusLsOptions, ui UI) error {
-	metas, err := repo.List()
+func corpusLsCommand(ctx context.Context, repo storage.CorpusReader, opts CorpusLsOptions, ui UI) error {
+	metas, err := repo.List(ctx)
 	if err != nil {
 		return err
 	}
@@ -1,19 +1,20 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/revelaction/segrob/storage"
 )
 
-func corpusLsLabelCommand(repo storage.CorpusReader, opts CorpusLsLabelOptions, ui UI) error {
+func corpusLsLabelCommand(ctx context.Context, repo storage.CorpusReader, opts CorpusLsLabelOptions, ui UI) error {
 	var labels []string
 	var err error
 
 	if opts.ID != "" {
 		// List labels for a specific document
-		meta, err := repo.ReadMeta(opts.ID)
+		meta, err := repo.ReadMeta(ctx, opts.ID)
 		if err != nil {
 			return err
 		}
@@ -29,7 +30,7 @@ func corpusLsLabelCommand(repo storage.CorpusReader, opts CorpusLsLabelOptions,
 		}
 	} else {
 		// List all labels in the corpus
-		labels, err = repo.ListLabels(opts.Match)
+		labels, err = repo.ListLabels(ctx, opts.Match)
 		if err != nil {
 			return err
 		}
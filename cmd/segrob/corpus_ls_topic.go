@@ -1,12 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/revelaction/segrob/storage"
 )
 
-func corpusLsTopicCommand(tr storage.TopicRepository, opts CorpusLsTopicOptions, ui UI) error {
-	topicLib, err := tr.ReadAll("")
+func corpusLsTopicCommand(ctx context.Context, tr storage.TopicRepository, opts CorpusLsTopicOptions, ui UI) error {
+	topicLib, err := tr.ReadAll(ctx, "")
 	if err != nil {
 		return err
 	}
@@ -1,22 +1,25 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/revelaction/segrob/search"
+	sent "github.com/revelaction/segrob/sentence"
 	"github.com/revelaction/segrob/storage"
 )
 
 // corpusPublishCommand is the single entry point for both single-doc and all-doc modes.
-func corpusPublishCommand(corpusRepo storage.CorpusRepository, docRepo storage.DocRepository, opts CorpusPublishOptions, ui UI) error {
+func corpusPublishCommand(ctx context.Context, corpusRepo storage.CorpusRepository, docRepo storage.DocRepository, topicRepo storage.TopicReader, indexRepo storage.TopicIndexWriter, opts CorpusPublishOptions, ui UI) error {
 	if !opts.All {
-		return publishOne(corpusRepo, docRepo, opts.ID, opts.Move, opts.Force, ui)
+		return publishOne(ctx, corpusRepo, docRepo, topicRepo, indexRepo, opts.ID, opts.Move, opts.Force, ui)
 	}
 
-	metas, err := corpusRepo.List()
+	metas, err := corpusRepo.List(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to list corpus: %w", err)
 	}
@@ -44,7 +47,7 @@ func corpusPublishCommand(corpusRepo storage.CorpusRepository, docRepo storage.D
 			return err
 		}
 		// force is always false: the HasAck() filter above already guarantees ACK
-		if err := publishOne(corpusRepo, docRepo, m.ID, opts.Move, false, ui); err != nil {
+		if err := publishOne(ctx, corpusRepo, docRepo, topicRepo, indexRepo, m.ID, opts.Move, false, ui); err != nil {
 			return fmt.Errorf("failed to publish %s: %w\n\nFix the issue and re-run the command to continue", m.ID, err)
 		}
 		_, err = fmt.Fprintln(ui.Err)
@@ -57,10 +60,12 @@ func corpusPublishCommand(corpusRepo storage.CorpusRepository, docRepo storage.D
 	return err
 }
 
-// publishOne publishes a single document through the 4 idempotent transactional phases.
-func publishOne(corpusRepo storage.CorpusRepository, docRepo storage.DocRepository, id string, move bool, force bool, ui UI) error {
+// publishOne publishes a single document through 5 idempotent transactional
+// phases. Phase 5 is the live switch; it writes the lemma frequencies in the
+// same transaction.
+func publishOne(ctx context.Context, corpusRepo storage.CorpusRepository, docRepo storage.DocRepository, topicRepo storage.TopicReader, indexRepo storage.TopicIndexWriter, id string, move bool, force bool, ui UI) error {
 	// Read NLP data from corpus
-	nlpBytes, err := corpusRepo.ReadNlp(id)
+	nlpBytes, err := corpusRepo.ReadNlp(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to read NLP data for %s: %w", id, err)
 	}
@@ -77,7 +82,7 @@ func publishOne(corpusRepo storage.CorpusRepository, docRepo storage.DocReposito
 	}
 
 	// Read metadata from corpus for WriteMeta
-	meta, err := corpusRepo.ReadMeta(id)
+	meta, err := corpusRepo.ReadMeta(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to read corpus meta for %s: %w", id, err)
 	}
@@ -96,14 +101,14 @@ func publishOne(corpusRepo storage.CorpusRepository, docRepo storage.DocReposito
 	}
 
 	// Transaction 1: WriteMeta (idempotent — skip if doc already exists)
-	exists, err := docRepo.Exists(id)
+	exists, err := docRepo.Exists(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to check existence: %w", err)
 	}
 	if !exists {
 		start := time.Now()
 		// WriteMeta: upserts labels, INSERT docs with label_ids, returns IDs
-		labelIDs, err = docRepo.WriteMeta(meta.ID, meta.Epub, labels) // [3, 7, 12, 15]
+		labelIDs, err = docRepo.WriteMeta(ctx, meta.ID, meta.Epub, labels) // [3, 7, 12, 15]
 		if err != nil {
 			_, perr := fmt.Fprintf(ui.Err, "WriteMeta       ❌ %v\n", err)
 			return errors.Join(fmt.Errorf("WriteMeta failed: %w", err), perr)
@@ -120,13 +125,13 @@ func publishOne(corpusRepo storage.CorpusRepository, docRepo storage.DocReposito
 	}
 
 	// Transaction 2: WriteNlpData (idempotent — skip if sentences exist)
-	hasSentences, err := docRepo.HasSentences(id)
+	hasSentences, err := docRepo.HasSentences(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to check sentences: %w", err)
 	}
 	if !hasSentences {
 		start := time.Now()
-		if err := docRepo.WriteNlpData(id, doc.Sentences); err != nil {
+		if err := docRepo.WriteNlpData(ctx, id, doc.Sentences); err != nil {
 			_, perr := fmt.Fprintf(ui.Err, "WriteNlpData    ❌ %v\n", err)
 			return errors.Join(fmt.Errorf("WriteNlpData failed: %w", err), perr)
 		}
@@ -142,13 +147,13 @@ func publishOne(corpusRepo storage.CorpusRepository, docRepo storage.DocReposito
 	}
 
 	// Transaction 3: WriteLabelsOptimization (idempotent — skip if labels optimization exists)
-	hasLabels, err := docRepo.HasLabelsOptimization(id)
+	hasLabels, err := docRepo.HasLabelsOptimization(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to check labels optimization: %w", err)
 	}
 	if !hasLabels {
 		start := time.Now()
-		if err := docRepo.WriteLabelsOptimization(id, labelIDs); err != nil {
+		if err := docRepo.WriteLabelsOptimization(ctx, id, labelIDs); err != nil {
 			_, perr := fmt.Fprintf(ui.Err, "WriteLabelsOpt  ❌ %v\n", err)
 			return errors.Join(fmt.Errorf("WriteLabelsOptimization failed: %w", err), perr)
 		}
@@ -163,14 +168,32 @@ func publishOne(corpusRepo storage.CorpusRepository, docRepo storage.DocReposito
 		}
 	}
 
-	// Transaction 4: WriteLemmaOptimization — THE LIVE SWITCH (idempotent)
-	hasLemmas, err := docRepo.HasLemmaOptimization(id)
+	// Transaction 4: WriteDocTopicIndex — adds the document to the index of
+	// the already indexed topics before the live switch, so that a fresh
+	// topic never misses a live document (idempotent: rows are inserted once)
+	start := time.Now()
+	if err := writeDocTopicIndex(ctx, docRepo, topicRepo, indexRepo, id); err != nil {
+		_, perr := fmt.Fprintf(ui.Err, "WriteTopicIndex ❌ %v\n", err)
+		return errors.Join(fmt.Errorf("WriteDocTopicIndex failed: %w", err), perr)
+	}
+	_, err = fmt.Fprintf(ui.Err, "WriteTopicIndex ✅ %s\n", time.Since(start))
+	if err != nil {
+		return err
+	}
+
+	// Transaction 5: WriteLemmaOptimization — THE LIVE SWITCH, with the
+	// lemma frequencies (idempotent)
+	hasLemmas, err := docRepo.HasLemmaOptimization(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to check lemma optimization: %w", err)
 	}
 	if !hasLemmas {
 		start := time.Now()
-		if err := docRepo.WriteLemmaOptimization(id, doc.Sentences); err != nil {
+		freqs, err := ingestLemmaFreqs(doc.Sentences)
+		if err == nil {
+			err = docRepo.WriteLemmaOptimization(ctx, id, doc.Sentences, freqs)
+		}
+		if err != nil {
 			_, perr := fmt.Fprintf(ui.Err, "WriteLemmaOpt   ❌ %v\n", err)
 			return errors.Join(fmt.Errorf("WriteLemmaOptimization failed: %w", err), perr)
 		}
@@ -187,7 +210,7 @@ func publishOne(corpusRepo storage.CorpusRepository, docRepo storage.DocReposito
 
 	// Optional: delete nlp field from corpus
 	if move {
-		if err := corpusRepo.ClearNlp(id); err != nil {
+		if err := corpusRepo.ClearNlp(ctx, id); err != nil {
 			_, perr := fmt.Fprintf(ui.Err, "Warning: failed to clear NLP from corpus: %v\n", err)
 			if perr != nil {
 				return perr
@@ -197,3 +220,49 @@ func publishOne(corpusRepo storage.CorpusRepository, docRepo storage.DocReposito
 
 	return nil
 }
+
+// ingestLemmaFreqs decodes the tokens of the sentences and counts their
+// lemmas.
+func ingestLemmaFreqs(sentences []storage.SentenceIngest) ([]storage.LemmaFreq, error) {
+	decoded := make([]sent.Sentence, len(sentences))
+	for i, s := range sentences {
+		if err := json.Unmarshal(s.Tokens, &decoded[i].Tokens); err != nil {
+			return nil, fmt.Errorf("sentence %d: %w", s.ID, err)
+		}
+	}
+	return storage.CountLemmas(decoded), nil
+}
+
+// writeDocTopicIndex matches the default topics against the sentences of the
+// document and writes the matches to the sentence→topic index. The store
+// keeps only the topics that are already indexed; the others are indexed as
+// a whole by live reindex-topics.
+func writeDocTopicIndex(ctx context.Context, docRepo storage.DocReader, topicRepo storage.TopicReader, indexRepo storage.TopicIndexWriter, id string) error {
+	topics, err := topicRepo.ReadAll(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	if len(topics) == 0 {
+		return nil
+	}
+
+	sentences, err := docRepo.Nlp(ctx, id, 0, nil)
+	if err != nil {
+		return err
+	}
+
+	var matches []storage.TopicSentenceIDs
+	for _, tp := range topics {
+		m := storage.TopicSentenceIDs{Topic: tp}
+		tm := search.NewTopicMatcher(tp)
+		for _, s := range sentences {
+			if tm.Match(s) != nil {
+				m.SentenceIDs = append(m.SentenceIDs, s.SentenceId)
+			}
+		}
+		matches = append(matches, m)
+	}
+
+	return indexRepo.WriteDocTopicIndex(ctx, id, matches)
+}
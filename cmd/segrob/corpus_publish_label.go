@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -17,11 +18,11 @@ import (
 //  3. Rebuild the label index from the fresh label IDs.
 //
 // The command is idempotent: re-running produces the same final state.
-func corpusPublishLabelCommand(corpusRepo storage.CorpusRepository, docRepo storage.DocRepository, opts CorpusPublishLabelOptions, ui UI) error {
+func corpusPublishLabelCommand(ctx context.Context, corpusRepo storage.CorpusRepository, docRepo storage.DocRepository, opts CorpusPublishLabelOptions, ui UI) error {
 	id := opts.ID
 
 	// Verify the document exists in the live tables.
-	exists, err := docRepo.Exists(id)
+	exists, err := docRepo.Exists(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to check existence: %w", err)
 	}
@@ -31,7 +32,7 @@ func corpusPublishLabelCommand(corpusRepo storage.CorpusRepository, docRepo stor
 	}
 
 	// Read the current labels from the corpus.
-	meta, err := corpusRepo.ReadMeta(id)
+	meta, err := corpusRepo.ReadMeta(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to read corpus meta for %s: %w", id, err)
 	}
@@ -43,7 +44,7 @@ func corpusPublishLabelCommand(corpusRepo storage.CorpusRepository, docRepo stor
 
 	// Transaction 1 — cut the label index (live switch for label filtering).
 	start := time.Now()
-	if err := docRepo.DeleteLabelsOptimization(id); err != nil {
+	if err := docRepo.DeleteLabelsOptimization(ctx, id); err != nil {
 		_, perr := fmt.Fprintf(ui.Err, "DeleteLabelsOpt ❌ %v\n", err)
 		return errors.Join(fmt.Errorf("DeleteLabelsOptimization failed: %w", err), perr)
 	}
@@ -55,7 +56,7 @@ func corpusPublishLabelCommand(corpusRepo storage.CorpusRepository, docRepo stor
 	// Transaction 2 — upsert labels table and update docs row.
 	// Note: We do not clean up potentially orphaned labels in the labels table.
 	start = time.Now()
-	labelIDs, err := docRepo.UpdateLabels(id, labels)
+	labelIDs, err := docRepo.UpdateLabels(ctx, id, labels)
 	if err != nil {
 		_, perr := fmt.Fprintf(ui.Err, "UpdateLabels    ❌ %v\n", err)
 		return errors.Join(fmt.Errorf("UpdateLabels failed: %w", err), perr)
@@ -67,7 +68,7 @@ func corpusPublishLabelCommand(corpusRepo storage.CorpusRepository, docRepo stor
 
 	// Transaction 3 — rebuild the label index.
 	start = time.Now()
-	if err := docRepo.WriteLabelsOptimization(id, labelIDs); err != nil {
+	if err := docRepo.WriteLabelsOptimization(ctx, id, labelIDs); err != nil {
 		_, perr := fmt.Fprintf(ui.Err, "WriteLabelsOpt  ❌ %v\n", err)
 		return errors.Join(fmt.Errorf("WriteLabelsOptimization failed: %w", err), perr)
 	}
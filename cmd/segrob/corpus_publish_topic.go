@@ -1,20 +1,21 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/revelaction/segrob/storage"
 	tpc "github.com/revelaction/segrob/topic"
 )
 
-func corpusPublishTopicCommand(
+func corpusPublishTopicCommand(ctx context.Context,
 	corpusTopics storage.TopicReader,
 	liveTopics storage.TopicWriter,
 	opts CorpusPublishTopicOptions,
 	ui UI,
 ) error {
 	// 1. Read all topics from corpus
-	topics, readErr := corpusTopics.ReadAll("")
+	topics, readErr := corpusTopics.ReadAll(ctx, "")
 	if readErr != nil {
 		return fmt.Errorf("failed to read topics from corpus: %w", readErr)
 	}
@@ -33,7 +34,7 @@ func corpusPublishTopicCommand(
 	publishedCount := 0
 	for _, tp := range topics {
 		tp.Exprs = tpc.Deduplicate(tp.Exprs)
-		_, writeErr := liveTopics.Upsert("", tp, nil)
+		_, writeErr := liveTopics.Upsert(ctx, "", tp, nil)
 		if writeErr != nil {
 			return fmt.Errorf("failed to write topic %q to live: %w", tp.Name, writeErr)
 		}
@@ -1,13 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"github.com/revelaction/segrob/storage"
 )
 
-func corpusPushTxtCommand(repo storage.CorpusRepository, opts CorpusPushTxtOptions, ui UI) error {
+func corpusPushTxtCommand(ctx context.Context, repo storage.CorpusRepository, opts CorpusPushTxtOptions, ui UI) error {
 	txt, err := os.ReadFile(opts.File)
 	if err != nil {
 		return fmt.Errorf("failed to read file %s: %w", opts.File, err)
@@ -16,7 +17,7 @@ func corpusPushTxtCommand(repo storage.CorpusRepository, opts CorpusPushTxtOptio
 	// Use existing sha256Hex from corpus_ingest_meta.go (same package main)
 	txtHash := sha256Hex(txt, 32)
 
-	err = repo.UpdateTxt(opts.ID, txt, txtHash, opts.By, opts.Note)
+	err = repo.UpdateTxt(ctx, opts.ID, txt, txtHash, opts.By, opts.Note)
 	if err != nil {
 		return fmt.Errorf("failed to update corpus txt for %s: %w", opts.ID, err)
 	}
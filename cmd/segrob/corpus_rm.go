@@ -1,12 +1,13 @@
 package main
 
 import (
+	"context"
 	"github.com/revelaction/segrob/storage"
 )
 
-func corpusRmCommand(repo storage.CorpusRepository, opts CorpusRmOptions, ui UI) error {
+func corpusRmCommand(ctx context.Context, repo storage.CorpusRepository, opts CorpusRmOptions, ui UI) error {
 	// 1. Remove the document
-	if err := repo.Delete(opts.ID); err != nil {
+	if err := repo.Delete(ctx, opts.ID); err != nil {
 		return err
 	}
 
@@ -1,21 +1,22 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/revelaction/segrob/storage"
 )
 
-func corpusSetLabelCommand(repo storage.CorpusRepository, opts CorpusSetLabelOptions, ui UI) error {
+func corpusSetLabelCommand(ctx context.Context, repo storage.CorpusRepository, opts CorpusSetLabelOptions, ui UI) error {
 
 	if opts.Delete {
-		if err := repo.DeleteLabel(opts.DocID, opts.Labels...); err != nil {
+		if err := repo.DeleteLabel(ctx, opts.DocID, opts.Labels...); err != nil {
 			return fmt.Errorf("failed to delete labels: %w", err)
 		}
 		return nil
 	}
 
-	if err := repo.AddLabel(opts.DocID, opts.Labels...); err != nil {
+	if err := repo.AddLabel(ctx, opts.DocID, opts.Labels...); err != nil {
 		return fmt.Errorf("failed to add labels: %w", err)
 	}
 
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -12,8 +13,8 @@ type nlpResponse struct {
 	Sentences []sent.Sentence `json:"sentences"`
 }
 
-func corpusShowCommand(repo storage.CorpusRepository, opts ShowOptions, id string, ui UI) error {
-	nlpData, err := repo.ReadNlp(id)
+func corpusShowCommand(ctx context.Context, repo storage.CorpusRepository, opts ShowOptions, id string, ui UI) error {
+	nlpData, err := repo.ReadNlp(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to read nlp for %s: %w", id, err)
 	}
@@ -1,12 +1,13 @@
 package main
 
 import (
+	"context"
 	"github.com/revelaction/segrob/render"
 	"github.com/revelaction/segrob/storage"
 )
 
-func corpusShowTopicCommand(tr storage.TopicRepository, opts CorpusShowTopicOptions, name string, ui UI) error {
-	tp, err := tr.Read("", name)
+func corpusShowTopicCommand(ctx context.Context, tr storage.TopicRepository, opts CorpusShowTopicOptions, name string, ui UI) error {
+	tp, err := tr.Read(ctx, "", name)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"sort"
 
@@ -36,8 +37,8 @@ import (
 //	    ]
 //	  }
 //	]
-func dumpTopics(src storage.TopicReader, userID string, ui UI) error {
-	topics, err := src.ReadAll(userID)
+func dumpTopics(ctx context.Context, src storage.TopicReader, userID string, ui UI) error {
+	topics, err := src.ReadAll(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("failed to read topics: %w", err)
 	}
@@ -1,88 +1,81 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"strings"
 
+	"github.com/revelaction/segrob/cql"
 	"github.com/revelaction/segrob/match"
 	"github.com/revelaction/segrob/render"
-	sent "github.com/revelaction/segrob/sentence"
+	"github.com/revelaction/segrob/search"
 	"github.com/revelaction/segrob/storage"
 	"github.com/revelaction/segrob/topic"
 )
 
-func liveFindCommand(dr storage.DocRepository, opts LiveFindOptions, args []string, ui UI) error {
+func liveFindCommand(ctx context.Context, dr storage.DocRepository, opts LiveFindOptions, args []string, ui UI) error {
 
-	// args is guaranteed to have at least 1 element by parseLiveFindArgs
-	// Flatten arguments to support quoted expressions containing spaces,
-	// matching the behavior of the query REPL.
-	//
-	// Verification use cases:
-	// - Unquoted: segrob expr a 1 el      -> args:["a", "1", "el"] -> flatArgs:["a", "1", "el"]
-	// - Quoted:   segrob expr "a 1 el"    -> args:["a 1 el"]       -> flatArgs:["a", "1", "el"]
-	// - Mixed:    segrob expr "a 1" el    -> args:["a 1", "el"]    -> flatArgs:["a", "1", "el"]
-	var flatArgs []string
-	for _, arg := range args {
-		flatArgs = append(flatArgs, strings.Fields(arg)...)
-	}
-
-	// parse the expr expression
-	expr, parseErr := topic.Parse(flatArgs)
-	if parseErr != nil {
-		return parseErr
-	}
-
-	// Resolve labels to IDs
-	var labelIDs []int
-	if len(opts.Labels) > 0 {
-		allLabels, err := dr.ListLabels("")
+	var sopts search.Options
+	var title string
+	if opts.CQL != "" {
+		q, err := cql.Parse(opts.CQL)
 		if err != nil {
 			return err
 		}
+		sopts.Pattern = q
+		title = q.String()
+	} else {
+		// args is guaranteed to have at least 1 element by parseLiveFindArgs
+		// Flatten arguments to support quoted expressions containing spaces,
+		// matching the behavior of the query REPL.
+		//
+		// Verification use cases:
+		// - Unquoted: segrob expr a 1 el      -> args:["a", "1", "el"] -> flatArgs:["a", "1", "el"]
+		// - Quoted:   segrob expr "a 1 el"    -> args:["a 1 el"]       -> flatArgs:["a", "1", "el"]
+		// - Mixed:    segrob expr "a 1" el    -> args:["a 1", "el"]    -> flatArgs:["a", "1", "el"]
+		var flatArgs []string
+		for _, arg := range args {
+			flatArgs = append(flatArgs, strings.Fields(arg)...)
+		}
 
-		for _, name := range opts.Labels {
-			if id, ok := allLabels[name]; ok {
-				labelIDs = append(labelIDs, id)
-			}
+		// parse the expr expression
+		expr, parseErr := topic.Parse(flatArgs)
+		if parseErr != nil {
+			return parseErr
 		}
+		sopts.Expr = expr
+		title = expr.String()
+	}
+
+	// Resolve labels to IDs
+	labelIDs, err := resolveLabelIDs(ctx, dr, opts.Labels)
+	if err != nil {
+		return err
 	}
+	sopts.LabelIDs = labelIDs
 
-	// Prepare Matcher
-	matcher := match.NewMatcher(expr)
+	sopts.Budget = opts.Budget
 
-	// Prepare results accumulator
 	var results []*match.SentenceMatch
-	limitReached := false
-	onMatch := func(m *match.SentenceMatch) error {
-		results = append(results, m)
-		if opts.Limit > 0 && len(results) >= opts.Limit {
-			limitReached = true
-		}
-		return nil
+	var s *search.Search
+	if hasComplexityOptions(opts) {
+		s = search.New(dr, sopts)
+		results, err = collectComplexity(ctx, s, opts)
+	} else {
+		sopts.Limit = opts.Limit
+		s = search.New(dr, sopts)
+		results, err = s.Collect(ctx)
+	}
+	if err != nil {
+		return err
 	}
 
-	// Execute search with pagination
-	cursor := storage.Cursor(0)
-	limit := 1000
-	lemmas := expr.Lemmas()
-
-	for {
-		newCursor, err := dr.FindCandidates(lemmas, labelIDs, cursor, limit, func(s sent.Sentence) error {
-			if m := matcher.MatchSentence(s); m != nil {
-				return onMatch(m)
-			}
-			return nil
-		})
+	if opts.Budget > 0 && s.Fetched() >= opts.Budget && !s.Exhausted() {
+		_, _ = fmt.Fprintf(ui.Err, "Warning: stopped after %d candidate sentences (--budget)\n", opts.Budget)
+	}
 
-		if err != nil {
-			return err
-		}
-		if cursor == newCursor {
-			break
-		}
-		if limitReached {
-			break
-		}
-		cursor = newCursor
+	if opts.HTML != "" {
+		return writeHTMLReport(ctx, dr, "segrob find: "+title, opts.HTML, results, ui)
 	}
 
 	// Render results
@@ -93,7 +86,7 @@ func liveFindCommand(dr storage.DocRepository, opts LiveFindOptions, args []stri
 	r.Format = opts.Format
 
 	// Populate DocNames for indexed search
-	list, err := dr.List()
+	list, err := dr.List(ctx)
 	if err != nil {
 		return err
 	}
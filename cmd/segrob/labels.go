@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	sent "github.com/revelaction/segrob/sentence"
+	"github.com/revelaction/segrob/storage"
+)
+
+// resolveLabelIDs maps label names to their live IDs. Names that do not
+// exist in the live labels table are skipped.
+func resolveLabelIDs(ctx context.Context, dr storage.DocReader, names []string) ([]int, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	allLabels, err := dr.ListLabels(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var labelIDs []int
+	for _, name := range names {
+		if id, ok := allLabels[name]; ok {
+			labelIDs = append(labelIDs, id)
+		}
+	}
+
+	return labelIDs, nil
+}
+
+// docLabels returns the label names of each given document, keyed by doc ID.
+func docLabels(ctx context.Context, dr storage.DocReader, docs []sent.Meta) (map[string][]string, error) {
+	allLabels, err := dr.ListLabels(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Reverse the Name->ID map to an ID->Name map for lookups
+	labelMap := allLabels.Reverse()
+
+	res := make(map[string][]string, len(docs))
+	for _, d := range docs {
+		var names []string
+		for _, id := range d.LabelIDs {
+			if name, ok := labelMap[id]; ok {
+				names = append(names, name)
+			}
+		}
+		res[d.Id] = names
+	}
+
+	return res, nil
+}
+
+// scanBatchSize is the number of sentences read per LabelSentences call.
+const scanBatchSize = 500
+
+// resolveLabelIDsStrict is resolveLabelIDs failing on unknown label names,
+// for commands where skipping a label would widen the subcorpus.
+func resolveLabelIDsStrict(ctx context.Context, dr storage.DocReader, names []string) ([]int, error) {
+	allLabels, err := dr.ListLabels(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var labelIDs []int
+	for _, name := range names {
+		id, ok := allLabels[name]
+		if !ok {
+			return nil, fmt.Errorf("label %q not found", name)
+		}
+		labelIDs = append(labelIDs, id)
+	}
+
+	return labelIDs, nil
+}
+
+// scanLabelSentences passes every sentence having all labelIDs (all the
+// sentences if empty) to fn, in rowid order.
+func scanLabelSentences(ctx context.Context, dr storage.DocReader, labelIDs []int, fn func(sent.Sentence) error) error {
+	var after storage.Cursor
+	for {
+		cursor, err := dr.LabelSentences(ctx, labelIDs, after, scanBatchSize, fn)
+		if err != nil {
+			return err
+		}
+		if cursor == after {
+			return nil
+		}
+		after = cursor
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/revelaction/segrob/search"
+	"github.com/revelaction/segrob/stat"
+	"github.com/revelaction/segrob/storage"
+	"github.com/revelaction/segrob/topic"
+)
+
+const liveCollocatesFmt = "%-20s %8s %8s %8s %8s %10s %8s\n"
+
+// collocate is a lemma co-occurring with the node and its association.
+type collocate struct {
+	Lemma string
+	stat.Contingency
+}
+
+// liveCollocatesCommand ranks the lemmas found within a window of a node
+// lemma. Only the sentences containing the node are read; the frequencies of
+// the collocates come from the lemma index.
+func liveCollocatesCommand(ctx context.Context, dr storage.DocReader, opts LiveCollocatesOptions, node string, ui UI) error {
+	labelIDs, err := resolveLabelIDs(ctx, dr, opts.Labels)
+	if err != nil {
+		return err
+	}
+
+	// Co-occurrences are counted once per sentence, like the frequencies
+	nodeSentences := 0
+	cooc := make(map[string]int)
+	s := search.New(dr, search.Options{
+		Expr:     topic.TopicExpr{Items: []topic.TopicExprItem{{Lemma: node}}},
+		LabelIDs: labelIDs,
+	})
+	for sm, err := range s.All(ctx) {
+		if err != nil {
+			return err
+		}
+		nodeSentences++
+
+		var nodes []int
+		for _, t := range sm.Sentence.Tokens {
+			if t.Lemma == node {
+				nodes = append(nodes, t.Index)
+			}
+		}
+
+		seen := make(map[string]bool)
+		for _, t := range sm.Sentence.Tokens {
+			if t.Lemma == node || t.Pos == "PUNCT" || t.Pos == "SPACE" || seen[t.Lemma] {
+				continue
+			}
+			if len(opts.Pos) > 0 && !slices.Contains(opts.Pos, t.Pos) {
+				continue
+			}
+			for _, n := range nodes {
+				if t.Index >= n-opts.Window && t.Index <= n+opts.Window {
+					seen[t.Lemma] = true
+					break
+				}
+			}
+		}
+
+		for lemma := range seen {
+			cooc[lemma]++
+		}
+	}
+
+	if nodeSentences == 0 {
+		return fmt.Errorf("lemma %q not found", node)
+	}
+
+	var lemmas []string
+	for lemma, n := range cooc {
+		if n >= opts.Min {
+			lemmas = append(lemmas, lemma)
+		}
+	}
+
+	// the frequencies of the whole corpus come from the lemma frequencies
+	if len(labelIDs) == 0 {
+		if err := warnMissingLemmaFreq(ctx, dr, ui); err != nil {
+			return err
+		}
+	}
+
+	total, err := dr.CountSentences(ctx, labelIDs)
+	if err != nil {
+		return err
+	}
+
+	freqs, err := dr.LemmaSentenceCounts(ctx, lemmas, labelIDs)
+	if err != nil {
+		return err
+	}
+
+	colls := make([]collocate, 0, len(lemmas))
+	for _, lemma := range lemmas {
+		colls = append(colls, collocate{
+			Lemma: lemma,
+			Contingency: stat.Contingency{
+				O11: float64(cooc[lemma]),
+				R1:  float64(nodeSentences),
+				C1:  float64(freqs[lemma]),
+				N:   float64(total),
+			},
+		})
+	}
+
+	score := func(c collocate) float64 {
+		switch opts.Sort {
+		case "pmi":
+			return c.PMI()
+		case "t":
+			return c.TScore()
+		default:
+			// G² is two-sided: rank repelled lemmas last
+			if c.O11 < c.E11() {
+				return -c.LogLikelihood()
+			}
+			return c.LogLikelihood()
+		}
+	}
+	sort.SliceStable(colls, func(i, j int) bool {
+		si, sj := score(colls[i]), score(colls[j])
+		if si != sj {
+			return si > sj
+		}
+		return colls[i].Lemma < colls[j].Lemma
+	})
+	if len(colls) > opts.Top {
+		colls = colls[:opts.Top]
+	}
+
+	_, _ = fmt.Fprintf(ui.Err, "%s: %d of %d sentences, %d collocates with at least %d co-occurrences\n", node, nodeSentences, total, len(lemmas), opts.Min)
+
+	if opts.Format == "csv" {
+		return writeCollocatesCSV(ui.Out, colls)
+	}
+
+	_, _ = fmt.Fprintf(ui.Out, liveCollocatesFmt, "COLLOCATE", "COOC", "FREQ", "EXPECTED", "PMI", "LL", "T")
+	for _, c := range colls {
+		_, err := fmt.Fprintf(ui.Out, liveCollocatesFmt,
+			truncate(c.Lemma, 20),
+			strconv.Itoa(int(c.O11)),
+			strconv.Itoa(int(c.C1)),
+			fmt.Sprintf("%.2f", c.E11()),
+			fmt.Sprintf("%.2f", c.PMI()),
+			fmt.Sprintf("%.2f", c.LogLikelihood()),
+			fmt.Sprintf("%.2f", c.TScore()),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeCollocatesCSV(w io.Writer, colls []collocate) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"collocate", "cooc", "freq", "expected", "pmi", "ll", "t"}); err != nil {
+		return err
+	}
+
+	for _, c := range colls {
+		line := []string{
+			c.Lemma,
+			strconv.Itoa(int(c.O11)),
+			strconv.Itoa(int(c.C1)),
+			strconv.FormatFloat(c.E11(), 'f', 4, 64),
+			strconv.FormatFloat(c.PMI(), 'f', 4, 64),
+			strconv.FormatFloat(c.LogLikelihood(), 'f', 4, 64),
+			strconv.FormatFloat(c.TScore(), 'f', 4, 64),
+		}
+		if err := cw.Write(line); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -18,18 +19,32 @@ func printLiveUsage(w io.Writer) {
 	_, _ = fmt.Fprintf(w, helpCmdFmt, "find", "Find sentences matching a topic expression.")
 	_, _ = fmt.Fprintf(w, helpCmdFmt, "find-topics", "Show topics for a specific sentence.")
 	_, _ = fmt.Fprintf(w, helpCmdFmt, "query", "Enter interactive query mode.")
+	_, _ = fmt.Fprintf(w, helpCmdFmt, "report", "Write an HTML report of the matches of a topic.")
+	_, _ = fmt.Fprintf(w, helpCmdFmt, "export-cloze", "Export cloze flashcards (Anki TSV or CSV).")
+	_, _ = fmt.Fprintf(w, helpCmdFmt, "drill", "Enter interactive grammar drill mode.")
+	_, _ = fmt.Fprintf(w, helpCmdFmt, "dispersion", "Show where in a document a topic occurs.")
+	_, _ = fmt.Fprintf(w, helpCmdFmt, "collocates", "Rank the collocates of a lemma by association.")
+	_, _ = fmt.Fprintf(w, helpCmdFmt, "keyness", "Compare the keywords of two label subcorpora.")
+	_, _ = fmt.Fprintf(w, helpCmdFmt, "lemmas", "List the lemmas of the corpus by frequency.")
+	_, _ = fmt.Fprintf(w, helpCmdFmt, "export", "Export documents as CoNLL-U or CWB vertical.")
+	_, _ = fmt.Fprintf(w, helpCmdFmt, "graded", "Find sentences with known vocabulary for graded reading.")
+	_, _ = fmt.Fprintf(w, helpCmdFmt, "paradigm", "Show the forms of a lemma by morphological features.")
+	_, _ = fmt.Fprintf(w, helpCmdFmt, "stats", "Show the statistics of the corpus or a label subcorpus.")
 
 	_, _ = fmt.Fprintf(w, "\nSubcommands: Topics\n")
 	_, _ = fmt.Fprintf(w, helpCmdFmt, "ls-topic", "List all unique topics in the repository.")
 	_, _ = fmt.Fprintf(w, helpCmdFmt, "show-topic", "Show expressions for a specific topic.")
 	_, _ = fmt.Fprintf(w, helpCmdFmt, "unpublish-topic", "Remove a topic from the live topics repository.")
 	_, _ = fmt.Fprintf(w, helpCmdFmt, "dump-topic", "Output all live topics of a user as a single JSON file.")
+	_, _ = fmt.Fprintf(w, helpCmdFmt, "reindex-topics", "Compute the sentence→topic index of stale topics.")
+	_, _ = fmt.Fprintf(w, helpCmdFmt, "topic-stats", "Count topic matches per document or label value.")
+	_, _ = fmt.Fprintf(w, helpCmdFmt, "timeline", "Chart the frequency of a topic by publication date.")
 
 	_, _ = fmt.Fprintf(w, "\nSubcommands: Other\n")
 	_, _ = fmt.Fprintf(w, helpCmdFmt, "init", "Initialize a new SQLite database with the required schema.")
 }
 
-func runLiveCommand(args []string, setup *Setup, ui UI) error {
+func runLiveCommand(ctx context.Context, args []string, setup *Setup, ui UI) error {
 	if len(args) < 1 {
 		printLiveUsage(ui.Err)
 		return fmt.Errorf("live requires a subcommand")
@@ -53,7 +68,7 @@ func runLiveCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return liveLsCommand(repo, opts, ui)
+		return liveLsCommand(ctx, repo, opts, ui)
 
 	case "ls-topic":
 		opts, err := parseLiveLsTopicArgs(subArgs, ui)
@@ -64,7 +79,7 @@ func runLiveCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return liveLsTopicCommand(repo, opts, ui)
+		return liveLsTopicCommand(ctx, repo, opts, ui)
 
 	case "show-topic":
 		opts, name, err := parseLiveShowTopicArgs(subArgs, ui)
@@ -75,7 +90,7 @@ func runLiveCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return liveShowTopicCommand(repo, opts, name, ui)
+		return liveShowTopicCommand(ctx, repo, opts, name, ui)
 
 	case "find":
 		opts, cmdArgs, _, err := parseLiveFindArgs(subArgs, ui)
@@ -86,7 +101,164 @@ func runLiveCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return liveFindCommand(dr, opts, cmdArgs, ui)
+		return liveFindCommand(ctx, dr, opts, cmdArgs, ui)
+
+	case "report":
+		opts, name, err := parseLiveReportArgs(subArgs, ui)
+		if err != nil {
+			return err
+		}
+		dr, err := setup.NewDocRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		tr, err := setup.NewLiveTopicRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		return liveReportCommand(ctx, dr, tr, opts, name, ui)
+
+	case "export-cloze":
+		opts, cmdArgs, err := parseLiveExportClozeArgs(subArgs, ui)
+		if err != nil {
+			return err
+		}
+		dr, err := setup.NewDocRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		tr, err := setup.NewLiveTopicRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		indexRepo, err := setup.NewTopicIndexRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		return liveExportClozeCommand(ctx, dr, tr, indexRepo, opts, cmdArgs, ui)
+
+	case "drill":
+		opts, cmdArgs, err := parseLiveDrillArgs(subArgs, ui)
+		if err != nil {
+			return err
+		}
+		dr, err := setup.NewDocRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		tr, err := setup.NewLiveTopicRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		indexRepo, err := setup.NewTopicIndexRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		drr, err := setup.NewDrillRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		return liveDrillCommand(ctx, dr, tr, indexRepo, drr, opts, cmdArgs, ui)
+
+	case "dispersion":
+		opts, docId, cmdArgs, err := parseLiveDispersionArgs(subArgs, ui)
+		if err != nil {
+			return err
+		}
+		dr, err := setup.NewDocRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		tr, err := setup.NewLiveTopicRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		return liveDispersionCommand(ctx, dr, tr, opts, docId, cmdArgs, ui)
+
+	case "collocates":
+		opts, lemma, err := parseLiveCollocatesArgs(subArgs, ui)
+		if err != nil {
+			return err
+		}
+		dr, err := setup.NewDocRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		return liveCollocatesCommand(ctx, dr, opts, lemma, ui)
+
+	case "keyness":
+		opts, err := parseLiveKeynessArgs(subArgs, ui)
+		if err != nil {
+			return err
+		}
+		dr, err := setup.NewDocRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		return liveKeynessCommand(ctx, dr, opts, ui)
+
+	case "lemmas":
+		opts, prefix, err := parseLiveLemmasArgs(subArgs, ui)
+		if err != nil {
+			return err
+		}
+		dr, err := setup.NewDocRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		return liveLemmasCommand(ctx, dr, opts, prefix, ui)
+
+	case "export":
+		opts, ids, err := parseLiveExportArgs(subArgs, ui)
+		if err != nil {
+			return err
+		}
+		dr, err := setup.NewDocRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		return liveExportCommand(ctx, dr, opts, ids, ui)
+
+	case "graded":
+		opts, cmdArgs, err := parseLiveGradedArgs(subArgs, ui)
+		if err != nil {
+			return err
+		}
+		dr, err := setup.NewDocRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		tr, err := setup.NewLiveTopicRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		indexRepo, err := setup.NewTopicIndexRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		return liveGradedCommand(ctx, dr, tr, indexRepo, opts, cmdArgs, ui)
+
+	case "paradigm":
+		opts, lemma, err := parseLiveParadigmArgs(subArgs, ui)
+		if err != nil {
+			return err
+		}
+		dr, err := setup.NewDocRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		return liveParadigmCommand(ctx, dr, opts, lemma, ui)
+
+	case "stats":
+		opts, err := parseLiveStatsArgs(subArgs, ui)
+		if err != nil {
+			return err
+		}
+		dr, err := setup.NewDocRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		return liveStatsCommand(ctx, dr, opts, ui)
 
 	case "find-topics":
 		opts, docId, sentId, err := parseLiveFindTopicsArgs(subArgs, ui)
@@ -101,7 +273,11 @@ func runLiveCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return liveFindTopicsCommand(dr, tr, opts, docId, sentId, ui)
+		indexRepo, err := setup.NewTopicIndexRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		return liveFindTopicsCommand(ctx, dr, tr, indexRepo, opts, docId, sentId, ui)
 
 	case "init":
 		opts, err := parseLiveInitArgs(subArgs, ui)
@@ -112,7 +288,7 @@ func runLiveCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return liveInitCommand(mgr, opts, ui)
+		return liveInitCommand(ctx, mgr, opts, ui)
 
 	case "show":
 		opts, id, err := parseLiveShowArgs(subArgs, ui)
@@ -123,7 +299,7 @@ func runLiveCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return liveShowCommand(repo, opts, id, ui)
+		return liveShowCommand(ctx, repo, opts, id, ui)
 
 	case "show-sent":
 		opts, docId, sentId, err := parseLiveShowSentArgs(subArgs, ui)
@@ -134,7 +310,7 @@ func runLiveCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return liveShowSentCommand(repo, opts, docId, sentId, ui)
+		return liveShowSentCommand(ctx, repo, opts, docId, sentId, ui)
 
 	case "unpublish":
 		opts, err := parseLiveUnpublishArgs(subArgs, ui)
@@ -145,7 +321,68 @@ func runLiveCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return liveUnpublishCommand(repo, opts, ui)
+		indexRepo, err := setup.NewTopicIndexRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		return liveUnpublishCommand(ctx, repo, indexRepo, opts, ui)
+
+	case "topic-stats":
+		opts, names, err := parseLiveTopicStatsArgs(subArgs, ui)
+		if err != nil {
+			return err
+		}
+		dr, err := setup.NewDocRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		tr, err := setup.NewLiveTopicRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		indexRepo, err := setup.NewTopicIndexRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		return liveTopicStatsCommand(ctx, dr, tr, indexRepo, opts, names, ui)
+
+	case "timeline":
+		opts, cmdArgs, err := parseLiveTimelineArgs(subArgs, ui)
+		if err != nil {
+			return err
+		}
+		dr, err := setup.NewDocRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		tr, err := setup.NewLiveTopicRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		indexRepo, err := setup.NewTopicIndexRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		return liveTimelineCommand(ctx, dr, tr, indexRepo, opts, cmdArgs, ui)
+
+	case "reindex-topics":
+		opts, names, err := parseLiveReindexTopicsArgs(subArgs, ui)
+		if err != nil {
+			return err
+		}
+		dr, err := setup.NewDocRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		tr, err := setup.NewLiveTopicRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		indexRepo, err := setup.NewTopicIndexRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		return liveReindexTopicsCommand(ctx, dr, tr, indexRepo, opts, names, ui)
 
 	case "unpublish-topic":
 		opts, name, err := parseLiveUnpublishTopicArgs(subArgs, ui)
@@ -156,7 +393,7 @@ func runLiveCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return liveUnpublishTopicCommand(repo, opts, name, ui)
+		return liveUnpublishTopicCommand(ctx, repo, opts, name, ui)
 
 	case "query":
 		opts, err := parseLiveQueryArgs(subArgs, ui)
@@ -171,7 +408,7 @@ func runLiveCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return liveQueryCommand(dr, tr, opts, ui)
+		return liveQueryCommand(ctx, dr, tr, opts, ui)
 
 	case "dump-topic":
 		opts, err := parseLiveDumpTopicArgs(subArgs, ui)
@@ -182,7 +419,7 @@ func runLiveCommand(args []string, setup *Setup, ui UI) error {
 		if err != nil {
 			return err
 		}
-		return liveDumpTopicCommand(src, opts, ui)
+		return liveDumpTopicCommand(ctx, src, opts, ui)
 
 	default:
 		printLiveUsage(ui.Err)
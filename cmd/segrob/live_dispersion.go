@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/revelaction/segrob/search"
+	"github.com/revelaction/segrob/stat"
+	"github.com/revelaction/segrob/storage"
+	"github.com/revelaction/segrob/topic"
+)
+
+const liveDispersionFmt = "%-24s %s %6s %6s %6s\n"
+
+// liveDispersionCommand prints a strip plot of the sentences of a document
+// matching each expression of a topic, with their dispersion statistics.
+func liveDispersionCommand(ctx context.Context, dr storage.DocReader, tr storage.TopicReader, opts LiveDispersionOptions, docId string, args []string, ui UI) error {
+	tp, err := resolveTopicArgs(ctx, tr, "", args)
+	if err != nil {
+		return err
+	}
+
+	exists, err := dr.Exists(ctx, docId)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("document %s not found", docId)
+	}
+
+	sentences, err := dr.Nlp(ctx, docId, 0, nil)
+	if err != nil {
+		return err
+	}
+
+	size := len(sentences)
+	if size == 0 {
+		return fmt.Errorf("document %s has no sentences", docId)
+	}
+
+	// A row per expression, and the topic as a whole if it has several
+	positions := make([][]int, len(tp.Exprs))
+	var all []int
+	for _, s := range sentences {
+		matched := false
+		for i, expr := range tp.Exprs {
+			if search.MatchTopic(topic.Topic{Exprs: []topic.TopicExpr{expr}}, s) == nil {
+				continue
+			}
+			positions[i] = append(positions[i], s.SentenceId)
+			matched = true
+		}
+		if matched {
+			all = append(all, s.SentenceId)
+		}
+	}
+
+	width := min(opts.Width, size)
+	_, _ = fmt.Fprintf(ui.Out, "%s: %d sentences, %d parts, %d sentences per column\n\n", docId, size, opts.Parts, (size+width-1)/width)
+	_, _ = fmt.Fprintf(ui.Out, liveDispersionFmt, "EXPR", padRight("", width+2), "HITS", "D", "DP")
+
+	for i, expr := range tp.Exprs {
+		if err := writeDispersionRow(ui.Out, truncate(expr.String(), 24), positions[i], size, width, opts.Parts); err != nil {
+			return err
+		}
+	}
+
+	if len(tp.Exprs) > 1 {
+		name := tp.Name
+		if name == "" {
+			name = "all"
+		}
+		return writeDispersionRow(ui.Out, truncate(name, 24), all, size, width, opts.Parts)
+	}
+
+	return nil
+}
+
+// writeDispersionRow prints the strip plot of the positions, a column
+// marked for every span of size/width sentences with a hit.
+func writeDispersionRow(w io.Writer, name string, positions []int, size, width, parts int) error {
+	strip := []rune(strings.Repeat(" ", width))
+	for _, p := range positions {
+		if p >= 0 && p < size {
+			strip[p*width/size] = '|'
+		}
+	}
+
+	d := stat.Disperse(positions, size, parts)
+	dStr, dpStr := "-", "-"
+	if d.Hits > 0 {
+		dStr = fmt.Sprintf("%.2f", d.JuillandD)
+		dpStr = fmt.Sprintf("%.2f", d.DP)
+	}
+
+	_, err := fmt.Fprintf(w, liveDispersionFmt, name, "["+string(strip)+"]", fmt.Sprint(d.Hits), dStr, dpStr)
+	return err
+}
+
+func padRight(s string, n int) string {
+	return s + strings.Repeat(" ", max(0, n-len([]rune(s))))
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/revelaction/segrob/drill"
+	"github.com/revelaction/segrob/match"
+	"github.com/revelaction/segrob/render"
+	"github.com/revelaction/segrob/search"
+	"github.com/revelaction/segrob/storage"
+	"github.com/revelaction/segrob/topic"
+	"golang.org/x/term"
+)
+
+// Drill command
+func liveDrillCommand(ctx context.Context, dr storage.DocRepository, tr storage.TopicRepository, indexRepo storage.TopicIndexReader, drr storage.DrillRepository, opts LiveDrillOptions, args []string, ui UI) (err error) {
+
+	// See liveQueryCommand: go-prompt may leave the terminal in raw mode.
+	fd := int(os.Stdin.Fd())
+	state, gErr := term.GetState(fd)
+	if gErr == nil {
+		defer func() {
+			err = errors.Join(err, term.Restore(fd, state))
+		}()
+	}
+
+	tp, err := resolveTopicArgs(ctx, tr, opts.UserID, args)
+	if err != nil {
+		return err
+	}
+
+	labelIDs, err := resolveLabelIDs(ctx, dr, opts.Labels)
+	if err != nil {
+		return err
+	}
+
+	index, err := freshTopicIndex(ctx, indexRepo, opts.UserID, tp)
+	if err != nil {
+		return err
+	}
+
+	// The limit bounds the search for new sentences: the due ones are read
+	// from the drill state, wherever they are in the topic.
+	matches, err := dueMatches(ctx, dr, drr, opts.UserID, tp, labelIDs)
+	if err != nil {
+		return err
+	}
+
+	s := search.New(dr, search.Options{Topic: tp, LabelIDs: labelIDs, Limit: opts.Limit, Index: index})
+	found, err := s.Collect(ctx)
+	if err != nil {
+		return err
+	}
+
+	type key struct {
+		docID      string
+		sentenceID int
+	}
+	due := make(map[key]bool, len(matches))
+	for _, sm := range matches {
+		due[key{sm.Sentence.DocId, sm.Sentence.SentenceId}] = true
+	}
+	for _, sm := range found {
+		if !due[key{sm.Sentence.DocId, sm.Sentence.SentenceId}] {
+			matches = append(matches, sm)
+		}
+	}
+
+	r := render.NewCLIRenderer()
+	r.HasColor = !opts.NoColor
+
+	h := drill.NewHandler(drr, r, opts.UserID, opts.NewCards)
+	return h.Run(ctx, matches)
+}
+
+// dueMatches returns the matches of the topic among the sentences due for
+// review by the user, in the documents having all labelIDs.
+func dueMatches(ctx context.Context, dr storage.DocReader, drr storage.DrillReader, userID string, tp topic.Topic, labelIDs []int) ([]*match.SentenceMatch, error) {
+	cards, err := drr.ReadAll(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := dr.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	docLabels := make(map[string][]int, len(docs))
+	for _, d := range docs {
+		docLabels[d.Id] = d.LabelIDs
+	}
+
+	now := time.Now()
+	var matches []*match.SentenceMatch
+	for _, c := range cards {
+		labels, ok := docLabels[c.DocID]
+		if c.Due.After(now) || !ok || !hasAllLabels(labels, labelIDs) {
+			continue
+		}
+
+		zero := 0
+		sentences, err := dr.Nlp(ctx, c.DocID, c.SentenceID, &zero)
+		if err != nil {
+			return nil, err
+		}
+		// the document may have been unpublished
+		if len(sentences) == 0 {
+			continue
+		}
+
+		if sm := search.MatchTopic(tp, sentences[0]); sm != nil {
+			matches = append(matches, sm)
+		}
+	}
+
+	return matches, nil
+}
@@ -1,9 +1,10 @@
 package main
 
 import (
+	"context"
 	"github.com/revelaction/segrob/storage"
 )
 
-func liveDumpTopicCommand(src storage.TopicReader, opts LiveDumpTopicOptions, ui UI) error {
-	return dumpTopics(src, opts.UserID, ui)
+func liveDumpTopicCommand(ctx context.Context, src storage.TopicReader, opts LiveDumpTopicOptions, ui UI) error {
+	return dumpTopics(ctx, src, opts.UserID, ui)
 }
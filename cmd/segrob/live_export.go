@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/revelaction/segrob/conllu"
+	sent "github.com/revelaction/segrob/sentence"
+	"github.com/revelaction/segrob/storage"
+	"github.com/revelaction/segrob/vert"
+)
+
+// sentenceWriter is implemented by the export formats.
+type sentenceWriter interface {
+	Write(s sent.Sentence) error
+	Flush() error
+}
+
+// liveExportCommand writes the sentences of the documents, of the documents
+// having all the labels or of all the documents, as CoNLL-U or vertical.
+// Label and full exports are streamed in batches.
+func liveExportCommand(ctx context.Context, dr storage.DocReader, opts LiveExportOptions, ids []string, ui UI) (err error) {
+	var w io.Writer = ui.Out
+	if opts.Output != "" {
+		f, cErr := os.Create(opts.Output)
+		if cErr != nil {
+			return fmt.Errorf("failed to create output file %s: %w", opts.Output, cErr)
+		}
+		defer func() {
+			err = errors.Join(err, f.Close())
+		}()
+		w = f
+	}
+
+	var sw sentenceWriter
+	switch opts.Format {
+	case "vert":
+		docs, err := dr.List(ctx)
+		if err != nil {
+			return err
+		}
+		labels, err := docLabels(ctx, dr, docs)
+		if err != nil {
+			return err
+		}
+		sw = vert.NewWriter(w, labels)
+	default:
+		sw = conllu.NewWriter(w)
+	}
+
+	n, skipped := 0, 0
+	write := func(s sent.Sentence) error {
+		err := sw.Write(s)
+		if errors.Is(err, sent.ErrTree) {
+			skipped++
+			_, _ = fmt.Fprintf(ui.Err, "skipped %s\n", err)
+			return nil
+		}
+		n++
+		return err
+	}
+
+	if opts.All || len(opts.Labels) > 0 {
+		labelIDs, err := resolveLabelIDsStrict(ctx, dr, opts.Labels)
+		if err != nil {
+			return err
+		}
+		if err := scanLabelSentences(ctx, dr, labelIDs, write); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range ids {
+		sentences, err := dr.Nlp(ctx, id, 0, nil)
+		if err != nil {
+			return err
+		}
+		if len(sentences) == 0 {
+			return fmt.Errorf("document %s not found", id)
+		}
+		for _, s := range sentences {
+			if err := write(s); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(ui.Err, "%d sentences\n", n)
+	if skipped > 0 {
+		_, _ = fmt.Fprintf(ui.Err, "%d sentences skipped: invalid dependency tree\n", skipped)
+	}
+	return nil
+}
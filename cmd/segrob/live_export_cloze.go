@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/revelaction/segrob/match"
+	"github.com/revelaction/segrob/render"
+	topicsample "github.com/revelaction/segrob/sample/topic"
+	"github.com/revelaction/segrob/search"
+	"github.com/revelaction/segrob/storage"
+)
+
+// clozeCard is one flashcard: the blinded sentence, the solution and the tags.
+type clozeCard struct {
+	Front string
+	Back  string
+	Tags  []string
+}
+
+// liveExportClozeCommand writes cloze flashcards for the sentences matching a
+// topic or an expression. The matched tokens are masked in the front of the
+// card; the back shows the original sentence with the lemma and tag of every
+// hidden token.
+func liveExportClozeCommand(ctx context.Context, dr storage.DocRepository, tr storage.TopicRepository, indexRepo storage.TopicIndexReader, opts LiveExportClozeOptions, args []string, ui UI) (err error) {
+	tp, err := resolveTopicArgs(ctx, tr, "", args)
+	if err != nil {
+		return err
+	}
+
+	labelIDs, err := resolveLabelIDs(ctx, dr, opts.Labels)
+	if err != nil {
+		return err
+	}
+
+	var results []*match.SentenceMatch
+	if opts.Sample > 0 {
+		if len(labelIDs) != 1 {
+			return errors.New("--sample requires exactly one existing --label (the sampler works within one book)")
+		}
+
+		sampler := topicsample.New(dr, tp, topicsample.Options{
+			Size:                 opts.Sample,
+			MinSizePerExpression: 1,
+			CandidateBudget:      opts.Budget,
+			LabelID:              labelIDs[0],
+		})
+
+		results, err = sampler.Sample(ctx)
+		if err != nil {
+			return err
+		}
+	} else {
+		index, err := freshTopicIndex(ctx, indexRepo, "", tp)
+		if err != nil {
+			return err
+		}
+
+		s := search.New(dr, search.Options{Topic: tp, LabelIDs: labelIDs, Limit: opts.Limit, Index: index})
+		results, err = s.Collect(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	docs, err := dr.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	labels, err := docLabels(ctx, dr, docs)
+	if err != nil {
+		return err
+	}
+
+	r := render.NewCLIRenderer()
+	r.HasColor = false
+
+	cards := make([]clozeCard, 0, len(results))
+	for _, sm := range results {
+		cards = append(cards, newClozeCard(r, sm, labels[sm.Sentence.DocId]))
+	}
+
+	var w io.Writer = ui.Out
+	if opts.Output != "" {
+		f, cErr := os.Create(opts.Output)
+		if cErr != nil {
+			return fmt.Errorf("failed to create output file %s: %w", opts.Output, cErr)
+		}
+		defer func() {
+			err = errors.Join(err, f.Close())
+		}()
+		w = f
+	}
+
+	switch opts.Format {
+	case "csv":
+		err = writeClozeCSV(w, cards)
+	default:
+		err = writeClozeTSV(w, cards)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(ui.Err, "Exported %d cards.\n", len(cards))
+	return err
+}
+
+func newClozeCard(r *render.CLIRenderer, sm *match.SentenceMatch, docLabels []string) clozeCard {
+	hidden := sm.AllTokens()
+	sort.Slice(hidden, func(i, j int) bool { return hidden[i].Index < hidden[j].Index })
+
+	var solutions []string
+	lastIndex := -1
+	for _, t := range hidden {
+		if t.Index == lastIndex {
+			continue
+		}
+		lastIndex = t.Index
+		tag := strings.TrimPrefix(t.Tag, t.Pos+"__")
+		solutions = append(solutions, fmt.Sprintf("%s = %s (%s)", t.Text, t.Lemma, tag))
+	}
+
+	var tags []string
+	if sm.TopicName != "" {
+		tags = append(tags, sm.TopicName)
+	}
+	tags = append(tags, docLabels...)
+
+	return clozeCard{
+		Front: r.SentenceBlindedString(sm.Sentence.Tokens, hidden),
+		Back:  r.SentenceString(sm.Sentence.Tokens, hidden) + " — " + strings.Join(solutions, "; "),
+		Tags:  tags,
+	}
+}
+
+// writeClozeTSV writes the cards as tab separated values with the file
+// headers understood by the Anki importer.
+func writeClozeTSV(w io.Writer, cards []clozeCard) error {
+	_, err := fmt.Fprint(w, "#separator:tab\n#html:false\n#tags column:3\n")
+	if err != nil {
+		return err
+	}
+
+	clean := strings.NewReplacer("\t", " ", "\n", " ")
+	for _, c := range cards {
+		_, err = fmt.Fprintf(w, "%s\t%s\t%s\n", clean.Replace(c.Front), clean.Replace(c.Back), clean.Replace(strings.Join(c.Tags, " ")))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeClozeCSV writes the cards as CSV with a front,back,tags header.
+func writeClozeCSV(w io.Writer, cards []clozeCard) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"front", "back", "tags"}); err != nil {
+		return err
+	}
+
+	for _, c := range cards {
+		if err := cw.Write([]string{c.Front, c.Back, strings.Join(c.Tags, " ")}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 
 	"github.com/revelaction/segrob/match"
 	"github.com/revelaction/segrob/render"
@@ -9,9 +11,9 @@ import (
 	"github.com/revelaction/segrob/storage"
 )
 
-func liveFindTopicsCommand(docRepo storage.DocRepository, topicRepo storage.TopicRepository, opts LiveFindTopicsOptions, docId string, sentId int, ui UI) error {
+func liveFindTopicsCommand(ctx context.Context, docRepo storage.DocRepository, topicRepo storage.TopicRepository, indexRepo storage.TopicIndexReader, opts LiveFindTopicsOptions, docId string, sentId int, ui UI) error {
 	zero := 0
-	sentences, err := docRepo.Nlp(docId, sentId, &zero)
+	sentences, err := docRepo.Nlp(ctx, docId, sentId, &zero)
 	if err != nil {
 		return err
 	}
@@ -20,10 +22,10 @@ func liveFindTopicsCommand(docRepo storage.DocRepository, topicRepo storage.Topi
 		return fmt.Errorf("sentence index %d not found", sentId)
 	}
 
-	return renderTopics(sentences[0], topicRepo, opts, ui)
+	return renderTopics(ctx, sentences[0], topicRepo, indexRepo, opts, ui)
 }
 
-func renderTopics(s sent.Sentence, topicRepo storage.TopicRepository, opts LiveFindTopicsOptions, ui UI) error {
+func renderTopics(ctx context.Context, s sent.Sentence, topicRepo storage.TopicRepository, indexRepo storage.TopicIndexReader, opts LiveFindTopicsOptions, ui UI) error {
 	r := render.NewCLIRenderer()
 	r.HasColor = false
 
@@ -34,7 +36,14 @@ func renderTopics(s sent.Sentence, topicRepo storage.TopicRepository, opts LiveF
 		return err
 	}
 
-	allTopics, err := topicRepo.ReadAll("")
+	allTopics, err := topicRepo.ReadAll(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	// Topics with a fresh index entry are only matched if the index lists
+	// the sentence; the matchers still run for the highlighting.
+	skip, err := unmatchedFreshTopics(ctx, indexRepo, s)
 	if err != nil {
 		return err
 	}
@@ -45,6 +54,9 @@ func renderTopics(s sent.Sentence, topicRepo storage.TopicRepository, opts LiveF
 	r.Format = opts.Format
 
 	for _, tp := range allTopics {
+		if skip[tp.Name] {
+			continue
+		}
 		for _, expr := range tp.Exprs {
 			matcher := match.NewMatcher(expr)
 			sm := matcher.MatchSentence(s)
@@ -55,8 +67,60 @@ func renderTopics(s sent.Sentence, topicRepo storage.TopicRepository, opts LiveF
 			sm.TopicName = tp.Name
 			res := []*match.SentenceMatch{sm}
 			r.Render(res)
+
+			if err := renderMatchTree(ui.Out, sm, opts); err != nil {
+				return err
+			}
 		}
 	}
 
 	return nil
 }
+
+// unmatchedFreshTopics returns the topics whose index is fresh and does not
+// contain the sentence.
+func unmatchedFreshTopics(ctx context.Context, indexRepo storage.TopicIndexReader, s sent.Sentence) (map[string]bool, error) {
+	states, err := indexRepo.IndexState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	matched, err := indexRepo.SentenceTopics(ctx, s.DocId, s.SentenceId)
+	if err != nil {
+		return nil, err
+	}
+
+	skip := make(map[string]bool)
+	for _, st := range states {
+		if st.Fresh() {
+			skip[st.Name] = true
+		}
+	}
+	for _, name := range matched {
+		delete(skip, name)
+	}
+
+	return skip, nil
+}
+
+// renderMatchTree writes the dependency tree or arcs of a match, with the
+// matched tokens highlighted, if requested.
+func renderMatchTree(w io.Writer, sm *match.SentenceMatch, opts LiveFindTopicsOptions) error {
+	switch {
+	case opts.Tree:
+		err := render.Tree(w, sm.Sentence.Tokens, sm.AllTokens(), true)
+		if err != nil {
+			return err
+		}
+	case opts.Arcs:
+		err := render.Arcs(w, sm.Sentence.Tokens, sm.AllTokens(), true)
+		if err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+
+	_, err := fmt.Fprintln(w)
+	return err
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/revelaction/segrob/match"
+	"github.com/revelaction/segrob/render"
+	"github.com/revelaction/segrob/search"
+	sent "github.com/revelaction/segrob/sentence"
+	"github.com/revelaction/segrob/storage"
+)
+
+// gradedContentPos are the POS of the content words checked against the
+// known lemmas. Proper nouns, function words and punctuation are not.
+var gradedContentPos = map[string]bool{"NOUN": true, "VERB": true, "ADJ": true, "ADV": true}
+
+// gradedSentence is a candidate sentence with its ranking keys.
+type gradedSentence struct {
+	*match.SentenceMatch
+	Unknown []string // unknown content lemmas
+	Words   int      // tokens that are not punctuation
+	MinFreq int      // corpus frequency of the rarest content lemma
+	seq     int      // scan order, for stable ties
+}
+
+// gradedBefore reports whether a ranks before b: fewer unknown lemmas, then
+// fewer words, then a more frequent rarest lemma, then scan order.
+func gradedBefore(a, b gradedSentence) bool {
+	if len(a.Unknown) != len(b.Unknown) {
+		return len(a.Unknown) < len(b.Unknown)
+	}
+	if a.Words != b.Words {
+		return a.Words < b.Words
+	}
+	if a.MinFreq != b.MinFreq {
+		return a.MinFreq > b.MinFreq
+	}
+	return a.seq < b.seq
+}
+
+// gradedHeap keeps the best graded sentences seen so far, the worst at the
+// root so that it is dropped when a better one comes.
+type gradedHeap []gradedSentence
+
+func (h gradedHeap) Len() int           { return len(h) }
+func (h gradedHeap) Less(i, j int) bool { return gradedBefore(h[j], h[i]) }
+func (h gradedHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *gradedHeap) Push(x any)        { *h = append(*h, x.(gradedSentence)) }
+
+func (h *gradedHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// liveGradedCommand finds the sentences whose content lemmas are all known,
+// but at most opts.MaxUnknown, optionally among the matches of a topic or
+// expression. Sentences with fewer unknown lemmas rank first, then the
+// shorter ones, then those whose rarest lemma is more frequent.
+func liveGradedCommand(ctx context.Context, dr storage.DocReader, tr storage.TopicReader, indexRepo storage.TopicIndexReader, opts LiveGradedOptions, args []string, ui UI) error {
+	known, err := readKnownLemmas(opts.Known)
+	if err != nil {
+		return err
+	}
+
+	labelIDs, err := resolveLabelIDs(ctx, dr, opts.Labels)
+	if err != nil {
+		return err
+	}
+
+	// Lemma frequencies are maintained at publish time; if the table is
+	// empty all the sentences tie on frequency.
+	if err := warnMissingLemmaFreq(ctx, dr, ui); err != nil {
+		return err
+	}
+	freqs, err := dr.LemmaFreqs(ctx, "", nil, 0)
+	if err != nil {
+		return err
+	}
+	freq := make(map[string]int, len(freqs))
+	for _, f := range freqs {
+		freq[f.Lemma] = f.Sentences
+	}
+
+	// Only the opts.Limit best sentences are kept while scanning
+	var best gradedHeap
+	scanned, qualified := 0, 0
+	check := func(sm *match.SentenceMatch) {
+		scanned++
+		g, ok := gradeSentence(sm, known, freq, opts)
+		if !ok {
+			return
+		}
+		qualified++
+		g.seq = scanned
+		heap.Push(&best, g)
+		if best.Len() > opts.Limit {
+			heap.Pop(&best)
+		}
+	}
+
+	if len(args) == 0 {
+		err = scanLabelSentences(ctx, dr, labelIDs, func(s sent.Sentence) error {
+			check(&match.SentenceMatch{Sentence: s})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		tp, err := resolveTopicArgs(ctx, tr, opts.UserID, args)
+		if err != nil {
+			return err
+		}
+
+		index, err := freshTopicIndex(ctx, indexRepo, opts.UserID, tp)
+		if err != nil {
+			return err
+		}
+
+		s := search.New(dr, search.Options{Topic: tp, LabelIDs: labelIDs, Index: index})
+		for sm, err := range s.All(ctx) {
+			if err != nil {
+				return err
+			}
+			check(sm)
+		}
+	}
+
+	graded := []gradedSentence(best)
+	sort.Slice(graded, func(i, j int) bool { return gradedBefore(graded[i], graded[j]) })
+
+	_, _ = fmt.Fprintf(ui.Err, "%d known lemmas, %d of %d sentences with at most %d unknown\n", len(known), qualified, scanned, opts.MaxUnknown)
+
+	results := make([]*match.SentenceMatch, len(graded))
+	unknown := make(map[*match.SentenceMatch][]string, len(graded))
+	for i, g := range graded {
+		results[i] = g.SentenceMatch
+		unknown[g.SentenceMatch] = g.Unknown
+	}
+
+	r := render.NewCLIRenderer()
+	r.HasColor = !opts.NoColor
+	r.HasPrefix = !opts.NoPrefix
+	r.Format = "all"
+	r.PrefixTopicFunc = func(sm *match.SentenceMatch) string {
+		if len(unknown[sm]) == 0 {
+			return ""
+		}
+		return "[? " + strings.Join(unknown[sm], " ") + "] "
+	}
+
+	list, err := dr.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, d := range list {
+		r.AddDocName(d.Id, d.Source)
+	}
+
+	r.Render(results)
+	return nil
+}
+
+// gradeSentence returns the ranking keys of a sentence, and false if it has
+// too many unknown lemmas or too few words. Without a topic match, the
+// unknown tokens are set as the match so that they are highlighted.
+func gradeSentence(sm *match.SentenceMatch, known map[string]bool, freq map[string]int, opts LiveGradedOptions) (gradedSentence, bool) {
+	g := gradedSentence{SentenceMatch: sm, MinFreq: math.MaxInt}
+
+	var unknownTokens []sent.Token
+	content := 0
+	for _, t := range sm.Sentence.Tokens {
+		if t.Pos == "PUNCT" || t.Pos == "SPACE" {
+			continue
+		}
+		g.Words++
+		if !gradedContentPos[t.Pos] {
+			continue
+		}
+		content++
+		g.MinFreq = min(g.MinFreq, freq[t.Lemma])
+		if !known[t.Lemma] {
+			unknownTokens = append(unknownTokens, t)
+			if !slices.Contains(g.Unknown, t.Lemma) {
+				g.Unknown = append(g.Unknown, t.Lemma)
+			}
+		}
+	}
+
+	if content == 0 || g.Words < opts.MinWords || len(g.Unknown) > opts.MaxUnknown {
+		return g, false
+	}
+
+	if len(sm.Tokens) == 0 && len(unknownTokens) > 0 {
+		sm.Tokens = [][]sent.Token{unknownTokens}
+	}
+
+	return g, true
+}
+
+// readKnownLemmas reads a list of lemmas, one per line. Blank lines and
+// lines starting with # are skipped.
+func readKnownLemmas(path string) (map[string]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	known := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		known[strings.ToLower(strings.Fields(line)[0])] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(known) == 0 {
+		return nil, fmt.Errorf("no lemmas in %s", path)
+	}
+
+	return known, nil
+}
@@ -1,19 +1,25 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/revelaction/segrob/storage"
 )
 
-func liveInitCommand(mgr storage.SchemaManager, opts LiveInitOptions, ui UI) error {
+func liveInitCommand(ctx context.Context, mgr storage.SchemaManager, opts LiveInitOptions, ui UI) error {
 
-	err := mgr.Create("live_canonical.sql")
+	err := mgr.Create(ctx, "live_canonical.sql")
 	if err != nil {
 		return err
 	}
 
-	err = mgr.Create("live_optimization.sql")
+	err = mgr.Create(ctx, "live_optimization.sql")
+	if err != nil {
+		return err
+	}
+
+	err = mgr.Upgrade(ctx)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	sent "github.com/revelaction/segrob/sentence"
+	"github.com/revelaction/segrob/stat"
+	"github.com/revelaction/segrob/storage"
+)
+
+const liveKeynessFmt = "%-24s %10s %10s %10s %9s\n"
+
+// keyword is an item with its frequencies in the two subcorpora.
+type keyword struct {
+	Item string
+	stat.Keyness
+}
+
+// signedLL is the log-likelihood with the sign of the log-ratio, so that
+// sorting puts the target keywords first and the reference ones last.
+func (k keyword) signedLL() float64 {
+	if k.LogRatio() < 0 {
+		return -k.LogLikelihood()
+	}
+	return k.LogLikelihood()
+}
+
+// liveKeynessCommand compares the lemma, POS or feature frequencies of two
+// label subcorpora.
+func liveKeynessCommand(ctx context.Context, dr storage.DocReader, opts LiveKeynessOptions, ui UI) error {
+	targetIDs, err := resolveLabelIDsStrict(ctx, dr, opts.Target)
+	if err != nil {
+		return err
+	}
+
+	refIDs, err := resolveLabelIDsStrict(ctx, dr, opts.Reference)
+	if err != nil {
+		return err
+	}
+
+	count := subcorpusLemmas
+	unit := "sentences"
+	if opts.By != "lemma" {
+		count = subcorpusTokens(opts.By)
+		unit = "tokens"
+	}
+
+	target, targetN, err := count(ctx, dr, targetIDs)
+	if err != nil {
+		return err
+	}
+
+	ref, refN, err := count(ctx, dr, refIDs)
+	if err != nil {
+		return err
+	}
+
+	if targetN == 0 || refN == 0 {
+		return fmt.Errorf("empty subcorpus: %d target and %d reference %s", targetN, refN, unit)
+	}
+
+	var keywords []keyword
+	for item, n := range target {
+		if n+ref[item] >= opts.Min {
+			keywords = append(keywords, newKeyword(item, n, ref[item], targetN, refN))
+		}
+	}
+	for item, n := range ref {
+		if _, ok := target[item]; !ok && n >= opts.Min {
+			keywords = append(keywords, newKeyword(item, 0, n, targetN, refN))
+		}
+	}
+
+	sort.Slice(keywords, func(i, j int) bool {
+		si, sj := keywords[i].signedLL(), keywords[j].signedLL()
+		if si != sj {
+			return si > sj
+		}
+		return keywords[i].Item < keywords[j].Item
+	})
+
+	_, _ = fmt.Fprintf(ui.Err, "target: %d %s, reference: %d %s, %d items\n", targetN, unit, refN, unit, len(keywords))
+
+	if opts.Format == "csv" {
+		return writeKeynessCSV(ui.Out, keywords)
+	}
+
+	var positive, negative []keyword
+	for _, k := range keywords {
+		if k.LogRatio() > 0 && len(positive) < opts.Top {
+			positive = append(positive, k)
+		}
+	}
+	for i := len(keywords) - 1; i >= 0 && len(negative) < opts.Top; i-- {
+		if keywords[i].LogRatio() < 0 {
+			negative = append(negative, keywords[i])
+		}
+	}
+
+	if err := writeKeynessTable(ui.Out, "Target keywords ("+strings.Join(opts.Target, ", ")+")", positive); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(ui.Out)
+	return writeKeynessTable(ui.Out, "Reference keywords ("+strings.Join(opts.Reference, ", ")+")", negative)
+}
+
+func newKeyword(item string, target, ref, targetN, refN int) keyword {
+	return keyword{
+		Item: item,
+		Keyness: stat.Keyness{
+			Target:    float64(target),
+			Reference: float64(ref),
+			TargetN:   float64(targetN),
+			RefN:      float64(refN),
+		},
+	}
+}
+
+// subcorpusCounter returns the frequency of each item in a label subcorpus
+// and the size of the subcorpus.
+type subcorpusCounter func(ctx context.Context, dr storage.DocReader, labelIDs []int) (map[string]int, int, error)
+
+// subcorpusLemmas counts the sentences of each lemma from the lemma index.
+func subcorpusLemmas(ctx context.Context, dr storage.DocReader, labelIDs []int) (map[string]int, int, error) {
+	counts, err := dr.LemmaCounts(ctx, labelIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	n, err := dr.CountSentences(ctx, labelIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return counts, n, nil
+}
+
+// subcorpusTokens returns a counter of the POS or the morphological features
+// (e.g. Tense=Past) of the tokens. Punctuation is not counted.
+func subcorpusTokens(by string) subcorpusCounter {
+	return func(ctx context.Context, dr storage.DocReader, labelIDs []int) (map[string]int, int, error) {
+		counts := make(map[string]int)
+		n := 0
+		err := scanLabelSentences(ctx, dr, labelIDs, func(s sent.Sentence) error {
+			for _, t := range s.Tokens {
+				if t.Pos == "PUNCT" || t.Pos == "SPACE" {
+					continue
+				}
+				n++
+				if by == "pos" {
+					counts[t.Pos]++
+					continue
+				}
+				for name, value := range t.Features() {
+					counts[name+"="+value]++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+
+		return counts, n, nil
+	}
+}
+
+func writeKeynessTable(w io.Writer, title string, keywords []keyword) error {
+	if _, err := fmt.Fprintf(w, "%s\n\n", title); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(w, liveKeynessFmt, "ITEM", "TARGET", "REFERENCE", "LL", "LOGRATIO")
+	for _, k := range keywords {
+		_, err := fmt.Fprintf(w, liveKeynessFmt,
+			truncate(k.Item, 24),
+			strconv.Itoa(int(k.Target)),
+			strconv.Itoa(int(k.Reference)),
+			fmt.Sprintf("%.2f", k.LogLikelihood()),
+			fmt.Sprintf("%+.2f", k.LogRatio()),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeKeynessCSV writes all the items, target keywords first.
+func writeKeynessCSV(w io.Writer, keywords []keyword) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"item", "target", "reference", "ll", "log_ratio"}); err != nil {
+		return err
+	}
+
+	for _, k := range keywords {
+		line := []string{
+			k.Item,
+			strconv.Itoa(int(k.Target)),
+			strconv.Itoa(int(k.Reference)),
+			strconv.FormatFloat(k.LogLikelihood(), 'f', 4, 64),
+			strconv.FormatFloat(k.LogRatio(), 'f', 4, 64),
+		}
+		if err := cw.Write(line); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
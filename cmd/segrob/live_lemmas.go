@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/revelaction/segrob/storage"
+)
+
+const liveLemmasFmt = "%-24s %10s %10s %6s\n"
+
+// liveLemmasCommand prints the lemma frequency table of the corpus, or of
+// the documents having all the labels. With opts.Rebuild it writes the
+// frequencies of the published documents that have none instead.
+func liveLemmasCommand(ctx context.Context, dr storage.DocRepository, opts LiveLemmasOptions, prefix string, ui UI) error {
+	if opts.Rebuild {
+		return rebuildLemmaFreq(ctx, dr, ui)
+	}
+
+	if err := warnMissingLemmaFreq(ctx, dr, ui); err != nil {
+		return err
+	}
+
+	var docIDs []string
+	if len(opts.Labels) > 0 {
+		labelIDs, err := resolveLabelIDsStrict(ctx, dr, opts.Labels)
+		if err != nil {
+			return err
+		}
+
+		docs, err := dr.List(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, d := range docs {
+			if hasAllLabels(d.LabelIDs, labelIDs) {
+				docIDs = append(docIDs, d.Id)
+			}
+		}
+
+		if len(docIDs) == 0 {
+			return fmt.Errorf("no documents with labels %v", opts.Labels)
+		}
+	}
+
+	freqs, err := dr.LemmaFreqs(ctx, prefix, docIDs, opts.Top)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(ui.Out, liveLemmasFmt, "LEMMA", "TOKENS", "SENTENCES", "DOCS")
+	for _, f := range freqs {
+		_, err := fmt.Fprintf(ui.Out, liveLemmasFmt, truncate(f.Lemma, 24), strconv.Itoa(f.Tokens), strconv.Itoa(f.Sentences), strconv.Itoa(f.Docs))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// warnMissingLemmaFreq warns that the frequencies are incomplete if some
+// published documents have none.
+func warnMissingLemmaFreq(ctx context.Context, dr storage.DocReader, ui UI) error {
+	ids, err := dr.MissingLemmaFreq(ctx)
+	if err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		_, _ = fmt.Fprintf(ui.Err, "Warning: %d published document(s) have no lemma frequencies; run 'segrob live lemmas --rebuild'\n", len(ids))
+	}
+	return nil
+}
+
+// rebuildLemmaFreq computes the lemma frequencies of the published
+// documents without them from their live sentences, so that it works after
+// publish --move removed the corpus NLP. Each document is written in its own
+// transaction.
+func rebuildLemmaFreq(ctx context.Context, dr storage.DocRepository, ui UI) error {
+	ids, err := dr.MissingLemmaFreq(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		_, err = fmt.Fprintf(ui.Err, "All published documents have lemma frequencies.\n")
+		return err
+	}
+
+	_, _ = fmt.Fprintf(ui.Err, "Computing the lemma frequencies of %d document(s)...\n\n", len(ids))
+
+	for i, id := range ids {
+		start := time.Now()
+		sentences, err := dr.Nlp(ctx, id, 0, nil)
+		if err == nil {
+			err = dr.WriteLemmaFreq(ctx, id, storage.CountLemmas(sentences))
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(ui.Err, "[%d/%d] %s ❌ %v\n", i+1, len(ids), id, err)
+			return err
+		}
+		_, _ = fmt.Fprintf(ui.Err, "[%d/%d] %s ✅ %s\n", i+1, len(ids), id, time.Since(start))
+	}
+
+	return nil
+}
+
+// hasAllLabels reports whether ids contains every id of want.
+func hasAllLabels(ids []int, want []int) bool {
+	for _, id := range want {
+		if !slices.Contains(ids, id) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,19 +1,20 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/revelaction/segrob/storage"
 )
 
-func liveLsCommand(repo storage.DocReader, opts LiveLsOptions, ui UI) error {
-	docs, err := repo.List()
+func liveLsCommand(ctx context.Context, repo storage.DocReader, opts LiveLsOptions, ui UI) error {
+	docs, err := repo.List(ctx)
 	if err != nil {
 		return err
 	}
 
-	allLabels, err := repo.ListLabels("")
+	allLabels, err := repo.ListLabels(ctx, "")
 	if err != nil {
 		return err
 	}
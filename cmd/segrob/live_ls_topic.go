@@ -1,15 +1,16 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/revelaction/segrob/storage"
 )
 
 // liveLsTopicCommand lists all topics
-func liveLsTopicCommand(tr storage.TopicRepository, opts LiveLsTopicOptions, ui UI) error {
+func liveLsTopicCommand(ctx context.Context, tr storage.TopicRepository, opts LiveLsTopicOptions, ui UI) error {
 
-	topicLib, err := tr.ReadAll("")
+	topicLib, err := tr.ReadAll(ctx, "")
 	if err != nil {
 		return err
 	}
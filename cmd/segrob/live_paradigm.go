@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/revelaction/segrob/search"
+	"github.com/revelaction/segrob/storage"
+	"github.com/revelaction/segrob/topic"
+)
+
+const (
+	liveParadigmFmt       = "%-16s %-6s %6s  %-60s %s\n"
+	liveParadigmCellWidth = 14
+)
+
+// paradigmPersons are the columns of the conjugation table.
+var paradigmPersons = []string{"1 Sing", "2 Sing", "3 Sing", "1 Plur", "2 Plur", "3 Plur"}
+
+// paradigmMoods and paradigmTenses order the rows of the conjugation table.
+// Features missing from a form (the tense of the conditional) sort last.
+var (
+	paradigmMoods  = []string{"Ind", "Sub", "Cnd", "Imp"}
+	paradigmTenses = []string{"Pres", "Past", "Imp", "Pqp", "Fut"}
+	paradigmNonFin = []string{"Inf", "Ger", "Part"}
+)
+
+// paradigmForm is a surface form of the lemma with a set of features, its
+// occurrences and the first sentence where it was found.
+type paradigmForm struct {
+	Form       string
+	Pos        string
+	Features   map[string]string
+	Feats      string // the features as written in the tag
+	Count      int
+	DocId      string
+	SentenceId int
+}
+
+// liveParadigmCommand collects the surface forms of a lemma, grouped by their
+// UD features. The sentences are read through the lemma index.
+func liveParadigmCommand(ctx context.Context, dr storage.DocReader, opts LiveParadigmOptions, lemma string, ui UI) error {
+	labelIDs, err := resolveLabelIDs(ctx, dr, opts.Labels)
+	if err != nil {
+		return err
+	}
+
+	numSentences := 0
+	forms := make(map[string]*paradigmForm)
+	s := search.New(dr, search.Options{
+		Expr:     topic.TopicExpr{Items: []topic.TopicExprItem{{Lemma: lemma}}},
+		LabelIDs: labelIDs,
+	})
+	for sm, err := range s.All(ctx) {
+		if err != nil {
+			return err
+		}
+		numSentences++
+
+		for _, t := range sm.Sentence.Tokens {
+			if t.Lemma != lemma {
+				continue
+			}
+			form := strings.ToLower(t.Text)
+			_, feats, _ := strings.Cut(t.Tag, "__")
+			key := form + "\x00" + t.Pos + "\x00" + feats
+			f, ok := forms[key]
+			if !ok {
+				f = &paradigmForm{
+					Form:       form,
+					Pos:        t.Pos,
+					Features:   t.Features(),
+					Feats:      feats,
+					DocId:      sm.Sentence.DocId,
+					SentenceId: sm.Sentence.SentenceId,
+				}
+				forms[key] = f
+			}
+			f.Count++
+		}
+	}
+
+	if numSentences == 0 {
+		return fmt.Errorf("lemma %q not found", lemma)
+	}
+
+	list := make([]*paradigmForm, 0, len(forms))
+	for _, f := range forms {
+		list = append(list, f)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Pos != list[j].Pos {
+			return list[i].Pos < list[j].Pos
+		}
+		if list[i].Feats != list[j].Feats {
+			return list[i].Feats < list[j].Feats
+		}
+		return list[i].Form < list[j].Form
+	})
+
+	_, _ = fmt.Fprintf(ui.Err, "%s: %d sentences, %d forms\n", lemma, numSentences, len(list))
+
+	var verbs []*paradigmForm
+	for _, f := range list {
+		if f.Pos == "VERB" || f.Pos == "AUX" {
+			verbs = append(verbs, f)
+		}
+	}
+	if len(verbs) > 0 {
+		writeConjugation(ui.Out, verbs)
+		_, _ = fmt.Fprintln(ui.Out)
+	}
+
+	_, _ = fmt.Fprintf(ui.Out, liveParadigmFmt, "FORM", "POS", "COUNT", "FEATURES", "EXAMPLE")
+	for _, f := range list {
+		feats := f.Feats
+		if feats == "" {
+			feats = "-"
+		}
+		_, err := fmt.Fprintf(ui.Out, liveParadigmFmt,
+			truncate(f.Form, 16),
+			f.Pos,
+			strconv.Itoa(f.Count),
+			truncate(feats, 60),
+			fmt.Sprintf("%s %d", f.DocId, f.SentenceId),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeConjugation writes the finite forms of a verb as a table of mood and
+// tense by person and number, followed by the non-finite forms.
+func writeConjugation(w io.Writer, verbs []*paradigmForm) {
+	cells := make(map[string]map[string][]string)
+	nonFinite := make(map[string][]string)
+	for _, f := range verbs {
+		if vf := f.Features["VerbForm"]; vf != "Fin" {
+			if vf != "" {
+				nonFinite[vf] = appendForm(nonFinite[vf], f.Form)
+			}
+			continue
+		}
+		row := strings.TrimSpace(f.Features["Mood"] + " " + f.Features["Tense"])
+		col := f.Features["Person"] + " " + f.Features["Number"]
+		if cells[row] == nil {
+			cells[row] = make(map[string][]string)
+		}
+		cells[row][col] = appendForm(cells[row][col], f.Form)
+	}
+
+	var rows []string
+	for row := range cells {
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		mi, ti := paradigmRank(rows[i])
+		mj, tj := paradigmRank(rows[j])
+		if mi != mj {
+			return mi < mj
+		}
+		if ti != tj {
+			return ti < tj
+		}
+		return rows[i] < rows[j]
+	})
+
+	writeParadigmRow(w, "", paradigmPersons)
+	for _, row := range rows {
+		var line []string
+		for _, p := range paradigmPersons {
+			cell := strings.Join(cells[row][p], "/")
+			if cell == "" {
+				cell = "-"
+			}
+			line = append(line, cell)
+		}
+		writeParadigmRow(w, row, line)
+	}
+
+	for _, vf := range paradigmNonFin {
+		if forms, ok := nonFinite[vf]; ok {
+			_, _ = fmt.Fprintf(w, "%s%s\n", paradigmCell(vf), strings.Join(forms, "/"))
+		}
+	}
+}
+
+func writeParadigmRow(w io.Writer, name string, cells []string) {
+	line := paradigmCell(name)
+	for _, c := range cells {
+		line += paradigmCell(c)
+	}
+	_, _ = fmt.Fprintln(w, strings.TrimRight(line, " "))
+}
+
+// paradigmCell pads a cell of the conjugation table, keeping a space
+// between columns.
+func paradigmCell(s string) string {
+	return padRight(truncate(s, liveParadigmCellWidth-1), liveParadigmCellWidth)
+}
+
+// paradigmRank returns the position of the mood and the tense of a row in
+// paradigmMoods and paradigmTenses, or their length if unknown.
+func paradigmRank(row string) (int, int) {
+	mood, tense, _ := strings.Cut(row, " ")
+	return rank(paradigmMoods, mood), rank(paradigmTenses, tense)
+}
+
+func rank(order []string, s string) int {
+	if i := slices.Index(order, s); i >= 0 {
+		return i
+	}
+	return len(order)
+}
+
+func appendForm(forms []string, form string) []string {
+	if slices.Contains(forms, form) {
+		return forms
+	}
+	return append(forms, form)
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"os"
 
+	"github.com/revelaction/segrob/history"
 	"github.com/revelaction/segrob/query"
 	"github.com/revelaction/segrob/render"
 	"github.com/revelaction/segrob/storage"
@@ -11,7 +13,7 @@ import (
 )
 
 // Query command
-func liveQueryCommand(dr storage.DocRepository, tr storage.TopicRepository, opts LiveQueryOptions, ui UI) (err error) {
+func liveQueryCommand(ctx context.Context, dr storage.DocRepository, tr storage.TopicRepository, opts LiveQueryOptions, ui UI) (err error) {
 
 	// Terminal Reset
 	//
@@ -30,7 +32,7 @@ func liveQueryCommand(dr storage.DocRepository, tr storage.TopicRepository, opts
 		}()
 	}
 
-	topicLib, rErr := tr.ReadAll("")
+	topicLib, rErr := tr.ReadAll(ctx, opts.UserID)
 	if rErr != nil {
 		return rErr
 	}
@@ -42,8 +44,13 @@ func liveQueryCommand(dr storage.DocRepository, tr storage.TopicRepository, opts
 	r.Format = opts.Format
 
 	// now present the REPL and prepare for topic in the REPL
-	t := query.NewHandler(dr, topicLib, r, opts.Labels)
-	tErr := t.Run()
+	t := query.NewHandler(dr, tr, topicLib, r, opts.Labels, opts.UserID)
+	t.HistoryPath, err = history.Path("query", opts.UserID)
+	if err != nil {
+		return err
+	}
+
+	tErr := t.Run(ctx)
 	if tErr != nil {
 		return tErr
 	}
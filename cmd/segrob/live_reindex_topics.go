@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/revelaction/segrob/search"
+	"github.com/revelaction/segrob/storage"
+	"github.com/revelaction/segrob/topic"
+)
+
+const liveIndexStateFmt = "%-30s %-8s %10s  %s\n"
+
+// liveReindexTopicsCommand rebuilds the sentence→topic index of the default
+// topics. Each topic is scanned through the lemma index and written in its
+// own transaction, so an interrupted run keeps the topics already done.
+func liveReindexTopicsCommand(ctx context.Context, dr storage.DocReader, tr storage.TopicReader, indexRepo storage.TopicIndexRepository, opts LiveReindexTopicsOptions, names []string, ui UI) error {
+	states, err := indexRepo.IndexState(ctx)
+	if err != nil {
+		return err
+	}
+
+	if opts.Status {
+		_, _ = fmt.Fprintf(ui.Out, liveIndexStateFmt, "TOPIC", "STATE", "SENTENCES", "INDEXED")
+		for _, st := range states {
+			indexed := ""
+			if st.IsIndexed() {
+				indexed = storage.TimeFormat(st.Indexed)
+			}
+			_, _ = fmt.Fprintf(ui.Out, liveIndexStateFmt, st.Name, indexState(st), fmt.Sprint(st.Sentences), indexed)
+		}
+		return nil
+	}
+
+	// Select the topics: the arguments, all, or the stale ones
+	var todo []string
+	switch {
+	case len(names) > 0:
+		known := make(map[string]bool, len(states))
+		for _, st := range states {
+			known[st.Name] = true
+		}
+		for _, name := range names {
+			if !known[name] {
+				return fmt.Errorf("topic %w: %s", storage.ErrNotFound, name)
+			}
+		}
+		todo = names
+	default:
+		for _, st := range states {
+			if opts.All || !st.Fresh() {
+				todo = append(todo, st.Name)
+			}
+		}
+	}
+
+	if len(todo) == 0 {
+		_, err = fmt.Fprintf(ui.Err, "All %d topic(s) are fresh.\n", len(states))
+		return err
+	}
+
+	_, _ = fmt.Fprintf(ui.Err, "Indexing %d topic(s)...\n\n", len(todo))
+
+	for i, name := range todo {
+		start := time.Now()
+		n, err := reindexTopic(ctx, dr, tr, indexRepo, name)
+		if err != nil {
+			_, _ = fmt.Fprintf(ui.Err, "[%d/%d] %-30s ❌ %v\n", i+1, len(todo), name, err)
+			if errors.Is(err, storage.ErrTopicChanged) {
+				return fmt.Errorf("%w\n\nRe-run the command to index the new version", err)
+			}
+			return err
+		}
+		_, _ = fmt.Fprintf(ui.Err, "[%d/%d] %-30s ✅ %d sentences %s\n", i+1, len(todo), name, n, time.Since(start))
+	}
+
+	return nil
+}
+
+// reindexTopic searches all the sentences of the topic and replaces its
+// index rows. It returns the number of sentences indexed.
+func reindexTopic(ctx context.Context, dr storage.DocReader, tr storage.TopicReader, indexRepo storage.TopicIndexWriter, name string) (int, error) {
+	tp, err := tr.Read(ctx, "", name)
+	if err != nil {
+		return 0, err
+	}
+
+	var rowids []int64
+	sr := search.New(dr, search.Options{Topic: tp})
+	for sm, err := range sr.All(ctx) {
+		if err != nil {
+			return 0, err
+		}
+		rowids = append(rowids, sm.Sentence.Rowid)
+	}
+
+	if err := indexRepo.WriteTopicIndex(ctx, tp, rowids); err != nil {
+		return 0, err
+	}
+
+	return len(rowids), nil
+}
+
+// indexState names the index state of a topic for the status table.
+func indexState(st storage.TopicIndexState) string {
+	switch {
+	case st.Fresh():
+		return "fresh"
+	case st.IsIndexed():
+		return "stale"
+	default:
+		return "missing"
+	}
+}
+
+// freshTopicIndex returns indexRepo if tp is a default topic (read for user
+// "") with a fresh index, so that a search can read its sentences from the
+// index, and nil otherwise.
+func freshTopicIndex(ctx context.Context, indexRepo storage.TopicIndexReader, userID string, tp topic.Topic) (storage.TopicIndexReader, error) {
+	if tp.Name == "" || userID != "" {
+		return nil, nil
+	}
+
+	states, err := indexRepo.IndexState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, st := range states {
+		if st.Name == tp.Name && st.Fresh() {
+			return indexRepo, nil
+		}
+	}
+
+	return nil, nil
+}
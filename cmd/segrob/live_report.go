@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/revelaction/segrob/match"
+	"github.com/revelaction/segrob/render"
+	"github.com/revelaction/segrob/search"
+	"github.com/revelaction/segrob/storage"
+	"github.com/revelaction/segrob/topic"
+)
+
+// liveReportCommand writes a self-contained HTML report with all the
+// sentences matching the expressions of a topic.
+func liveReportCommand(ctx context.Context, dr storage.DocRepository, tr storage.TopicRepository, opts LiveReportOptions, name string, ui UI) error {
+	tp, err := tr.Read(ctx, "", name)
+	if err != nil {
+		return err
+	}
+
+	labelIDs, err := resolveLabelIDs(ctx, dr, opts.Labels)
+	if err != nil {
+		return err
+	}
+
+	// Each expression is scanned on its own, so that a sentence matched by
+	// several expressions is listed under each of them in the report.
+	var results []*match.SentenceMatch
+	for _, expr := range tp.Exprs {
+		limit := 0
+		if opts.Limit > 0 {
+			limit = opts.Limit - len(results)
+			if limit <= 0 {
+				break
+			}
+		}
+
+		s := search.New(dr, search.Options{
+			Topic:    topic.Topic{Name: tp.Name, Exprs: []topic.TopicExpr{expr}},
+			LabelIDs: labelIDs,
+			Limit:    limit,
+		})
+		matches, err := s.Collect(ctx)
+		if err != nil {
+			return err
+		}
+
+		results = append(results, matches...)
+	}
+
+	return writeHTMLReport(ctx, dr, "segrob report: "+tp.Name, opts.Output, results, ui)
+}
+
+// writeHTMLReport renders results as an HTML report to the file path, or to
+// ui.Out if path is empty.
+func writeHTMLReport(ctx context.Context, dr storage.DocReader, title string, path string, results []*match.SentenceMatch, ui UI) (err error) {
+	docs, err := dr.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	labels, err := docLabels(ctx, dr, docs)
+	if err != nil {
+		return err
+	}
+
+	r := render.NewHTMLRenderer()
+	r.Title = title
+	for _, d := range docs {
+		r.AddDoc(d.Id, d.Source, labels[d.Id])
+	}
+
+	var w io.Writer = ui.Out
+	if path != "" {
+		f, cErr := os.Create(path)
+		if cErr != nil {
+			return fmt.Errorf("failed to create report file %s: %w", path, cErr)
+		}
+		defer func() {
+			err = errors.Join(err, f.Close())
+		}()
+		w = f
+	}
+
+	if err := r.Render(w, results); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+
+	if path != "" {
+		_, err = fmt.Fprintf(ui.Err, "Report with %d matches written to %s\n", len(results), path)
+		return err
+	}
+
+	return nil
+}
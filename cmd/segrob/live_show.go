@@ -1,18 +1,18 @@
 package main
 
 import (
+	"context"
 	"github.com/revelaction/segrob/storage"
 )
 
-func liveShowCommand(repo storage.DocRepository, opts ShowOptions, id string, ui UI) error {
-	sentences, err := repo.Nlp(id, 0, nil)
+func liveShowCommand(ctx context.Context, repo storage.DocRepository, opts ShowOptions, id string, ui UI) error {
+	sentences, err := repo.Nlp(ctx, id, 0, nil)
 	if err != nil {
 		return err
 	}
 
 	if opts.Stats {
-		printStats(sentences, ui)
-		return nil
+		return printStats(sentences, opts.Format, ui)
 	}
 
 	renderDoc(sentences, opts, ui)
@@ -1,15 +1,16 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/revelaction/segrob/render"
 	"github.com/revelaction/segrob/storage"
 )
 
-func liveShowSentCommand(repo storage.DocRepository, opts LiveShowSentOptions, docId string, sentId int, ui UI) error {
+func liveShowSentCommand(ctx context.Context, repo storage.DocRepository, opts LiveShowSentOptions, docId string, sentId int, ui UI) error {
 	zero := 0
-	sentences, err := repo.Nlp(docId, sentId, &zero)
+	sentences, err := repo.Nlp(ctx, docId, sentId, &zero)
 	if err != nil {
 		return err
 	}
@@ -19,11 +20,19 @@ func liveShowSentCommand(repo storage.DocRepository, opts LiveShowSentOptions, d
 	}
 
 	if opts.Stats {
-		printStats(sentences, ui)
-		return nil
+		return printStats(sentences, opts.Format, ui)
 	}
 
 	s := sentences[0]
+	switch {
+	case opts.Tree:
+		return render.Tree(ui.Out, s.Tokens, nil, false)
+	case opts.Arcs:
+		return render.Arcs(ui.Out, s.Tokens, nil, false)
+	case opts.Dot:
+		return render.Dot(ui.Out, s.Tokens, nil)
+	}
+
 	r := render.NewCLIRenderer()
 	r.HasColor = false
 	prefix := fmt.Sprintf("✍  %d ", sentId)
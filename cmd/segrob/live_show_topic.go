@@ -1,14 +1,15 @@
 package main
 
 import (
+	"context"
 	"github.com/revelaction/segrob/render"
 	"github.com/revelaction/segrob/storage"
 )
 
 // liveShowTopicCommand prints the expressions of a topic
-func liveShowTopicCommand(tr storage.TopicRepository, opts LiveShowTopicOptions, name string, ui UI) error {
+func liveShowTopicCommand(ctx context.Context, tr storage.TopicRepository, opts LiveShowTopicOptions, name string, ui UI) error {
 
-	tp, err := tr.Read("", name)
+	tp, err := tr.Read(ctx, "", name)
 	if err != nil {
 		return err
 	}
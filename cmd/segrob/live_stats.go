@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+
+	sent "github.com/revelaction/segrob/sentence"
+	"github.com/revelaction/segrob/stat"
+	"github.com/revelaction/segrob/storage"
+)
+
+// liveStatsCommand prints the statistics of the sentences of the documents
+// having all the labels, reading them in batches.
+func liveStatsCommand(ctx context.Context, dr storage.DocReader, opts LiveStatsOptions, ui UI) error {
+	labelIDs, err := resolveLabelIDsStrict(ctx, dr, opts.Labels)
+	if err != nil {
+		return err
+	}
+
+	hdl := stat.NewHandler()
+	batch := make([]sent.Sentence, 1)
+	err = scanLabelSentences(ctx, dr, labelIDs, func(s sent.Sentence) error {
+		batch[0] = s
+		hdl.Aggregate(batch)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeStats(ui.Out, hdl.Get(), opts.Format)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/revelaction/segrob/storage"
+)
+
+// timelineBarWidth is the width in cells of the longest bar of the chart.
+const timelineBarWidth = 40
+
+// timelineBucket aggregates the documents published in a year or decade.
+type timelineBucket struct {
+	Start     int // first year of the bucket
+	Docs      int
+	Sentences int
+	Count     int
+}
+
+// liveTimelineCommand buckets the documents by their date label and shows the
+// sentences matching a topic or expression per 10k sentences of each bucket.
+func liveTimelineCommand(ctx context.Context, dr storage.DocReader, tr storage.TopicReader, indexRepo storage.TopicIndexReader, opts LiveTimelineOptions, args []string, ui UI) error {
+	tp, err := resolveTopicArgs(ctx, tr, "", args)
+	if err != nil {
+		return err
+	}
+
+	docs, err := dr.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	sentences, err := dr.SentenceCounts(ctx)
+	if err != nil {
+		return err
+	}
+	docs = countedDocs(docs, sentences)
+
+	labels, err := docLabels(ctx, dr, docs)
+	if err != nil {
+		return err
+	}
+
+	counts, err := topicDocCounts(ctx, dr, indexRepo, tp)
+	if err != nil {
+		return err
+	}
+
+	size := 10
+	if opts.Bucket == "year" {
+		size = 1
+	}
+
+	byStart := make(map[int]*timelineBucket)
+	undated := 0
+	for _, d := range docs {
+		year, err := strconv.Atoi(extractLabelValue(labels[d.Id], "date:"))
+		if err != nil {
+			undated++
+			continue
+		}
+
+		start := year - year%size
+		b, ok := byStart[start]
+		if !ok {
+			b = &timelineBucket{Start: start}
+			byStart[start] = b
+		}
+		b.Docs++
+		b.Sentences += sentences[d.Id]
+		b.Count += counts[d.Id]
+	}
+
+	if undated > 0 {
+		_, _ = fmt.Fprintf(ui.Err, "Skipped %d document(s) without a date label.\n", undated)
+	}
+
+	if len(byStart) == 0 {
+		return fmt.Errorf("no dated documents found")
+	}
+
+	// Fill the gaps so that the chart has a linear time axis
+	first, last := -1, 0
+	for start := range byStart {
+		if first < 0 || start < first {
+			first = start
+		}
+		last = max(last, start)
+	}
+	var buckets []timelineBucket
+	for start := first; start <= last; start += size {
+		if b, ok := byStart[start]; ok {
+			buckets = append(buckets, *b)
+			continue
+		}
+		buckets = append(buckets, timelineBucket{Start: start})
+	}
+
+	if opts.Format == "csv" {
+		return writeTimelineCSV(ui.Out, buckets, opts.Bucket)
+	}
+
+	return writeTimelineChart(ui.Out, buckets, opts.Bucket)
+}
+
+// bucketName returns the year, or the decade as "1940s".
+func bucketName(start int, bucket string) string {
+	if bucket == "decade" {
+		return strconv.Itoa(start) + "s"
+	}
+	return strconv.Itoa(start)
+}
+
+func writeTimelineChart(w io.Writer, buckets []timelineBucket, bucket string) error {
+	top := 0.0
+	for _, b := range buckets {
+		top = max(top, per10k(b.Count, b.Sentences))
+	}
+
+	for _, b := range buckets {
+		freq := per10k(b.Count, b.Sentences)
+		n := 0
+		if top > 0 {
+			n = int(freq / top * timelineBarWidth)
+		}
+		if n == 0 && b.Count > 0 {
+			n = 1
+		}
+
+		bar := strings.Repeat("█", n) + strings.Repeat(" ", timelineBarWidth-n)
+		detail := fmt.Sprintf("%8s", "-")
+		if b.Docs > 0 {
+			detail = fmt.Sprintf("%8.1f  %d/%d sentences, %d docs", freq, b.Count, b.Sentences, b.Docs)
+		}
+		if _, err := fmt.Fprintf(w, "%-6s %s %s\n", bucketName(b.Start, bucket), bar, detail); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeTimelineCSV writes one line per bucket, empty buckets included.
+func writeTimelineCSV(w io.Writer, buckets []timelineBucket, bucket string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{bucket, "docs", "sentences", "count", "per10k"}); err != nil {
+		return err
+	}
+
+	for _, b := range buckets {
+		line := []string{
+			strconv.Itoa(b.Start),
+			strconv.Itoa(b.Docs),
+			strconv.Itoa(b.Sentences),
+			strconv.Itoa(b.Count),
+			strconv.FormatFloat(per10k(b.Count, b.Sentences), 'f', 2, 64),
+		}
+		if err := cw.Write(line); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
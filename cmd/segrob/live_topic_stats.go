@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/revelaction/segrob/search"
+	sent "github.com/revelaction/segrob/sentence"
+	"github.com/revelaction/segrob/storage"
+	"github.com/revelaction/segrob/topic"
+)
+
+// topicStatsCell is the frequency of a topic in a row of the matrix.
+type topicStatsCell struct {
+	Topic  string  `json:"topic"`
+	Count  int     `json:"count"`
+	Per10k float64 `json:"per10k"`
+}
+
+// topicStatsRow is a document, or a label value grouping documents.
+type topicStatsRow struct {
+	Row       string           `json:"row"`
+	Docs      int              `json:"docs"`
+	Sentences int              `json:"sentences"`
+	Cells     []topicStatsCell `json:"cells"`
+}
+
+// liveTopicStatsCommand prints a matrix of documents (or label values) ×
+// topics with the number of matching sentences and the matches per 10k
+// sentences of each row.
+func liveTopicStatsCommand(ctx context.Context, dr storage.DocReader, tr storage.TopicReader, indexRepo storage.TopicIndexReader, opts LiveTopicStatsOptions, names []string, ui UI) error {
+	topics, err := readTopics(ctx, tr, names)
+	if err != nil {
+		return err
+	}
+
+	if len(topics) == 0 {
+		return fmt.Errorf("no topics found")
+	}
+
+	docs, err := dr.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	sentences, err := dr.SentenceCounts(ctx)
+	if err != nil {
+		return err
+	}
+	docs = countedDocs(docs, sentences)
+
+	labels, err := docLabels(ctx, dr, docs)
+	if err != nil {
+		return err
+	}
+
+	var docIDs []string
+	for _, d := range docs {
+		docIDs = append(docIDs, d.Id)
+	}
+	groups, rowNames := groupDocs(docIDs, labels, opts.By)
+
+	rows := make([]topicStatsRow, len(rowNames))
+	for i, name := range rowNames {
+		rows[i] = topicStatsRow{Row: name, Docs: len(groups[name])}
+		for _, id := range groups[name] {
+			rows[i].Sentences += sentences[id]
+		}
+	}
+
+	for _, tp := range topics {
+		counts, err := topicDocCounts(ctx, dr, indexRepo, tp)
+		if err != nil {
+			return err
+		}
+
+		for i, name := range rowNames {
+			n := 0
+			for _, id := range groups[name] {
+				n += counts[id]
+			}
+			rows[i].Cells = append(rows[i].Cells, topicStatsCell{
+				Topic:  tp.Name,
+				Count:  n,
+				Per10k: per10k(n, rows[i].Sentences),
+			})
+		}
+	}
+
+	switch opts.Format {
+	case "csv":
+		return writeTopicStatsCSV(ui.Out, topics, rows)
+	case "json":
+		enc := json.NewEncoder(ui.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	default:
+		return writeTopicStatsTable(ui.Out, topics, rows)
+	}
+}
+
+// countedDocs keeps the documents with a sentence count: the live ones.
+func countedDocs(docs []sent.Meta, sentences map[string]int) []sent.Meta {
+	var counted []sent.Meta
+	for _, d := range docs {
+		if _, ok := sentences[d.Id]; ok {
+			counted = append(counted, d)
+		}
+	}
+	return counted
+}
+
+// readTopics returns the named default topics, or all of them if names is
+// empty.
+func readTopics(ctx context.Context, tr storage.TopicReader, names []string) (topic.Library, error) {
+	if len(names) == 0 {
+		return tr.ReadAll(ctx, "")
+	}
+
+	var lib topic.Library
+	for _, name := range names {
+		tp, err := tr.Read(ctx, "", name)
+		if err != nil {
+			return nil, err
+		}
+		lib = append(lib, tp)
+	}
+
+	return lib, nil
+}
+
+// topicDocCounts returns the number of sentences matching the topic in each
+// document. A fresh topic index is read directly; otherwise the topic is
+// searched.
+func topicDocCounts(ctx context.Context, dr storage.DocReader, indexRepo storage.TopicIndexReader, tp topic.Topic) (map[string]int, error) {
+	index, err := freshTopicIndex(ctx, indexRepo, "", tp)
+	if err != nil {
+		return nil, err
+	}
+	if index != nil {
+		return index.TopicDocCounts(ctx, tp.Name)
+	}
+
+	counts := make(map[string]int)
+	s := search.New(dr, search.Options{Topic: tp})
+	for sm, err := range s.All(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		counts[sm.Sentence.DocId]++
+	}
+
+	return counts, nil
+}
+
+// groupDocs groups the documents by the values of their labels with the
+// given prefix (a document with several values, e.g. two creators, is in
+// several groups). Documents without such a label are grouped under "-". If
+// by is empty, every document is its own group. The group names are
+// returned in order: documents in list order, label values sorted.
+func groupDocs(docIDs []string, labels map[string][]string, by string) (map[string][]string, []string) {
+	groups := make(map[string][]string)
+	if by == "" {
+		for _, id := range docIDs {
+			groups[id] = []string{id}
+		}
+		return groups, docIDs
+	}
+
+	prefix := by + ":"
+	for _, id := range docIDs {
+		found := false
+		for _, l := range labels[id] {
+			if value, ok := strings.CutPrefix(l, prefix); ok {
+				groups[value] = append(groups[value], id)
+				found = true
+			}
+		}
+		if !found {
+			groups["-"] = append(groups["-"], id)
+		}
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return groups, names
+}
+
+// per10k normalizes a count of matching sentences by the number of
+// sentences.
+func per10k(count, sentences int) float64 {
+	if sentences == 0 {
+		return 0
+	}
+	return float64(count) * 10000 / float64(sentences)
+}
+
+func writeTopicStatsTable(w io.Writer, topics topic.Library, rows []topicStatsRow) error {
+	header := []string{"ROW", "SENTENCES"}
+	for _, tp := range topics {
+		header = append(header, tp.Name)
+	}
+
+	table := [][]string{header}
+	for _, r := range rows {
+		line := []string{r.Row, strconv.Itoa(r.Sentences)}
+		for _, c := range r.Cells {
+			line = append(line, fmt.Sprintf("%d (%.1f)", c.Count, c.Per10k))
+		}
+		table = append(table, line)
+	}
+
+	widths := make([]int, len(header))
+	for _, line := range table {
+		for i, cell := range line {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	for _, line := range table {
+		var b strings.Builder
+		for i, cell := range line {
+			pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			if i == 0 {
+				b.WriteString(cell + pad)
+				continue
+			}
+			// Numbers are right aligned
+			b.WriteString("  " + pad + cell)
+		}
+		if _, err := fmt.Fprintln(w, b.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeTopicStatsCSV writes one line per row with a count and a per10k
+// column for each topic.
+func writeTopicStatsCSV(w io.Writer, topics topic.Library, rows []topicStatsRow) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"row", "docs", "sentences"}
+	for _, tp := range topics {
+		header = append(header, tp.Name, tp.Name+"_per10k")
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		line := []string{r.Row, strconv.Itoa(r.Docs), strconv.Itoa(r.Sentences)}
+		for _, c := range r.Cells {
+			line = append(line, strconv.Itoa(c.Count), strconv.FormatFloat(c.Per10k, 'f', 2, 64))
+		}
+		if err := cw.Write(line); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -12,11 +13,11 @@ import (
 // disappears from FindCandidates immediately; the remaining phases clean up the
 // supporting rows. Each phase is idempotent: if the data is already gone it
 // prints "(already removed)" and continues.
-func liveUnpublishCommand(docRepo storage.DocRepository, opts LiveUnpublishOptions, ui UI) error {
+func liveUnpublishCommand(ctx context.Context, docRepo storage.DocRepository, indexRepo storage.TopicIndexRepository, opts LiveUnpublishOptions, ui UI) error {
 	id := opts.ID
 
 	// Verify the document exists before starting.
-	exists, err := docRepo.Exists(id)
+	exists, err := docRepo.Exists(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to check existence: %w", err)
 	}
@@ -25,14 +26,15 @@ func liveUnpublishCommand(docRepo storage.DocRepository, opts LiveUnpublishOptio
 		return nil
 	}
 
-	// Phase 1 — THE LIVE SWITCH: cut lemma index first.
-	hasLemmas, err := docRepo.HasLemmaOptimization(id)
+	// Phase 1 — THE LIVE SWITCH: cut lemma index first, with the lemma
+	// frequencies.
+	hasLemmas, err := docRepo.HasLemmaOptimization(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to check lemma optimization: %w", err)
 	}
 	if hasLemmas {
 		start := time.Now()
-		dErr := docRepo.DeleteLemmaOptimization(id)
+		dErr := docRepo.DeleteLemmaOptimization(ctx, id)
 		if dErr != nil {
 			_, _ = fmt.Fprintf(ui.Err, "DeleteLemmaOpt  ❌ %v\n", dErr)
 			return fmt.Errorf("DeleteLemmaOptimization failed: %w", dErr)
@@ -42,14 +44,31 @@ func liveUnpublishCommand(docRepo storage.DocRepository, opts LiveUnpublishOptio
 		_, _ = fmt.Fprintf(ui.Err, "DeleteLemmaOpt  ✅ (already removed)\n")
 	}
 
-	// Phase 2 — remove label index.
-	hasLabels, err := docRepo.HasLabelsOptimization(id)
+	// Phase 2 — remove topic index.
+	hasTopics, err := indexRepo.HasTopicIndex(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to check topic index: %w", err)
+	}
+	if hasTopics {
+		start := time.Now()
+		dErr := indexRepo.DeleteDocTopicIndex(ctx, id)
+		if dErr != nil {
+			_, _ = fmt.Fprintf(ui.Err, "DeleteTopicIdx  ❌ %v\n", dErr)
+			return fmt.Errorf("DeleteDocTopicIndex failed: %w", dErr)
+		}
+		_, _ = fmt.Fprintf(ui.Err, "DeleteTopicIdx  ✅ %s\n", time.Since(start))
+	} else {
+		_, _ = fmt.Fprintf(ui.Err, "DeleteTopicIdx  ✅ (already removed)\n")
+	}
+
+	// Phase 3 — remove label index.
+	hasLabels, err := docRepo.HasLabelsOptimization(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to check labels optimization: %w", err)
 	}
 	if hasLabels {
 		start := time.Now()
-		dErr := docRepo.DeleteLabelsOptimization(id)
+		dErr := docRepo.DeleteLabelsOptimization(ctx, id)
 		if dErr != nil {
 			_, _ = fmt.Fprintf(ui.Err, "DeleteLabelsOpt ❌ %v\n", dErr)
 			return fmt.Errorf("DeleteLabelsOptimization failed: %w", dErr)
@@ -59,14 +78,14 @@ func liveUnpublishCommand(docRepo storage.DocRepository, opts LiveUnpublishOptio
 		_, _ = fmt.Fprintf(ui.Err, "DeleteLabelsOpt ✅ (already removed)\n")
 	}
 
-	// Phase 3 — remove sentences.
-	hasSentences, err := docRepo.HasSentences(id)
+	// Phase 4 — remove sentences.
+	hasSentences, err := docRepo.HasSentences(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to check sentences: %w", err)
 	}
 	if hasSentences {
 		start := time.Now()
-		dErr := docRepo.DeleteNlpData(id)
+		dErr := docRepo.DeleteNlpData(ctx, id)
 		if dErr != nil {
 			_, _ = fmt.Fprintf(ui.Err, "DeleteNlpData   ❌ %v\n", dErr)
 			return fmt.Errorf("DeleteNlpData failed: %w", dErr)
@@ -76,14 +95,14 @@ func liveUnpublishCommand(docRepo storage.DocRepository, opts LiveUnpublishOptio
 		_, _ = fmt.Fprintf(ui.Err, "DeleteNlpData   ✅ (already removed)\n")
 	}
 
-	// Phase 4 — remove doc row.
-	exists, err = docRepo.Exists(id)
+	// Phase 5 — remove doc row.
+	exists, err = docRepo.Exists(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to check existence: %w", err)
 	}
 	if exists {
 		start := time.Now()
-		dErr := docRepo.DeleteMeta(id)
+		dErr := docRepo.DeleteMeta(ctx, id)
 		if dErr != nil {
 			_, _ = fmt.Fprintf(ui.Err, "DeleteMeta      ❌ %v\n", dErr)
 			return fmt.Errorf("DeleteMeta failed: %w", dErr)
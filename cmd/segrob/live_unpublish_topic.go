@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/revelaction/segrob/storage"
@@ -9,8 +10,8 @@ import (
 // liveUnpublishTopicCommand removes a topic from the live topics repository.
 // The command is idempotent: a DELETE is issued; if the topic doesn't exist,
 // that's fine (already removed).
-func liveUnpublishTopicCommand(topicWriter storage.TopicWriter, opts LiveUnpublishTopicOptions, name string, ui UI) error {
-	err := topicWriter.Delete("", name)
+func liveUnpublishTopicCommand(ctx context.Context, topicWriter storage.TopicWriter, opts LiveUnpublishTopicOptions, name string, ui UI) error {
+	err := topicWriter.Delete(ctx, "", name)
 	if err != nil {
 		// For SQLite backend, DELETE with WHERE clause returns no error if row doesn't exist
 		// So any error here is a real failure
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
+	"syscall"
 )
 
 var (
@@ -23,6 +26,11 @@ type UI struct {
 func main() {
 	ui := UI{Out: os.Stdout, Err: os.Stderr}
 
+	// SIGINT and SIGTERM cancel the context passed to the storage: long
+	// scans and writes stop and their transactions are rolled back.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	cmd, args, err := parseMainArgs(os.Args[1:], ui)
 	if err != nil {
 		if errors.Is(err, flag.ErrHelp) {
@@ -31,14 +39,17 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := runCommand(cmd, args, ui); err != nil {
+	if err := runCommand(ctx, cmd, args, ui); err != nil {
 		if errors.Is(err, flag.ErrHelp) {
+			stop()
 			os.Exit(0)
 		}
 		fprintErr(ui.Err, err)
+		stop()
 		os.Exit(1)
 	}
 
+	stop()
 	os.Exit(0)
 }
 
@@ -46,7 +57,7 @@ func fprintErr(w io.Writer, err error) {
 	_, _ = fmt.Fprintf(w, "Error: %v\n", err)
 }
 
-func runCommand(cmd string, args []string, ui UI) (err error) {
+func runCommand(ctx context.Context, cmd string, args []string, ui UI) (err error) {
 
 	setup := NewSetup()
 	defer func() {
@@ -62,7 +73,7 @@ func runCommand(cmd string, args []string, ui UI) (err error) {
 
 	case "help":
 		if len(args) > 0 {
-			return runCommand(args[0], append(args[1:], "--help"), ui)
+			return runCommand(ctx, args[0], append(args[1:], "--help"), ui)
 		}
 		fs := flag.NewFlagSet("segrob", flag.ContinueOnError)
 		fs.SetOutput(ui.Out)
@@ -71,7 +82,22 @@ func runCommand(cmd string, args []string, ui UI) (err error) {
 		return nil
 
 	case "live":
-		return runLiveCommand(args, setup, ui)
+		return runLiveCommand(ctx, args, setup, ui)
+
+	case "serve":
+		opts, err := parseServeArgs(args, ui)
+		if err != nil {
+			return err
+		}
+		docRepo, err := setup.NewDocRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		topicRepo, err := setup.NewLiveTopicRepository(opts.DbPath)
+		if err != nil {
+			return err
+		}
+		return serveCommand(ctx, docRepo, topicRepo, opts, ui)
 
 	case "bash":
 		if err := parseBashArgs(args, ui); err != nil {
@@ -90,7 +116,7 @@ func runCommand(cmd string, args []string, ui UI) (err error) {
 		return completeCommand(completeArgs, ui)
 
 	case "corpus":
-		return runCorpusCommand(args, setup, ui)
+		return runCorpusCommand(ctx, args, setup, ui)
 	}
 
 	return fmt.Errorf("unknown command: %s", cmd)
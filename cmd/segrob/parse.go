@@ -43,6 +43,7 @@ type ShowOptions struct {
 	Count  *int
 	DbPath string // path to segrob.db or corpus.db
 	Stats  bool   // -s/--stats: show document statistics
+	Format string // --format: statistics as table or json
 }
 
 // stringSliceFlag implements flag.Value for multi-value strings
@@ -101,6 +102,38 @@ func (o *optionalInt) Set(s string) error {
 	return nil
 }
 
+// optionalFloat implements flag.Value for optional float flags
+type optionalFloat struct {
+	value *float64
+}
+
+func (o *optionalFloat) String() string {
+	if o.value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*o.value, 'g', -1, 64)
+}
+
+func (o *optionalFloat) Set(s string) error {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	o.value = &v
+	return nil
+}
+
+// countTrue returns the number of set flags, to check exclusive options.
+func countTrue(flags ...bool) int {
+	n := 0
+	for _, f := range flags {
+		if f {
+			n++
+		}
+	}
+	return n
+}
+
 func parseMainArgs(args []string, ui UI) (string, []string, error) {
 	fs := flag.NewFlagSet("segrob", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
@@ -176,6 +209,7 @@ func setupUsage(fs *flag.FlagSet) {
 		_, _ = fmt.Fprintf(w, "\nCommands:\n")
 		_, _ = fmt.Fprintf(w, helpCmdFmt, "corpus", "Manage the corpus staging database.")
 		_, _ = fmt.Fprintf(w, helpCmdFmt, "live", "Manage the live production database.")
+		_, _ = fmt.Fprintf(w, helpCmdFmt, "serve", "Serve the live database as an HTTP JSON API.")
 		_, _ = fmt.Fprintf(w, helpCmdFmt, "bash", "Output bash completion script.")
 		_, _ = fmt.Fprintf(w, helpCmdFmt, "version", "Show version information.")
 		_, _ = fmt.Fprintf(w, helpCmdFmt, "env", "Show SEGROB environment variables.")
@@ -184,3 +218,55 @@ func setupUsage(fs *flag.FlagSet) {
 		_, _ = fmt.Fprintf(w, "\nVersion: %s, commit %s\n", BuildTag, BuildCommit)
 	}
 }
+
+// ServeOptions configures the HTTP JSON API server.
+type ServeOptions struct {
+	Addr   string
+	DbPath string
+}
+
+func parseServeArgs(args []string, ui UI) (ServeOptions, error) {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	const serveSynopsis = "[options]"
+
+	var opts ServeOptions
+	fs.StringVar(&opts.Addr, "addr", ":8080", "")
+	fs.StringVar(&opts.Addr, "a", ":8080", "")
+	fs.StringVar(&opts.DbPath, "db", os.Getenv("SEGROB_LIVE_DB"), "")
+
+	fs.Usage = func() {
+		w := fs.Output()
+		fprintUsage(w, fs, serveSynopsis)
+		_, _ = fmt.Fprintf(w, "  Serve the live database as an HTTP JSON API: documents, sentences,\n")
+		_, _ = fmt.Fprintf(w, "  find with cursor pagination, find-topics and per-user topic CRUD.\n")
+		_, _ = fmt.Fprintf(w, "  A web UI to browse and search the corpus is served at /. See the\n")
+		_, _ = fmt.Fprintf(w, "  server package for the endpoints.\n")
+		_, _ = fmt.Fprintf(w, "\nOptions:\n")
+		printOpt(w, "--db", "PATH", "Live SQLite file (or SEGROB_LIVE_DB)")
+		printOpt(w, "-a, --addr", "ADDR", "Listen address (default: :8080)")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(ui.Out)
+			fs.Usage()
+			return opts, err
+		}
+		fprintUsageError(ui.Err, fs, serveSynopsis)
+		return opts, err
+	}
+
+	if opts.DbPath == "" {
+		fprintUsageError(ui.Err, fs, serveSynopsis)
+		return opts, errors.New("database must be specified via --db or SEGROB_LIVE_DB")
+	}
+
+	if _, err := os.Stat(opts.DbPath); err != nil {
+		fprintUsageError(ui.Err, fs, serveSynopsis)
+		return opts, fmt.Errorf("database not found: %s", opts.DbPath)
+	}
+
+	return opts, nil
+}
@@ -232,6 +232,62 @@ func parseCorpusIngestNlpArgs(args []string, ui UI) (CorpusIngestNlpOptions, err
 	return opts, nil
 }
 
+type CorpusIngestConlluOptions struct {
+	DbPath string // corpus db path
+	ID     string
+	File   string // - for stdin
+	Force  bool   // -f/--force
+}
+
+func parseCorpusIngestConlluArgs(args []string, ui UI) (CorpusIngestConlluOptions, error) {
+	fs := flag.NewFlagSet("corpus ingest-conllu", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	const ingestConlluSynopsis = "[options] <id> <file>"
+
+	var opts CorpusIngestConlluOptions
+	fs.StringVar(&opts.DbPath, "db", os.Getenv("SEGROB_CORPUS_DB"), "")
+	fs.BoolVar(&opts.Force, "force", false, "")
+	fs.BoolVar(&opts.Force, "f", false, "")
+
+	fs.Usage = func() {
+		w := fs.Output()
+		fprintUsage(w, fs, ingestConlluSynopsis)
+		_, _ = fmt.Fprintf(w, "  Convert CoNLL-U annotations and store them as the document NLP data.\n")
+		_, _ = fmt.Fprintf(w, "  Multi-word token ranges (del = de el) keep the surface text.\n")
+		_, _ = fmt.Fprintf(w, "\nArguments:\n")
+		_, _ = fmt.Fprintf(w, helpArgFmt, "id", "Document ID")
+		_, _ = fmt.Fprintf(w, helpArgFmt, "file", "Path to the CoNLL-U file (- for stdin)")
+		_, _ = fmt.Fprintf(w, "\nOptions:\n")
+		printOpt(w, "--db", "FILE", "Path to the corpus SQLite database (or SEGROB_CORPUS_DB)")
+		printOpt(w, "-f, --force", "", "Force ingestion even if TxtAck is false")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(ui.Out)
+			fs.Usage()
+			return opts, err
+		}
+		fprintUsageError(ui.Err, fs, ingestConlluSynopsis)
+		return opts, err
+	}
+
+	if opts.DbPath == "" {
+		fprintUsageError(ui.Err, fs, ingestConlluSynopsis)
+		return opts, errors.New("corpus database must be specified via --db or SEGROB_CORPUS_DB")
+	}
+
+	if fs.NArg() != 2 {
+		fprintUsageError(ui.Err, fs, ingestConlluSynopsis)
+		return opts, errors.New("requires exactly two arguments: <id> <file>")
+	}
+	opts.ID = fs.Arg(0)
+	opts.File = fs.Arg(1)
+
+	return opts, nil
+}
+
 func parseCorpusShowArgs(args []string, ui UI) (ShowOptions, string, error) {
 	fs := flag.NewFlagSet("corpus show", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
@@ -406,6 +462,7 @@ func parseCorpusDumpTxtArgs(args []string, ui UI) (CorpusDumpTxtOptions, error)
 type CorpusDumpNlpOptions struct {
 	DbPath   string // --db / SEGROB_CORPUS_DB
 	NoLemmas bool   // -n, --no-lemmas
+	Format   string // -f, --format: json or conllu
 	Output   string // --output file path (empty = stdout)
 	ID       string // positional arg: document id
 }
@@ -423,6 +480,11 @@ func parseCorpusDumpNlpArgs(args []string, ui UI) (CorpusDumpNlpOptions, error)
 	fs.StringVar(&opts.Output, "output", "", "")
 	fs.StringVar(&opts.Output, "o", "", "")
 
+	opts.Format = "json"
+	formatFlag := &enumFlag{allowed: []string{"json", "conllu"}, value: &opts.Format}
+	fs.Var(formatFlag, "format", "")
+	fs.Var(formatFlag, "f", "")
+
 	fs.Usage = func() {
 		w := fs.Output()
 		fprintUsage(w, fs, dumpNlpSynopsis)
@@ -432,6 +494,7 @@ func parseCorpusDumpNlpArgs(args []string, ui UI) (CorpusDumpNlpOptions, error)
 		_, _ = fmt.Fprintf(w, "\nOptions:\n")
 		printOpt(w, "--db", "FILE", "Corpus SQLite file (or SEGROB_CORPUS_DB)")
 		printOpt(w, "-n, --no-lemmas", "", "Strip lemmas from the JSON payload")
+		printOpt(w, "-f, --format", "FORMAT", "Output format: json or conllu (default: json)")
 		printOpt(w, "-o, --output", "FILE", "Write output to FILE instead of stdout")
 	}
 
@@ -7,33 +7,158 @@ import (
 	"io"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/revelaction/segrob/render"
 )
 
 // Option structs for subcommands that have flags
 type LiveFindOptions struct {
-	Labels    []string
-	NoColor   bool
-	NoPrefix  bool
-	NMatches  int
-	Format    string
-	DocPath   string
-	Limit     int // max matched results (0 = unlimited)
+	Labels   []string
+	NoColor  bool
+	NoPrefix bool
+	NMatches int
+	Format   string
+	DocPath  string
+	Limit    int    // max matched results (0 = unlimited)
+	Budget   int    // --budget N: max candidate sentences examined (0 = unlimited)
+	HTML     string // --html: write an HTML report to this file
+	CQL      string // --cql: CQL query instead of the expression
+
+	// Sentence complexity bounds (0 or nil = no bound)
+	MinTokens      int
+	MaxTokens      int
+	MaxClauses     int
+	MaxDepth       int
+	MinReadability *float64
+	MaxReadability *float64
+	Sort           string // --sort: rowid, tokens, clauses, depth or readability
 }
 
 type LiveQueryOptions struct {
-	Labels    []string
-	NoColor   bool
-	NoPrefix  bool
-	NMatches  int
-	Format    string
+	Labels   []string
+	UserID   string // --user, -u
+	NoColor  bool
+	NoPrefix bool
+	NMatches int
+	Format   string
+	DbPath   string
+}
+
+type LiveReportOptions struct {
+	Labels []string
+	Limit  int    // max matched results (0 = unlimited)
+	Output string // -o/--output: report file (default stdout)
+	DbPath string
+}
+
+type LiveExportClozeOptions struct {
+	Labels []string
+	Limit  int    // max cards (0 = unlimited), ignored with --sample
+	Sample int    // --sample N: pick N diverse sentences with the topic sampler
+	Budget int    // --budget N: candidates scanned per expression when sampling
+	Format string // tsv or csv
+	Output string // -o/--output: cards file (default stdout)
+	DbPath string
+}
+
+type LiveDrillOptions struct {
+	Labels   []string
+	UserID   string // --user, -u
+	NewCards int    // --new N: sentences never drilled added to the session
+	Limit    int    // --limit N: max sentences scanned from the topic (0 = unlimited)
+	NoColor  bool
+	DbPath   string
+}
+
+type LiveReindexTopicsOptions struct {
+	All    bool // --all, -a: reindex fresh topics too
+	Status bool // --status, -s: only print the index state
+	DbPath string
+}
+
+type LiveTopicStatsOptions struct {
+	By     string // --by, -b: label prefix of the rows (e.g. creator), empty for docs
+	Format string // table, csv or json
+	DbPath string
+}
+
+type LiveTimelineOptions struct {
+	Bucket string // year or decade
+	Format string // chart or csv
+	DbPath string
+}
+
+type LiveDispersionOptions struct {
+	Parts  int // --parts N: parts of the book for the statistics
+	Width  int // --width N: columns of the strip plot
+	DbPath string
+}
+
+type LiveCollocatesOptions struct {
+	Labels []string
+	Window int      // --window N: tokens on each side of the node
+	Pos    []string // --pos: only collocates with these POS (repeatable)
+	Min    int      // --min N: minimum co-occurrences of a collocate
+	Top    int      // --top N: collocates printed
+	Sort   string   // ll, pmi or t
+	Format string   // table or csv
+	DbPath string
+}
+
+type LiveKeynessOptions struct {
+	Target    []string // --target, -t: labels of the target subcorpus (all required)
+	Reference []string // --reference, -r: labels of the reference subcorpus
+	By        string   // lemma, pos or feature
+	Min       int      // --min N: minimum frequency in both subcorpora together
+	Top       int      // --top N: keywords printed in each direction
+	Format    string   // table or csv
 	DbPath    string
 }
 
+type LiveLemmasOptions struct {
+	Labels  []string
+	Top     int  // --top N: lemmas printed (0 = all)
+	Rebuild bool // --rebuild: write the missing frequencies of published docs
+	DbPath  string
+}
+
+type LiveExportOptions struct {
+	Labels []string
+	All    bool   // --all: export all the documents
+	Format string // --format: conllu or vert
+	Output string // --output file path (empty = stdout)
+	DbPath string
+}
+
+type LiveGradedOptions struct {
+	Known      string // --known FILE: known lemmas, one per line
+	MaxUnknown int    // --max-unknown N: unknown content lemmas allowed
+	MinWords   int    // --min-words N: shortest sentence, in words
+	UserID     string // --user: owner of the topic
+	Labels     []string
+	Limit      int
+	NoColor    bool
+	NoPrefix   bool
+	DbPath     string
+}
+
+type LiveParadigmOptions struct {
+	Labels []string
+	DbPath string
+}
+
+type LiveStatsOptions struct {
+	Labels []string
+	Format string // --format: table or json
+	DbPath string
+}
+
 type LiveFindTopicsOptions struct {
 	Format string
 	DbPath string
+	Tree   bool // --tree: show the dependency tree of each match
+	Arcs   bool // --arcs: show the dependency arcs of each match
 }
 
 type LiveLsTopicOptions struct {
@@ -51,7 +176,11 @@ type LiveLsOptions struct {
 
 type LiveShowSentOptions struct {
 	DbPath string
-	Stats  bool // -s/--stats: show sentence statistics
+	Stats  bool   // -s/--stats: show sentence statistics
+	Format string // --format: statistics as table or json
+	Tree   bool   // --tree: show the dependency tree
+	Arcs   bool   // --arcs: show the dependency arcs over the sentence
+	Dot    bool   // --dot: write the dependency tree as Graphviz DOT
 }
 
 type LiveInitOptions struct {
@@ -83,6 +212,9 @@ func parseLiveShowArgs(args []string, ui UI) (ShowOptions, string, error) {
 	fs.BoolVar(&opts.Stats, "stats", false, "")
 	fs.BoolVar(&opts.Stats, "s", false, "")
 
+	opts.Format = "table"
+	fs.Var(&enumFlag{allowed: []string{"table", "json"}, value: &opts.Format}, "format", "")
+
 	var countOpt optionalInt
 	fs.Var(&countOpt, "number", "")
 	fs.Var(&countOpt, "n", "")
@@ -99,6 +231,7 @@ func parseLiveShowArgs(args []string, ui UI) (ShowOptions, string, error) {
 		printOpt(w, "--start", "INDEX", "Index of the first sentence to show (default: 0)")
 		printOpt(w, "-n, --number", "N", "Number of sentences to show")
 		printOpt(w, "-s, --stats", "", "Show document statistics")
+		printOpt(w, "--format", "FORMAT", "Statistics format: table or json (default: table)")
 		printOpt(w, "--db", "FILE", "Path to docs directory or SQLite file (or SEGROB_LIVE_DB)")
 	}
 
@@ -184,6 +317,13 @@ func parseLiveShowSentArgs(args []string, ui UI) (LiveShowSentOptions, string, i
 	fs.BoolVar(&opts.Stats, "stats", false, "")
 	fs.BoolVar(&opts.Stats, "s", false, "")
 
+	opts.Format = "table"
+	fs.Var(&enumFlag{allowed: []string{"table", "json"}, value: &opts.Format}, "format", "")
+
+	fs.BoolVar(&opts.Tree, "tree", false, "")
+	fs.BoolVar(&opts.Arcs, "arcs", false, "")
+	fs.BoolVar(&opts.Dot, "dot", false, "")
+
 	fs.Usage = func() {
 		w := fs.Output()
 		fprintUsage(w, fs, showSentSynopsis)
@@ -193,6 +333,10 @@ func parseLiveShowSentArgs(args []string, ui UI) (LiveShowSentOptions, string, i
 		_, _ = fmt.Fprintf(w, helpArgFmt, "sentence_id", "Index of the sentence")
 		_, _ = fmt.Fprintf(w, "\nOptions:\n")
 		printOpt(w, "-s, --stats", "", "Show sentence statistics")
+		printOpt(w, "--format", "FORMAT", "Statistics format: table or json (default: table)")
+		printOpt(w, "--tree", "", "Show the dependency tree as indented branches")
+		printOpt(w, "--arcs", "", "Show the dependencies as arcs over the sentence")
+		printOpt(w, "--dot", "", "Write the dependency tree as Graphviz DOT")
 		printOpt(w, "--db", "PATH", "Path to SQLite file (or SEGROB_LIVE_DB)")
 	}
 
@@ -211,6 +355,11 @@ func parseLiveShowSentArgs(args []string, ui UI) (LiveShowSentOptions, string, i
 		return opts, "", 0, errors.New("document source must be specified via --db or SEGROB_LIVE_DB")
 	}
 
+	if countTrue(opts.Stats, opts.Tree, opts.Arcs, opts.Dot) > 1 {
+		fprintUsageError(ui.Err, fs, showSentSynopsis)
+		return opts, "", 0, errors.New("--stats, --tree, --arcs and --dot are mutually exclusive")
+	}
+
 	if fs.NArg() != 2 {
 		fprintUsageError(ui.Err, fs, showSentSynopsis)
 		return opts, "", 0, errors.New("live show-sent requires exactly two arguments: <doc_id> <sentence_id>")
@@ -241,6 +390,9 @@ func parseLiveFindTopicsArgs(args []string, ui UI) (LiveFindTopicsOptions, strin
 	fs.Var(formatFlag, "format", "")
 	fs.Var(formatFlag, "f", "")
 
+	fs.BoolVar(&opts.Tree, "tree", false, "")
+	fs.BoolVar(&opts.Arcs, "arcs", false, "")
+
 	fs.Usage = func() {
 		w := fs.Output()
 		fprintUsage(w, fs, findTopicsSynopsis)
@@ -251,6 +403,8 @@ func parseLiveFindTopicsArgs(args []string, ui UI) (LiveFindTopicsOptions, strin
 		_, _ = fmt.Fprintf(w, "\nOptions:\n")
 		printOpt(w, "--db", "PATH", "Live SQLite file (or SEGROB_LIVE_DB)")
 		printOpt(w, "-f, --format", "FORMAT", "Output format: all, part, or lemma (default: "+render.Defaultformat+")")
+		printOpt(w, "--tree", "", "Show the dependency tree of each match, matched tokens highlighted")
+		printOpt(w, "--arcs", "", "Show the dependency arcs of each match, matched tokens highlighted")
 	}
 
 	if err := fs.Parse(args); err != nil {
@@ -288,7 +442,7 @@ func parseLiveFindArgs(args []string, ui UI) (LiveFindOptions, []string, bool, e
 	fs := flag.NewFlagSet("live find", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
 
-	const findSynopsis = "[options] <expr>..."
+	const findSynopsis = "[options] <expr>...|--cql QUERY"
 
 	var opts LiveFindOptions
 	labels := (*stringSliceFlag)(&opts.Labels)
@@ -313,21 +467,50 @@ func parseLiveFindArgs(args []string, ui UI) (LiveFindOptions, []string, bool, e
 	fs.StringVar(&opts.DocPath, "d", os.Getenv("SEGROB_LIVE_DB"), "")
 
 	fs.IntVar(&opts.Limit, "limit", 0, "")
+	fs.IntVar(&opts.Budget, "budget", 0, "")
+
+	fs.StringVar(&opts.HTML, "html", "", "")
+
+	fs.StringVar(&opts.CQL, "cql", "", "")
+
+	fs.IntVar(&opts.MinTokens, "min-tokens", 0, "")
+	fs.IntVar(&opts.MaxTokens, "max-tokens", 0, "")
+	fs.IntVar(&opts.MaxClauses, "max-clauses", 0, "")
+	fs.IntVar(&opts.MaxDepth, "max-depth", 0, "")
+	minReadability := &optionalFloat{}
+	fs.Var(minReadability, "min-readability", "")
+	maxReadability := &optionalFloat{}
+	fs.Var(maxReadability, "max-readability", "")
+
+	opts.Sort = "rowid"
+	sortFlag := &enumFlag{allowed: []string{"rowid", "tokens", "clauses", "depth", "readability"}, value: &opts.Sort}
+	fs.Var(sortFlag, "sort", "")
 
 	fs.Usage = func() {
 		w := fs.Output()
 		fprintUsage(w, fs, findSynopsis)
-		_, _ = fmt.Fprintf(w, "  Find sentences matching a topic expression.\n")
+		_, _ = fmt.Fprintf(w, "  Find sentences matching a topic expression or a CQL query.\n")
 		_, _ = fmt.Fprintf(w, "\nArguments:\n")
 		_, _ = fmt.Fprintf(w, helpArgFmt, "expr", "One or more topic expression items")
 		_, _ = fmt.Fprintf(w, "\nOptions:\n")
+		printOpt(w, "--cql", "QUERY", "CQL query, e.g. '[lemma=\"decir\"] []{0,3} [pos=\"NOUN\"]'")
 		printOpt(w, "-d, --doc-path", "PATH", "Path to docs directory or SQLite file (or SEGROB_LIVE_DB)")
 		printOpt(w, "-l, --label", "LABEL", "Only scan documents matching this label (repeatable, all required)")
 		printOpt(w, "-f, --format", "FORMAT", "Output format: all, part, or lemma (default: "+render.Defaultformat+")")
 		printOpt(w, "-n, --nmatches", "N", "Only show sentences with match score greater than N (default: 0)")
 		printOpt(w, "--limit", "N", "Maximum number of results to return (default: 0 = unlimited)")
+		printOpt(w, "--budget", "N", "Maximum number of candidate sentences examined (default: 0 = unlimited)")
+		printOpt(w, "--html", "FILE", "Write the results as an HTML report to FILE")
 		printOpt(w, "-c, --no-color", "", "Disable color formatting in output")
 		printOpt(w, "-x, --no-prefix", "", "Omit metadata prefixes from output")
+		_, _ = fmt.Fprintf(w, "\nComplexity:\n")
+		printOpt(w, "--min-tokens", "N", "Only sentences with at least N tokens")
+		printOpt(w, "--max-tokens", "N", "Only sentences with at most N tokens")
+		printOpt(w, "--max-clauses", "N", "Only sentences with at most N clauses (main and subordinate)")
+		printOpt(w, "--max-depth", "N", "Only sentences with a dependency tree at most N arcs deep")
+		printOpt(w, "--min-readability", "SCORE", "Only sentences with a Fernández-Huerta score of at least SCORE")
+		printOpt(w, "--max-readability", "SCORE", "Only sentences with a Fernández-Huerta score of at most SCORE")
+		printOpt(w, "--sort", "KEY", "Sort by rowid, tokens, clauses, depth or readability, simplest first (default: rowid)")
 	}
 
 	if err := fs.Parse(args); err != nil {
@@ -340,7 +523,14 @@ func parseLiveFindArgs(args []string, ui UI) (LiveFindOptions, []string, bool, e
 		return opts, nil, false, err
 	}
 
-	if fs.NArg() < 1 {
+	opts.MinReadability = minReadability.value
+	opts.MaxReadability = maxReadability.value
+
+	if opts.CQL != "" && fs.NArg() > 0 {
+		fprintUsageError(ui.Err, fs, findSynopsis)
+		return opts, nil, false, errors.New("find command takes an expression or --cql, not both")
+	}
+	if opts.CQL == "" && fs.NArg() < 1 {
 		fprintUsageError(ui.Err, fs, findSynopsis)
 		return opts, nil, false, errors.New("find command needs at least one argument")
 	}
@@ -359,6 +549,191 @@ func parseLiveFindArgs(args []string, ui UI) (LiveFindOptions, []string, bool, e
 	return opts, fs.Args(), !info.IsDir(), nil
 }
 
+func parseLiveReportArgs(args []string, ui UI) (LiveReportOptions, string, error) {
+	fs := flag.NewFlagSet("live report", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	const reportSynopsis = "[options] <topic>"
+
+	var opts LiveReportOptions
+	labels := (*stringSliceFlag)(&opts.Labels)
+	fs.Var(labels, "label", "")
+	fs.Var(labels, "l", "")
+
+	fs.IntVar(&opts.Limit, "limit", 0, "")
+
+	fs.StringVar(&opts.Output, "output", "", "")
+	fs.StringVar(&opts.Output, "o", "", "")
+
+	fs.StringVar(&opts.DbPath, "db", os.Getenv("SEGROB_LIVE_DB"), "")
+
+	fs.Usage = func() {
+		w := fs.Output()
+		fprintUsage(w, fs, reportSynopsis)
+		_, _ = fmt.Fprintf(w, "  Write an HTML report with the sentences matching a topic.\n")
+		_, _ = fmt.Fprintf(w, "  Matches are grouped by document and by topic expression.\n")
+		_, _ = fmt.Fprintf(w, "\nArguments:\n")
+		_, _ = fmt.Fprintf(w, helpArgFmt, "topic", "Name of the topic to report")
+		_, _ = fmt.Fprintf(w, "\nOptions:\n")
+		printOpt(w, "--db", "PATH", "Live SQLite file (or SEGROB_LIVE_DB)")
+		printOpt(w, "-l, --label", "LABEL", "Only scan documents matching this label (repeatable, all required)")
+		printOpt(w, "--limit", "N", "Maximum number of results to return (default: 0 = unlimited)")
+		printOpt(w, "-o, --output", "FILE", "Write the report to FILE instead of stdout")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(ui.Out)
+			fs.Usage()
+			return opts, "", err
+		}
+		fprintUsageError(ui.Err, fs, reportSynopsis)
+		return opts, "", err
+	}
+
+	if opts.DbPath == "" {
+		fprintUsageError(ui.Err, fs, reportSynopsis)
+		return opts, "", errors.New("database must be specified via --db or SEGROB_LIVE_DB")
+	}
+
+	if fs.NArg() != 1 {
+		fprintUsageError(ui.Err, fs, reportSynopsis)
+		return opts, "", errors.New("live report requires exactly one argument: <topic>")
+	}
+
+	return opts, fs.Arg(0), nil
+}
+
+func parseLiveExportClozeArgs(args []string, ui UI) (LiveExportClozeOptions, []string, error) {
+	fs := flag.NewFlagSet("live export-cloze", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	const exportClozeSynopsis = "[options] <topic|expr>..."
+
+	var opts LiveExportClozeOptions
+	labels := (*stringSliceFlag)(&opts.Labels)
+	fs.Var(labels, "label", "")
+	fs.Var(labels, "l", "")
+
+	fs.IntVar(&opts.Limit, "limit", 0, "")
+	fs.IntVar(&opts.Sample, "sample", 0, "")
+	fs.IntVar(&opts.Budget, "budget", 2000, "")
+
+	opts.Format = "tsv"
+	formatFlag := &enumFlag{allowed: []string{"tsv", "csv"}, value: &opts.Format}
+	fs.Var(formatFlag, "format", "")
+	fs.Var(formatFlag, "f", "")
+
+	fs.StringVar(&opts.Output, "output", "", "")
+	fs.StringVar(&opts.Output, "o", "", "")
+
+	fs.StringVar(&opts.DbPath, "db", os.Getenv("SEGROB_LIVE_DB"), "")
+
+	fs.Usage = func() {
+		w := fs.Output()
+		fprintUsage(w, fs, exportClozeSynopsis)
+		_, _ = fmt.Fprintf(w, "  Export cloze flashcards for the sentences matching a topic or expression.\n")
+		_, _ = fmt.Fprintf(w, "  The front is the sentence with the matched words masked, the back the\n")
+		_, _ = fmt.Fprintf(w, "  original sentence with the lemma and tag of the hidden words. Cards are\n")
+		_, _ = fmt.Fprintf(w, "  tagged with the topic name and the document labels.\n")
+		_, _ = fmt.Fprintf(w, "\nArguments:\n")
+		_, _ = fmt.Fprintf(w, helpArgFmt, "topic|expr", "A live topic name, or one or more topic expression items")
+		_, _ = fmt.Fprintf(w, "\nOptions:\n")
+		printOpt(w, "--db", "PATH", "Live SQLite file (or SEGROB_LIVE_DB)")
+		printOpt(w, "-l, --label", "LABEL", "Only scan documents matching this label (repeatable, all required)")
+		printOpt(w, "-f, --format", "FORMAT", "Output format: tsv (Anki) or csv (default: tsv)")
+		printOpt(w, "--limit", "N", "Maximum number of cards (default: 0 = unlimited)")
+		printOpt(w, "--sample", "N", "Pick N diverse sentences with the topic sampler (needs one --label)")
+		printOpt(w, "--budget", "N", "Candidates scanned per expression when sampling (default: 2000)")
+		printOpt(w, "-o, --output", "FILE", "Write the cards to FILE instead of stdout")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(ui.Out)
+			fs.Usage()
+			return opts, nil, err
+		}
+		fprintUsageError(ui.Err, fs, exportClozeSynopsis)
+		return opts, nil, err
+	}
+
+	if opts.DbPath == "" {
+		fprintUsageError(ui.Err, fs, exportClozeSynopsis)
+		return opts, nil, errors.New("database must be specified via --db or SEGROB_LIVE_DB")
+	}
+
+	if fs.NArg() < 1 {
+		fprintUsageError(ui.Err, fs, exportClozeSynopsis)
+		return opts, nil, errors.New("live export-cloze needs at least one argument: <topic|expr>")
+	}
+
+	return opts, fs.Args(), nil
+}
+
+func parseLiveDrillArgs(args []string, ui UI) (LiveDrillOptions, []string, error) {
+	fs := flag.NewFlagSet("live drill", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	const drillSynopsis = "[options] <topic|expr>..."
+
+	var opts LiveDrillOptions
+	labels := (*stringSliceFlag)(&opts.Labels)
+	fs.Var(labels, "label", "")
+	fs.Var(labels, "l", "")
+
+	fs.StringVar(&opts.UserID, "user", "", "")
+	fs.StringVar(&opts.UserID, "u", "", "")
+
+	fs.IntVar(&opts.NewCards, "new", 10, "")
+	fs.IntVar(&opts.Limit, "limit", 1000, "")
+
+	fs.BoolVar(&opts.NoColor, "no-color", false, "")
+	fs.BoolVar(&opts.NoColor, "c", false, "")
+
+	fs.StringVar(&opts.DbPath, "db", os.Getenv("SEGROB_LIVE_DB"), "")
+
+	fs.Usage = func() {
+		w := fs.Output()
+		fprintUsage(w, fs, drillSynopsis)
+		_, _ = fmt.Fprintf(w, "  Interactive grammar drill. Sentences matching the topic are shown with\n")
+		_, _ = fmt.Fprintf(w, "  the matched words masked; type the hidden words (text or lemma, accents\n")
+		_, _ = fmt.Fprintf(w, "  optional). Scores and the spaced-repetition schedule are kept per user in\n")
+		_, _ = fmt.Fprintf(w, "  the live database: due sentences come first, then new ones.\n")
+		_, _ = fmt.Fprintf(w, "\nArguments:\n")
+		_, _ = fmt.Fprintf(w, helpArgFmt, "topic|expr", "A live topic name, or one or more topic expression items")
+		_, _ = fmt.Fprintf(w, "\nOptions:\n")
+		printOpt(w, "--db", "PATH", "Live SQLite file (or SEGROB_LIVE_DB)")
+		printOpt(w, "-u, --user", "ID", "User ID of the drill state (default: \"\")")
+		printOpt(w, "-l, --label", "LABEL", "Only drill documents matching this label (repeatable, all required)")
+		printOpt(w, "--new", "N", "Maximum new sentences per session (default: 10)")
+		printOpt(w, "--limit", "N", "Maximum sentences searched for new ones (default: 1000, 0 = unlimited); due sentences are always drilled")
+		printOpt(w, "-c, --no-color", "", "Disable color output")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(ui.Out)
+			fs.Usage()
+			return opts, nil, err
+		}
+		fprintUsageError(ui.Err, fs, drillSynopsis)
+		return opts, nil, err
+	}
+
+	if opts.DbPath == "" {
+		fprintUsageError(ui.Err, fs, drillSynopsis)
+		return opts, nil, errors.New("database must be specified via --db or SEGROB_LIVE_DB")
+	}
+
+	if fs.NArg() < 1 {
+		fprintUsageError(ui.Err, fs, drillSynopsis)
+		return opts, nil, errors.New("live drill needs at least one argument: <topic|expr>")
+	}
+
+	return opts, fs.Args(), nil
+}
+
 func parseLiveQueryArgs(args []string, ui UI) (LiveQueryOptions, error) {
 	fs := flag.NewFlagSet("live query", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
@@ -384,14 +759,20 @@ func parseLiveQueryArgs(args []string, ui UI) (LiveQueryOptions, error) {
 	fs.Var(formatFlag, "format", "")
 	fs.Var(formatFlag, "f", "")
 
+	fs.StringVar(&opts.UserID, "user", "", "")
+	fs.StringVar(&opts.UserID, "u", "", "")
+
 	fs.StringVar(&opts.DbPath, "db", os.Getenv("SEGROB_LIVE_DB"), "")
 
 	fs.Usage = func() {
 		w := fs.Output()
 		fprintUsage(w, fs, querySynopsis)
-		_, _ = fmt.Fprintf(w, "  Enter interactive query mode.\n")
+		_, _ = fmt.Fprintf(w, "  Enter interactive query mode. The input history is kept per user in the\n")
+		_, _ = fmt.Fprintf(w, "  segrob directory of the user configuration directory. Type :help in the\n")
+		_, _ = fmt.Fprintf(w, "  prompt for the REPL commands (:labels, :limit, :format, :save, :export).\n")
 		_, _ = fmt.Fprintf(w, "\nOptions:\n")
 		printOpt(w, "--db", "PATH", "Live SQLite file (or SEGROB_LIVE_DB)")
+		printOpt(w, "-u, --user", "ID", "User ID of the topics and the history (default: \"\")")
 		printOpt(w, "-l, --label", "LABEL", "Only scan documents matching this label (repeatable, all required)")
 		printOpt(w, "-f, --format", "FORMAT", "Output format: all, part, or lemma (default: "+render.Defaultformat+")")
 		printOpt(w, "-n, --nmatches", "N", "Only show sentences with match score greater than N (default: 0)")
@@ -544,7 +925,8 @@ func parseLiveUnpublishArgs(args []string, ui UI) (LiveUnpublishOptions, error)
 		fprintUsage(w, fs, unpublishSynopsis)
 		_, _ = fmt.Fprintf(w, "  Remove a document from all live tables.\n")
 		_, _ = fmt.Fprintf(w, "  The removal is the reverse of publish: the live switch (lemma index) is\n")
-		_, _ = fmt.Fprintf(w, "  cut first, then labels, sentences, and finally the doc row.\n")
+		_, _ = fmt.Fprintf(w, "  cut first, then the topic index, lemma frequencies, labels, sentences, and\n")
+		_, _ = fmt.Fprintf(w, "  finally the doc row.\n")
 		_, _ = fmt.Fprintf(w, "\nArguments:\n")
 		_, _ = fmt.Fprintf(w, helpArgFmt, "id", "Document ID to unpublish")
 		_, _ = fmt.Fprintf(w, "\nOptions:\n")
@@ -661,3 +1043,683 @@ func parseLiveDumpTopicArgs(args []string, ui UI) (LiveDumpTopicOptions, error)
 
 	return opts, nil
 }
+
+func parseLiveReindexTopicsArgs(args []string, ui UI) (LiveReindexTopicsOptions, []string, error) {
+	fs := flag.NewFlagSet("live reindex-topics", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	const reindexTopicsSynopsis = "[options] [topic...]"
+
+	var opts LiveReindexTopicsOptions
+	fs.BoolVar(&opts.All, "all", false, "")
+	fs.BoolVar(&opts.All, "a", false, "")
+	fs.BoolVar(&opts.Status, "status", false, "")
+	fs.BoolVar(&opts.Status, "s", false, "")
+	fs.StringVar(&opts.DbPath, "db", os.Getenv("SEGROB_LIVE_DB"), "")
+
+	fs.Usage = func() {
+		w := fs.Output()
+		fprintUsage(w, fs, reindexTopicsSynopsis)
+		_, _ = fmt.Fprintf(w, "  Compute the sentence→topic index of the default topics. A topic is\n")
+		_, _ = fmt.Fprintf(w, "  stale when it changed after it was indexed (e.g. by publish-topic).\n")
+		_, _ = fmt.Fprintf(w, "  Without arguments, stale and never indexed topics are reindexed.\n")
+		_, _ = fmt.Fprintf(w, "\nArguments:\n")
+		_, _ = fmt.Fprintf(w, helpArgFmt, "topic", "Reindex only these topics, fresh or not")
+		_, _ = fmt.Fprintf(w, "\nOptions:\n")
+		printOpt(w, "--db", "PATH", "Live SQLite file (or SEGROB_LIVE_DB)")
+		printOpt(w, "-a, --all", "", "Reindex all the default topics")
+		printOpt(w, "-s, --status", "", "Only print the index state of the topics")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(ui.Out)
+			fs.Usage()
+			return opts, nil, err
+		}
+		fprintUsageError(ui.Err, fs, reindexTopicsSynopsis)
+		return opts, nil, err
+	}
+
+	if opts.DbPath == "" {
+		fprintUsageError(ui.Err, fs, reindexTopicsSynopsis)
+		return opts, nil, errors.New("database must be specified via --db or SEGROB_LIVE_DB")
+	}
+
+	if opts.All && fs.NArg() > 0 {
+		fprintUsageError(ui.Err, fs, reindexTopicsSynopsis)
+		return opts, nil, errors.New("--all and topic arguments are mutually exclusive")
+	}
+
+	return opts, fs.Args(), nil
+}
+
+func parseLiveTopicStatsArgs(args []string, ui UI) (LiveTopicStatsOptions, []string, error) {
+	fs := flag.NewFlagSet("live topic-stats", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	const topicStatsSynopsis = "[options] [topic...]"
+
+	var opts LiveTopicStatsOptions
+	fs.StringVar(&opts.By, "by", "", "")
+	fs.StringVar(&opts.By, "b", "", "")
+
+	opts.Format = "table"
+	formatFlag := &enumFlag{allowed: []string{"table", "csv", "json"}, value: &opts.Format}
+	fs.Var(formatFlag, "format", "")
+	fs.Var(formatFlag, "f", "")
+
+	fs.StringVar(&opts.DbPath, "db", os.Getenv("SEGROB_LIVE_DB"), "")
+
+	fs.Usage = func() {
+		w := fs.Output()
+		fprintUsage(w, fs, topicStatsSynopsis)
+		_, _ = fmt.Fprintf(w, "  Count the sentences matching each topic per document, or per label value\n")
+		_, _ = fmt.Fprintf(w, "  with --by. Each cell holds the raw count and the matches per 10k\n")
+		_, _ = fmt.Fprintf(w, "  sentences of the row. Fresh topics are read from the topic index.\n")
+		_, _ = fmt.Fprintf(w, "\nArguments:\n")
+		_, _ = fmt.Fprintf(w, helpArgFmt, "topic", "Live topic names (default: all the default topics)")
+		_, _ = fmt.Fprintf(w, "\nOptions:\n")
+		printOpt(w, "--db", "PATH", "Live SQLite file (or SEGROB_LIVE_DB)")
+		printOpt(w, "-b, --by", "PREFIX", "Group the documents by label value, e.g. creator or date:*")
+		printOpt(w, "-f, --format", "FORMAT", "Output format: table, csv or json (default: table)")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(ui.Out)
+			fs.Usage()
+			return opts, nil, err
+		}
+		fprintUsageError(ui.Err, fs, topicStatsSynopsis)
+		return opts, nil, err
+	}
+
+	if opts.DbPath == "" {
+		fprintUsageError(ui.Err, fs, topicStatsSynopsis)
+		return opts, nil, errors.New("database must be specified via --db or SEGROB_LIVE_DB")
+	}
+
+	// Accept creator, creator: and creator:*
+	opts.By = strings.TrimSuffix(strings.TrimSuffix(opts.By, "*"), ":")
+
+	return opts, fs.Args(), nil
+}
+
+func parseLiveTimelineArgs(args []string, ui UI) (LiveTimelineOptions, []string, error) {
+	fs := flag.NewFlagSet("live timeline", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	const timelineSynopsis = "[options] <topic|expr>..."
+
+	var opts LiveTimelineOptions
+	opts.Bucket = "decade"
+	bucketFlag := &enumFlag{allowed: []string{"decade", "year"}, value: &opts.Bucket}
+	fs.Var(bucketFlag, "bucket", "")
+	fs.Var(bucketFlag, "b", "")
+
+	opts.Format = "chart"
+	formatFlag := &enumFlag{allowed: []string{"chart", "csv"}, value: &opts.Format}
+	fs.Var(formatFlag, "format", "")
+	fs.Var(formatFlag, "f", "")
+
+	fs.StringVar(&opts.DbPath, "db", os.Getenv("SEGROB_LIVE_DB"), "")
+
+	fs.Usage = func() {
+		w := fs.Output()
+		fprintUsage(w, fs, timelineSynopsis)
+		_, _ = fmt.Fprintf(w, "  Show the frequency of a topic or expression over time. The documents are\n")
+		_, _ = fmt.Fprintf(w, "  bucketed by their date label and each bucket shows the matching sentences\n")
+		_, _ = fmt.Fprintf(w, "  per 10k sentences. Documents without a date label are skipped.\n")
+		_, _ = fmt.Fprintf(w, "\nArguments:\n")
+		_, _ = fmt.Fprintf(w, helpArgFmt, "topic|expr", "A live topic name, or one or more topic expression items")
+		_, _ = fmt.Fprintf(w, "\nOptions:\n")
+		printOpt(w, "--db", "PATH", "Live SQLite file (or SEGROB_LIVE_DB)")
+		printOpt(w, "-b, --bucket", "BUCKET", "Bucket size: decade or year (default: decade)")
+		printOpt(w, "-f, --format", "FORMAT", "Output format: chart or csv (default: chart)")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(ui.Out)
+			fs.Usage()
+			return opts, nil, err
+		}
+		fprintUsageError(ui.Err, fs, timelineSynopsis)
+		return opts, nil, err
+	}
+
+	if opts.DbPath == "" {
+		fprintUsageError(ui.Err, fs, timelineSynopsis)
+		return opts, nil, errors.New("database must be specified via --db or SEGROB_LIVE_DB")
+	}
+
+	if fs.NArg() < 1 {
+		fprintUsageError(ui.Err, fs, timelineSynopsis)
+		return opts, nil, errors.New("live timeline needs at least one argument: <topic|expr>")
+	}
+
+	return opts, fs.Args(), nil
+}
+
+func parseLiveDispersionArgs(args []string, ui UI) (LiveDispersionOptions, string, []string, error) {
+	fs := flag.NewFlagSet("live dispersion", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	const dispersionSynopsis = "[options] <doc_id> <topic|expr>..."
+
+	var opts LiveDispersionOptions
+	fs.IntVar(&opts.Parts, "parts", 10, "")
+	fs.IntVar(&opts.Parts, "p", 10, "")
+	fs.IntVar(&opts.Width, "width", 60, "")
+	fs.IntVar(&opts.Width, "w", 60, "")
+	fs.StringVar(&opts.DbPath, "db", os.Getenv("SEGROB_LIVE_DB"), "")
+
+	fs.Usage = func() {
+		w := fs.Output()
+		fprintUsage(w, fs, dispersionSynopsis)
+		_, _ = fmt.Fprintf(w, "  Show where in a document the sentences matching a topic or expression\n")
+		_, _ = fmt.Fprintf(w, "  occur, as a strip plot with a row per expression. Juilland's D and Gries'\n")
+		_, _ = fmt.Fprintf(w, "  DP are computed over the document divided into equal parts.\n")
+		_, _ = fmt.Fprintf(w, "\nArguments:\n")
+		_, _ = fmt.Fprintf(w, helpArgFmt, "doc_id", "Document ID")
+		_, _ = fmt.Fprintf(w, helpArgFmt, "topic|expr", "A live topic name, or one or more topic expression items")
+		_, _ = fmt.Fprintf(w, "\nOptions:\n")
+		printOpt(w, "--db", "PATH", "Live SQLite file (or SEGROB_LIVE_DB)")
+		printOpt(w, "-p, --parts", "N", "Parts of the document for the statistics (default: 10)")
+		printOpt(w, "-w, --width", "N", "Columns of the strip plot (default: 60)")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(ui.Out)
+			fs.Usage()
+			return opts, "", nil, err
+		}
+		fprintUsageError(ui.Err, fs, dispersionSynopsis)
+		return opts, "", nil, err
+	}
+
+	if opts.DbPath == "" {
+		fprintUsageError(ui.Err, fs, dispersionSynopsis)
+		return opts, "", nil, errors.New("database must be specified via --db or SEGROB_LIVE_DB")
+	}
+
+	if fs.NArg() < 2 {
+		fprintUsageError(ui.Err, fs, dispersionSynopsis)
+		return opts, "", nil, errors.New("live dispersion needs two arguments: <doc_id> <topic|expr>")
+	}
+
+	if opts.Parts < 2 || opts.Width < 1 {
+		fprintUsageError(ui.Err, fs, dispersionSynopsis)
+		return opts, "", nil, errors.New("--parts must be at least 2 and --width at least 1")
+	}
+
+	return opts, fs.Arg(0), fs.Args()[1:], nil
+}
+
+func parseLiveCollocatesArgs(args []string, ui UI) (LiveCollocatesOptions, string, error) {
+	fs := flag.NewFlagSet("live collocates", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	const collocatesSynopsis = "[options] <lemma>"
+
+	var opts LiveCollocatesOptions
+	labels := (*stringSliceFlag)(&opts.Labels)
+	fs.Var(labels, "label", "")
+	fs.Var(labels, "l", "")
+
+	pos := (*stringSliceFlag)(&opts.Pos)
+	fs.Var(pos, "pos", "")
+	fs.Var(pos, "p", "")
+
+	fs.IntVar(&opts.Window, "window", 5, "")
+	fs.IntVar(&opts.Window, "w", 5, "")
+	fs.IntVar(&opts.Min, "min", 3, "")
+	fs.IntVar(&opts.Top, "top", 30, "")
+	fs.IntVar(&opts.Top, "n", 30, "")
+
+	opts.Sort = "ll"
+	sortFlag := &enumFlag{allowed: []string{"ll", "pmi", "t"}, value: &opts.Sort}
+	fs.Var(sortFlag, "sort", "")
+	fs.Var(sortFlag, "s", "")
+
+	opts.Format = "table"
+	formatFlag := &enumFlag{allowed: []string{"table", "csv"}, value: &opts.Format}
+	fs.Var(formatFlag, "format", "")
+	fs.Var(formatFlag, "f", "")
+
+	fs.StringVar(&opts.DbPath, "db", os.Getenv("SEGROB_LIVE_DB"), "")
+
+	fs.Usage = func() {
+		w := fs.Output()
+		fprintUsage(w, fs, collocatesSynopsis)
+		_, _ = fmt.Fprintf(w, "  Rank the collocates of a lemma. The sentences containing the lemma are\n")
+		_, _ = fmt.Fprintf(w, "  scanned through the lemma index; a collocate is a lemma within the window\n")
+		_, _ = fmt.Fprintf(w, "  of the node. Counts are sentences: the association scores (log-likelihood,\n")
+		_, _ = fmt.Fprintf(w, "  PMI, t-score) compare them with the sentence frequency of each lemma.\n")
+		_, _ = fmt.Fprintf(w, "\nArguments:\n")
+		_, _ = fmt.Fprintf(w, helpArgFmt, "lemma", "The node lemma")
+		_, _ = fmt.Fprintf(w, "\nOptions:\n")
+		printOpt(w, "--db", "PATH", "Live SQLite file (or SEGROB_LIVE_DB)")
+		printOpt(w, "-l, --label", "LABEL", "Only scan documents matching this label (repeatable, all required)")
+		printOpt(w, "-w, --window", "N", "Tokens on each side of the node (default: 5)")
+		printOpt(w, "-p, --pos", "POS", "Only collocates with this POS, e.g. NOUN (repeatable)")
+		printOpt(w, "--min", "N", "Minimum co-occurrences of a collocate (default: 3)")
+		printOpt(w, "-n, --top", "N", "Number of collocates (default: 30)")
+		printOpt(w, "-s, --sort", "SCORE", "Rank by ll, pmi or t (default: ll)")
+		printOpt(w, "-f, --format", "FORMAT", "Output format: table or csv (default: table)")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(ui.Out)
+			fs.Usage()
+			return opts, "", err
+		}
+		fprintUsageError(ui.Err, fs, collocatesSynopsis)
+		return opts, "", err
+	}
+
+	if opts.DbPath == "" {
+		fprintUsageError(ui.Err, fs, collocatesSynopsis)
+		return opts, "", errors.New("database must be specified via --db or SEGROB_LIVE_DB")
+	}
+
+	if fs.NArg() != 1 {
+		fprintUsageError(ui.Err, fs, collocatesSynopsis)
+		return opts, "", errors.New("live collocates needs exactly one argument: <lemma>")
+	}
+
+	if opts.Window < 1 || opts.Top < 1 {
+		fprintUsageError(ui.Err, fs, collocatesSynopsis)
+		return opts, "", errors.New("--window and --top must be at least 1")
+	}
+
+	return opts, fs.Arg(0), nil
+}
+
+func parseLiveKeynessArgs(args []string, ui UI) (LiveKeynessOptions, error) {
+	fs := flag.NewFlagSet("live keyness", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	const keynessSynopsis = "[options] --target LABEL --reference LABEL"
+
+	var opts LiveKeynessOptions
+	target := (*stringSliceFlag)(&opts.Target)
+	fs.Var(target, "target", "")
+	fs.Var(target, "t", "")
+
+	reference := (*stringSliceFlag)(&opts.Reference)
+	fs.Var(reference, "reference", "")
+	fs.Var(reference, "r", "")
+
+	opts.By = "lemma"
+	byFlag := &enumFlag{allowed: []string{"lemma", "pos", "feature"}, value: &opts.By}
+	fs.Var(byFlag, "by", "")
+	fs.Var(byFlag, "b", "")
+
+	fs.IntVar(&opts.Min, "min", 5, "")
+	fs.IntVar(&opts.Top, "top", 20, "")
+	fs.IntVar(&opts.Top, "n", 20, "")
+
+	opts.Format = "table"
+	formatFlag := &enumFlag{allowed: []string{"table", "csv"}, value: &opts.Format}
+	fs.Var(formatFlag, "format", "")
+	fs.Var(formatFlag, "f", "")
+
+	fs.StringVar(&opts.DbPath, "db", os.Getenv("SEGROB_LIVE_DB"), "")
+
+	fs.Usage = func() {
+		w := fs.Output()
+		fprintUsage(w, fs, keynessSynopsis)
+		_, _ = fmt.Fprintf(w, "  Compare the frequencies of two label-defined subcorpora and list the\n")
+		_, _ = fmt.Fprintf(w, "  keywords of each, ranked by log-likelihood, with the log-ratio effect\n")
+		_, _ = fmt.Fprintf(w, "  size. Lemmas are counted per sentence from the lemma index; POS and\n")
+		_, _ = fmt.Fprintf(w, "  morphological features are counted per token by scanning the sentences.\n")
+		_, _ = fmt.Fprintf(w, "\nOptions:\n")
+		printOpt(w, "--db", "PATH", "Live SQLite file (or SEGROB_LIVE_DB)")
+		printOpt(w, "-t, --target", "LABEL", "Label of the target subcorpus (repeatable, all required)")
+		printOpt(w, "-r, --reference", "LABEL", "Label of the reference subcorpus (repeatable, all required)")
+		printOpt(w, "-b, --by", "ITEM", "Compare lemma, pos or feature (default: lemma)")
+		printOpt(w, "--min", "N", "Minimum frequency in both subcorpora together (default: 5)")
+		printOpt(w, "-n, --top", "N", "Keywords listed for each subcorpus (default: 20)")
+		printOpt(w, "-f, --format", "FORMAT", "Output format: table or csv (default: table)")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(ui.Out)
+			fs.Usage()
+			return opts, err
+		}
+		fprintUsageError(ui.Err, fs, keynessSynopsis)
+		return opts, err
+	}
+
+	if opts.DbPath == "" {
+		fprintUsageError(ui.Err, fs, keynessSynopsis)
+		return opts, errors.New("database must be specified via --db or SEGROB_LIVE_DB")
+	}
+
+	if len(opts.Target) == 0 || len(opts.Reference) == 0 {
+		fprintUsageError(ui.Err, fs, keynessSynopsis)
+		return opts, errors.New("live keyness needs a --target and a --reference label")
+	}
+
+	if fs.NArg() > 0 {
+		fprintUsageError(ui.Err, fs, keynessSynopsis)
+		return opts, fmt.Errorf("unexpected argument: %s", fs.Arg(0))
+	}
+
+	return opts, nil
+}
+
+func parseLiveLemmasArgs(args []string, ui UI) (LiveLemmasOptions, string, error) {
+	fs := flag.NewFlagSet("live lemmas", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	const lemmasSynopsis = "[options] [prefix]"
+
+	var opts LiveLemmasOptions
+	labels := (*stringSliceFlag)(&opts.Labels)
+	fs.Var(labels, "label", "")
+	fs.Var(labels, "l", "")
+
+	fs.IntVar(&opts.Top, "top", 50, "")
+	fs.IntVar(&opts.Top, "n", 50, "")
+
+	fs.BoolVar(&opts.Rebuild, "rebuild", false, "")
+
+	fs.StringVar(&opts.DbPath, "db", os.Getenv("SEGROB_LIVE_DB"), "")
+
+	fs.Usage = func() {
+		w := fs.Output()
+		fprintUsage(w, fs, lemmasSynopsis)
+		_, _ = fmt.Fprintf(w, "  List the lemmas of the live corpus, most frequent first, with their\n")
+		_, _ = fmt.Fprintf(w, "  occurrences and the number of sentences and documents containing them.\n")
+		_, _ = fmt.Fprintf(w, "\nArguments:\n")
+		_, _ = fmt.Fprintf(w, helpArgFmt, "prefix", "Only lemmas starting with prefix")
+		_, _ = fmt.Fprintf(w, "\nOptions:\n")
+		printOpt(w, "--db", "PATH", "Live SQLite file (or SEGROB_LIVE_DB)")
+		printOpt(w, "-l, --label", "LABEL", "Only count documents matching this label (repeatable, all required)")
+		printOpt(w, "-n, --top", "N", "Number of lemmas (default: 50, 0 = all)")
+		printOpt(w, "--rebuild", "", "Compute the frequencies of the published documents that have none")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(ui.Out)
+			fs.Usage()
+			return opts, "", err
+		}
+		fprintUsageError(ui.Err, fs, lemmasSynopsis)
+		return opts, "", err
+	}
+
+	if opts.DbPath == "" {
+		fprintUsageError(ui.Err, fs, lemmasSynopsis)
+		return opts, "", errors.New("database must be specified via --db or SEGROB_LIVE_DB")
+	}
+
+	if fs.NArg() > 1 {
+		fprintUsageError(ui.Err, fs, lemmasSynopsis)
+		return opts, "", errors.New("live lemmas takes at most one argument: [prefix]")
+	}
+
+	if opts.Top < 0 {
+		fprintUsageError(ui.Err, fs, lemmasSynopsis)
+		return opts, "", errors.New("--top must not be negative")
+	}
+
+	if opts.Rebuild && (fs.NArg() > 0 || len(opts.Labels) > 0) {
+		fprintUsageError(ui.Err, fs, lemmasSynopsis)
+		return opts, "", errors.New("--rebuild takes no prefix or labels")
+	}
+
+	return opts, fs.Arg(0), nil
+}
+
+func parseLiveStatsArgs(args []string, ui UI) (LiveStatsOptions, error) {
+	fs := flag.NewFlagSet("live stats", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	const statsSynopsis = "[options]"
+
+	var opts LiveStatsOptions
+	labels := (*stringSliceFlag)(&opts.Labels)
+	fs.Var(labels, "label", "")
+	fs.Var(labels, "l", "")
+
+	opts.Format = "table"
+	formatFlag := &enumFlag{allowed: []string{"table", "json"}, value: &opts.Format}
+	fs.Var(formatFlag, "format", "")
+	fs.Var(formatFlag, "f", "")
+
+	fs.StringVar(&opts.DbPath, "db", os.Getenv("SEGROB_LIVE_DB"), "")
+
+	fs.Usage = func() {
+		w := fs.Output()
+		fprintUsage(w, fs, statsSynopsis)
+		_, _ = fmt.Fprintf(w, "  Show the statistics of the live corpus, or of the subcorpus of the\n")
+		_, _ = fmt.Fprintf(w, "  documents matching the labels: sentence length, part of speech, type/token\n")
+		_, _ = fmt.Fprintf(w, "  ratio and MATTR, top lemmas, dependency tree depth and dialogue ratio.\n")
+		_, _ = fmt.Fprintf(w, "\nOptions:\n")
+		printOpt(w, "--db", "PATH", "Live SQLite file (or SEGROB_LIVE_DB)")
+		printOpt(w, "-l, --label", "LABEL", "Only count documents matching this label (repeatable, all required)")
+		printOpt(w, "-f, --format", "FORMAT", "Output format: table or json (default: table)")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(ui.Out)
+			fs.Usage()
+			return opts, err
+		}
+		fprintUsageError(ui.Err, fs, statsSynopsis)
+		return opts, err
+	}
+
+	if opts.DbPath == "" {
+		fprintUsageError(ui.Err, fs, statsSynopsis)
+		return opts, errors.New("database must be specified via --db or SEGROB_LIVE_DB")
+	}
+
+	if fs.NArg() > 0 {
+		fprintUsageError(ui.Err, fs, statsSynopsis)
+		return opts, errors.New("live stats takes no arguments")
+	}
+
+	return opts, nil
+}
+
+func parseLiveParadigmArgs(args []string, ui UI) (LiveParadigmOptions, string, error) {
+	fs := flag.NewFlagSet("live paradigm", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	const paradigmSynopsis = "[options] <lemma>"
+
+	var opts LiveParadigmOptions
+	labels := (*stringSliceFlag)(&opts.Labels)
+	fs.Var(labels, "label", "")
+	fs.Var(labels, "l", "")
+
+	fs.StringVar(&opts.DbPath, "db", os.Getenv("SEGROB_LIVE_DB"), "")
+
+	fs.Usage = func() {
+		w := fs.Output()
+		fprintUsage(w, fs, paradigmSynopsis)
+		_, _ = fmt.Fprintf(w, "  Show the surface forms of a lemma grouped by their morphological features,\n")
+		_, _ = fmt.Fprintf(w, "  with their occurrences and the first sentence (doc_id sentence_id) of each.\n")
+		_, _ = fmt.Fprintf(w, "  The finite forms of verbs are also shown as a conjugation table.\n")
+		_, _ = fmt.Fprintf(w, "\nArguments:\n")
+		_, _ = fmt.Fprintf(w, helpArgFmt, "lemma", "The lemma, e.g. decir")
+		_, _ = fmt.Fprintf(w, "\nOptions:\n")
+		printOpt(w, "--db", "PATH", "Live SQLite file (or SEGROB_LIVE_DB)")
+		printOpt(w, "-l, --label", "LABEL", "Only scan documents matching this label (repeatable, all required)")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(ui.Out)
+			fs.Usage()
+			return opts, "", err
+		}
+		fprintUsageError(ui.Err, fs, paradigmSynopsis)
+		return opts, "", err
+	}
+
+	if opts.DbPath == "" {
+		fprintUsageError(ui.Err, fs, paradigmSynopsis)
+		return opts, "", errors.New("database must be specified via --db or SEGROB_LIVE_DB")
+	}
+
+	if fs.NArg() != 1 {
+		fprintUsageError(ui.Err, fs, paradigmSynopsis)
+		return opts, "", errors.New("live paradigm requires exactly one argument: <lemma>")
+	}
+
+	return opts, fs.Arg(0), nil
+}
+
+func parseLiveGradedArgs(args []string, ui UI) (LiveGradedOptions, []string, error) {
+	fs := flag.NewFlagSet("live graded", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	const gradedSynopsis = "[options] --known FILE [topic|expr...]"
+
+	var opts LiveGradedOptions
+	fs.StringVar(&opts.Known, "known", "", "")
+	fs.StringVar(&opts.Known, "k", "", "")
+	fs.IntVar(&opts.MaxUnknown, "max-unknown", 0, "")
+	fs.IntVar(&opts.MaxUnknown, "u", 0, "")
+	fs.IntVar(&opts.MinWords, "min-words", 4, "")
+	fs.StringVar(&opts.UserID, "user", "", "")
+
+	labels := (*stringSliceFlag)(&opts.Labels)
+	fs.Var(labels, "label", "")
+	fs.Var(labels, "l", "")
+
+	fs.IntVar(&opts.Limit, "limit", 20, "")
+	fs.IntVar(&opts.Limit, "n", 20, "")
+	fs.BoolVar(&opts.NoColor, "no-color", false, "")
+	fs.BoolVar(&opts.NoColor, "c", false, "")
+	fs.BoolVar(&opts.NoPrefix, "no-prefix", false, "")
+	fs.BoolVar(&opts.NoPrefix, "x", false, "")
+
+	fs.StringVar(&opts.DbPath, "db", os.Getenv("SEGROB_LIVE_DB"), "")
+
+	fs.Usage = func() {
+		w := fs.Output()
+		fprintUsage(w, fs, gradedSynopsis)
+		_, _ = fmt.Fprintf(w, "  Find sentences for graded reading: all their content lemmas (nouns, verbs,\n")
+		_, _ = fmt.Fprintf(w, "  adjectives, adverbs) are in the known list, but at most --max-unknown.\n")
+		_, _ = fmt.Fprintf(w, "  Sentences with fewer unknown lemmas come first, then the shorter ones, then\n")
+		_, _ = fmt.Fprintf(w, "  those with more frequent lemmas. Unknown lemmas are shown and highlighted.\n")
+		_, _ = fmt.Fprintf(w, "\nArguments:\n")
+		_, _ = fmt.Fprintf(w, helpArgFmt, "topic|expr", "Only sentences matching a live topic or expression")
+		_, _ = fmt.Fprintf(w, "\nOptions:\n")
+		printOpt(w, "--db", "PATH", "Live SQLite file (or SEGROB_LIVE_DB)")
+		printOpt(w, "-k, --known", "FILE", "Known lemmas, one per line (# comments)")
+		printOpt(w, "-u, --max-unknown", "N", "Unknown content lemmas allowed (default: 0)")
+		printOpt(w, "--min-words", "N", "Skip sentences shorter than N words (default: 4)")
+		printOpt(w, "--user", "ID", "User ID of the topic (default: \"\")")
+		printOpt(w, "-l, --label", "LABEL", "Only scan documents matching this label (repeatable, all required)")
+		printOpt(w, "-n, --limit", "N", "Number of sentences (default: 20)")
+		printOpt(w, "-c, --no-color", "", "Disable color output")
+		printOpt(w, "-x, --no-prefix", "", "Hide the document prefix")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(ui.Out)
+			fs.Usage()
+			return opts, nil, err
+		}
+		fprintUsageError(ui.Err, fs, gradedSynopsis)
+		return opts, nil, err
+	}
+
+	if opts.DbPath == "" {
+		fprintUsageError(ui.Err, fs, gradedSynopsis)
+		return opts, nil, errors.New("database must be specified via --db or SEGROB_LIVE_DB")
+	}
+
+	if opts.Known == "" {
+		fprintUsageError(ui.Err, fs, gradedSynopsis)
+		return opts, nil, errors.New("the known lemmas must be specified via --known")
+	}
+
+	if opts.MaxUnknown < 0 || opts.Limit < 1 {
+		fprintUsageError(ui.Err, fs, gradedSynopsis)
+		return opts, nil, errors.New("--max-unknown must not be negative and --limit must be at least 1")
+	}
+
+	return opts, fs.Args(), nil
+}
+
+func parseLiveExportArgs(args []string, ui UI) (LiveExportOptions, []string, error) {
+	fs := flag.NewFlagSet("live export", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	const exportSynopsis = "[options] <doc_id...|--label LABEL|--all>"
+
+	var opts LiveExportOptions
+	labels := (*stringSliceFlag)(&opts.Labels)
+	fs.Var(labels, "label", "")
+	fs.Var(labels, "l", "")
+
+	fs.BoolVar(&opts.All, "all", false, "")
+	fs.BoolVar(&opts.All, "a", false, "")
+
+	opts.Format = "conllu"
+	formatFlag := &enumFlag{allowed: []string{"conllu", "vert"}, value: &opts.Format}
+	fs.Var(formatFlag, "format", "")
+	fs.Var(formatFlag, "f", "")
+
+	fs.StringVar(&opts.Output, "output", "", "")
+	fs.StringVar(&opts.Output, "o", "", "")
+
+	fs.StringVar(&opts.DbPath, "db", os.Getenv("SEGROB_LIVE_DB"), "")
+
+	fs.Usage = func() {
+		w := fs.Output()
+		fprintUsage(w, fs, exportSynopsis)
+		_, _ = fmt.Fprintf(w, "  Export the sentences of live documents, of the documents matching the\n")
+		_, _ = fmt.Fprintf(w, "  labels or of all the documents, for external tools. CoNLL-U carries the doc\n")
+		_, _ = fmt.Fprintf(w, "  and sentence ids in the newdoc and sent_id comments. The vertical format of\n")
+		_, _ = fmt.Fprintf(w, "  CWB/CQP and NoSketchEngine has <doc> structures with the labels as\n")
+		_, _ = fmt.Fprintf(w, "  attributes, <s> structures with the sentence ids, and word, lemma, POS, tag\n")
+		_, _ = fmt.Fprintf(w, "  and dep columns.\n")
+		_, _ = fmt.Fprintf(w, "\nArguments:\n")
+		_, _ = fmt.Fprintf(w, helpArgFmt, "doc_id", "Document ID (repeatable)")
+		_, _ = fmt.Fprintf(w, "\nOptions:\n")
+		printOpt(w, "--db", "PATH", "Live SQLite file (or SEGROB_LIVE_DB)")
+		printOpt(w, "-l, --label", "LABEL", "Export the documents matching this label (repeatable, all required)")
+		printOpt(w, "-a, --all", "", "Export all the documents")
+		printOpt(w, "-f, --format", "FORMAT", "Output format: conllu, vert (default: conllu)")
+		printOpt(w, "-o, --output", "FILE", "Write output to FILE instead of stdout")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(ui.Out)
+			fs.Usage()
+			return opts, nil, err
+		}
+		fprintUsageError(ui.Err, fs, exportSynopsis)
+		return opts, nil, err
+	}
+
+	if opts.DbPath == "" {
+		fprintUsageError(ui.Err, fs, exportSynopsis)
+		return opts, nil, errors.New("database must be specified via --db or SEGROB_LIVE_DB")
+	}
+
+	if countTrue(fs.NArg() > 0, len(opts.Labels) > 0, opts.All) != 1 {
+		fprintUsageError(ui.Err, fs, exportSynopsis)
+		return opts, nil, errors.New("live export needs either document ids, --label or --all")
+	}
+
+	return opts, fs.Args(), nil
+}
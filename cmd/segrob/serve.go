@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/revelaction/segrob/server"
+	"github.com/revelaction/segrob/storage"
+)
+
+// shutdownTimeout is the time given to running requests to finish after
+// SIGINT or SIGTERM.
+const shutdownTimeout = 10 * time.Second
+
+func serveCommand(ctx context.Context, docRepo storage.DocReader, topicRepo storage.TopicRepository, opts ServeOptions, ui UI) error {
+	srv := &http.Server{
+		Addr:              opts.Addr,
+		Handler:           server.New(docRepo, topicRepo),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.ListenAndServe()
+	}()
+
+	_, _ = fmt.Fprintf(ui.Err, "Serving %s on %s\n", opts.DbPath, opts.Addr)
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"path/filepath"
 
 	"github.com/revelaction/segrob/storage"
@@ -9,12 +11,14 @@ import (
 )
 
 type Setup struct {
-	pools map[string]*sqlitex.Pool
+	pools    map[string]*sqlitex.Pool
+	upgraded map[*sqlitex.Pool]bool
 }
 
 func NewSetup() *Setup {
 	return &Setup{
-		pools: make(map[string]*sqlitex.Pool),
+		pools:    make(map[string]*sqlitex.Pool),
+		upgraded: make(map[*sqlitex.Pool]bool),
 	}
 }
 
@@ -48,11 +52,29 @@ func (s *Setup) getPool(path string, params ...string) (*sqlitex.Pool, error) {
 	return pool, nil
 }
 
-func (s *Setup) NewDocRepository(path string, params ...string) (storage.DocRepository, error) {
+// getLivePool returns the pool of a live database like getPool, upgrading
+// the schema of a database initialized by an earlier version the first time.
+func (s *Setup) getLivePool(path string, params ...string) (*sqlitex.Pool, error) {
 	pool, err := s.getPool(path, params...)
 	if err != nil {
 		return nil, err
 	}
+
+	if !s.upgraded[pool] {
+		if err := zombiezen.NewSchemaManager(pool).Upgrade(context.Background()); err != nil {
+			return nil, fmt.Errorf("failed to upgrade the schema of %s (run 'segrob live init %s'): %w", path, path, err)
+		}
+		s.upgraded[pool] = true
+	}
+
+	return pool, nil
+}
+
+func (s *Setup) NewDocRepository(path string, params ...string) (storage.DocRepository, error) {
+	pool, err := s.getLivePool(path, params...)
+	if err != nil {
+		return nil, err
+	}
 	return zombiezen.NewDocStore(pool), nil
 }
 
@@ -65,13 +87,21 @@ func (s *Setup) NewCorpusRepository(path string, params ...string) (storage.Corp
 }
 
 func (s *Setup) NewLiveTopicRepository(path string, params ...string) (storage.TopicRepository, error) {
-	pool, err := s.getPool(path, params...)
+	pool, err := s.getLivePool(path, params...)
 	if err != nil {
 		return nil, err
 	}
 	return zombiezen.NewLiveTopicStore(pool), nil
 }
 
+func (s *Setup) NewDrillRepository(path string, params ...string) (storage.DrillRepository, error) {
+	pool, err := s.getLivePool(path, params...)
+	if err != nil {
+		return nil, err
+	}
+	return zombiezen.NewDrillStore(pool), nil
+}
+
 func (s *Setup) NewCorpusTopicRepository(path string, params ...string) (storage.TopicRepository, error) {
 	// Corpus uses sqlite exclusively
 	pool, err := s.getPool(path, params...)
@@ -91,3 +121,11 @@ func (s *Setup) Close() error {
 	}
 	return firstErr
 }
+
+func (s *Setup) NewTopicIndexRepository(path string, params ...string) (storage.TopicIndexRepository, error) {
+	pool, err := s.getLivePool(path, params...)
+	if err != nil {
+		return nil, err
+	}
+	return zombiezen.NewTopicIndexStore(pool), nil
+}
@@ -1,16 +1,125 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
 
 	sent "github.com/revelaction/segrob/sentence"
 	"github.com/revelaction/segrob/stat"
 )
 
-func printStats(sentences []sent.Sentence, ui UI) {
+const (
+	statFmt         = "%-22s %s\n"
+	statBarFmt      = "  %-10s %s %6d %6.1f%%\n"
+	statBarWidth    = 30
+	statLengthWidth = 5 // tokens per bucket of the sentence length histogram
+)
+
+// printStats writes the statistics of the sentences as a table or JSON.
+func printStats(sentences []sent.Sentence, format string, ui UI) error {
 	hdl := stat.NewHandler()
 	hdl.Aggregate(sentences)
+	return writeStats(ui.Out, hdl.Get(), format)
+}
+
+func writeStats(w io.Writer, stats stat.Stats, format string) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	}
+
+	_, _ = fmt.Fprintf(w, statFmt, "Sentences", strconv.Itoa(stats.NumSentences))
+	_, _ = fmt.Fprintf(w, statFmt, "Tokens", strconv.Itoa(stats.NumTokens))
+	_, _ = fmt.Fprintf(w, statFmt, "Tokens per sentence", strconv.Itoa(stats.TokensPerSentenceMean))
+	_, _ = fmt.Fprintf(w, statFmt, "Words", strconv.Itoa(stats.NumWords))
+	_, _ = fmt.Fprintf(w, statFmt, "Types", strconv.Itoa(stats.NumTypes))
+	_, _ = fmt.Fprintf(w, statFmt, "TTR", fmt.Sprintf("%.4f", stats.TTR))
+	_, _ = fmt.Fprintf(w, statFmt, fmt.Sprintf("MATTR (%d)", stat.MATTRWindow), fmt.Sprintf("%.4f", stats.MATTR))
+	_, _ = fmt.Fprintf(w, statFmt, "Dialogue sentences", fmt.Sprintf("%d (%.1f%%)", stats.DialogueSentences, 100*stats.DialogueRatio))
+
+	if stats.NumSentences == 0 {
+		return nil
+	}
+
+	// Sentence lengths in buckets of statLengthWidth tokens, without gaps
+	maxLen := 0
+	for n := range stats.TokensPerSentenceDis {
+		maxLen = max(maxLen, n)
+	}
+	lengths := make([]int, maxLen/statLengthWidth+1)
+	for n, count := range stats.TokensPerSentenceDis {
+		lengths[n/statLengthWidth] += count
+	}
+	var names []string
+	for i := range lengths {
+		names = append(names, fmt.Sprintf("%d-%d", i*statLengthWidth, (i+1)*statLengthWidth-1))
+	}
+	writeStatBars(w, "Sentence length (tokens)", names, lengths, stats.NumSentences)
+
+	var pos []string
+	for p := range stats.PosDis {
+		pos = append(pos, p)
+	}
+	sort.Slice(pos, func(i, j int) bool {
+		ni, nj := stats.PosDis[pos[i]], stats.PosDis[pos[j]]
+		if ni != nj {
+			return ni > nj
+		}
+		return pos[i] < pos[j]
+	})
+	var posCounts []int
+	for _, p := range pos {
+		posCounts = append(posCounts, stats.PosDis[p])
+	}
+	writeStatBars(w, "Part of speech", pos, posCounts, stats.NumTokens)
+
+	maxDepth := 0
+	for d := range stats.DepthDis {
+		maxDepth = max(maxDepth, d)
+	}
+	var depths []string
+	depthCounts := make([]int, maxDepth+1)
+	for d := range depthCounts {
+		depths = append(depths, strconv.Itoa(d))
+		depthCounts[d] = stats.DepthDis[d]
+	}
+	writeStatBars(w, "Dependency tree depth", depths, depthCounts, stats.NumSentences)
+
+	var lemmas []string
+	var lemmaCounts []int
+	for _, l := range stats.TopLemmas {
+		lemmas = append(lemmas, truncate(l.Lemma, 10))
+		lemmaCounts = append(lemmaCounts, l.Count)
+	}
+	writeStatBars(w, "Top lemmas", lemmas, lemmaCounts, stats.NumWords)
+
+	return nil
+}
+
+// writeStatBars writes a titled bar chart of the counts, scaled to the
+// largest, with their percentage of total.
+func writeStatBars(w io.Writer, title string, names []string, counts []int, total int) {
+	_, _ = fmt.Fprintf(w, "\n%s\n", title)
+
+	maxCount := 0
+	for _, c := range counts {
+		maxCount = max(maxCount, c)
+	}
 
-	stats := hdl.Get()
-	_, _ = fmt.Fprintf(ui.Out, "Num sentences %d, num tokens per sentence %d\n", stats.NumSentences, stats.TokensPerSentenceMean)
+	for i, c := range counts {
+		bar := ""
+		if maxCount > 0 {
+			bar = strings.Repeat("█", c*statBarWidth/maxCount)
+		}
+		pct := 0.0
+		if total > 0 {
+			pct = 100 * float64(c) / float64(total)
+		}
+		_, _ = fmt.Fprintf(w, statBarFmt, names[i], padRight(bar, statBarWidth), c, pct)
+	}
 }
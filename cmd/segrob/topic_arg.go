@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/revelaction/segrob/storage"
+	"github.com/revelaction/segrob/topic"
+)
+
+// resolveTopicArgs interprets the positional arguments of commands accepting
+// <topic|expr>. A single argument naming a live topic of the user returns
+// that topic.
+// Otherwise the arguments are parsed as one expression (quoted expressions
+// are flattened, as in live find) and returned as an unnamed topic with a
+// single expression.
+func resolveTopicArgs(ctx context.Context, tr storage.TopicReader, userID string, args []string) (topic.Topic, error) {
+	if len(args) == 1 {
+		lib, err := tr.ReadAll(ctx, userID)
+		if err != nil {
+			return topic.Topic{}, err
+		}
+
+		for _, tp := range lib {
+			if tp.Name == args[0] {
+				return tp, nil
+			}
+		}
+	}
+
+	var flatArgs []string
+	for _, arg := range args {
+		flatArgs = append(flatArgs, strings.Fields(arg)...)
+	}
+
+	expr, err := topic.Parse(flatArgs)
+	if err != nil {
+		return topic.Topic{}, err
+	}
+
+	return topic.Topic{Exprs: []topic.TopicExpr{expr}}, nil
+}
@@ -0,0 +1,221 @@
+package conllu
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	sent "github.com/revelaction/segrob/sentence"
+)
+
+// word is a parsed word line.
+type word struct {
+	id     int
+	form   string
+	lemma  string
+	upos   string
+	feats  string
+	head   int
+	deprel string
+	space  bool // SpaceAfter is not No
+}
+
+// Read parses CoNLL-U sentences into segrob tokens, numbering the sentences
+// from 0 in file order:
+//
+//   - the words of a multi-word token range get the text of the range, and
+//     share its offset, as in the NLP script output;
+//   - Tag is UPOS__FEATS, or UPOS without features;
+//   - heads become sentence indexes, the root being its own head;
+//   - offsets (idx) are rebuilt from the forms and SpaceAfter=No, with a
+//     space between sentences; ids run across the document.
+//
+// Empty nodes (decimal ids) and comments are skipped.
+func Read(r io.Reader) ([]sent.Sentence, error) {
+	var (
+		sentences []sent.Sentence
+		words     []word
+		ranges    = make(map[int]word) // first id of a range -> range
+		rangeEnd  = make(map[int]int)
+		tokenId   int
+		offset    int
+		lineNo    int
+		startLine int
+	)
+
+	flush := func() error {
+		if len(words) == 0 {
+			return nil
+		}
+
+		s, err := convert(words, ranges, rangeEnd, len(sentences), &tokenId, &offset)
+		if err != nil {
+			return fmt.Errorf("sentence at line %d: %w", startLine, err)
+		}
+		sentences = append(sentences, s)
+
+		words = nil
+		ranges = make(map[int]word)
+		rangeEnd = make(map[int]int)
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if strings.TrimSpace(line) == "" {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if len(words) == 0 && len(ranges) == 0 {
+			startLine = lineNo
+		}
+
+		cols := strings.Split(line, "\t")
+		if len(cols) != 10 {
+			return nil, fmt.Errorf("line %d: %d columns, want 10", lineNo, len(cols))
+		}
+
+		id := cols[0]
+		if strings.Contains(id, ".") {
+			continue
+		}
+
+		if first, last, ok := strings.Cut(id, "-"); ok {
+			start, err1 := strconv.Atoi(first)
+			end, err2 := strconv.Atoi(last)
+			if err1 != nil || err2 != nil || end <= start {
+				return nil, fmt.Errorf("line %d: invalid range %q", lineNo, id)
+			}
+			ranges[start] = word{form: cols[1], space: !hasSpaceAfterNo(cols[9])}
+			rangeEnd[start] = end
+			continue
+		}
+
+		n, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid id %q", lineNo, id)
+		}
+		if n != len(words)+1 {
+			return nil, fmt.Errorf("line %d: id %d, want %d", lineNo, n, len(words)+1)
+		}
+
+		head, err := strconv.Atoi(cols[6])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid head %q", lineNo, cols[6])
+		}
+
+		words = append(words, word{
+			id:     n,
+			form:   cols[1],
+			lemma:  cols[2],
+			upos:   cols[3],
+			feats:  cols[5],
+			head:   head,
+			deprel: cols[7],
+			space:  !hasSpaceAfterNo(cols[9]),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return sentences, nil
+}
+
+// convert builds the tokens of a sentence, advancing the doc-wide token id
+// and character offset.
+func convert(words []word, ranges map[int]word, rangeEnd map[int]int, sentenceId int, tokenId, offset *int) (sent.Sentence, error) {
+	s := sent.Sentence{SentenceId: sentenceId}
+
+	roots := 0
+	lastSpace := true
+	for i := 0; i < len(words); {
+		w := words[i]
+		text, space, end := w.form, w.space, w.id
+		if r, ok := ranges[w.id]; ok {
+			text, space, end = r.form, r.space, rangeEnd[w.id]
+			if end > len(words) {
+				return s, fmt.Errorf("range %d-%d beyond the last word", w.id, end)
+			}
+		}
+
+		for ; i < end; i++ {
+			w := words[i]
+			if w.head < 0 || w.head > len(words) {
+				return s, fmt.Errorf("word %d: head %d out of range", w.id, w.head)
+			}
+
+			head := w.head - 1
+			dep := strings.ToLower(w.deprel)
+			if w.head == 0 {
+				head = i
+				dep = "root"
+				roots++
+			}
+
+			tag := w.upos
+			if w.feats != "_" && w.feats != "" {
+				tag += "__" + w.feats
+			}
+
+			lemma := w.lemma
+			if lemma == "_" && w.form != "_" {
+				lemma = w.form
+			}
+
+			s.Tokens = append(s.Tokens, sent.Token{
+				Id:    *tokenId,
+				Head:  head,
+				Pos:   w.upos,
+				Dep:   dep,
+				Tag:   tag,
+				Idx:   *offset,
+				Text:  text,
+				Lemma: strings.ToLower(lemma),
+				Index: i,
+			})
+			*tokenId++
+		}
+
+		*offset += len([]rune(text))
+		if space {
+			*offset++
+		}
+		lastSpace = space
+	}
+
+	if roots != 1 {
+		return s, fmt.Errorf("%d roots, want 1", roots)
+	}
+
+	// sentences are separated by a space
+	if !lastSpace {
+		*offset++
+	}
+
+	return s, nil
+}
+
+func hasSpaceAfterNo(misc string) bool {
+	for _, m := range strings.Split(misc, "|") {
+		if m == "SpaceAfter=No" {
+			return true
+		}
+	}
+	return false
+}
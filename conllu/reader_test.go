@@ -0,0 +1,108 @@
+package conllu
+
+import (
+	"strings"
+	"testing"
+
+	sent "github.com/revelaction/segrob/sentence"
+)
+
+const sample = `# newdoc id = doc1
+# sent_id = doc1-0
+# text = Dámelo, dijo.
+1-3	Dámelo	_	_	_	_	_	_	_	SpaceAfter=No
+1	Dá	dar	VERB	_	Mood=Imp|VerbForm=Fin	5	ccomp	_	_
+2	me	yo	PRON	_	Person=1	1	iobj	_	_
+3	lo	él	PRON	_	_	1	obj	_	_
+4	,	,	PUNCT	_	_	1	punct	_	_
+5	dijo	decir	VERB	_	Tense=Past	0	ROOT	_	SpaceAfter=No
+5.1	dijo	decir	VERB	_	_	_	_	0:root	_
+6	.	.	PUNCT	_	_	5	punct	_	_
+
+# sent_id = doc1-1
+1	Sí	sí	ADV	_	_	0	root	_	_
+
+`
+
+func TestRead(t *testing.T) {
+	sentences, err := Read(strings.NewReader(sample))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(sentences) != 2 || sentences[1].SentenceId != 1 {
+		t.Fatalf("sentences = %+v, want 2", sentences)
+	}
+
+	tokens := sentences[0].Tokens
+	if len(tokens) != 6 {
+		t.Fatalf("tokens = %d, want 6 (empty node skipped)", len(tokens))
+	}
+
+	want := sent.Token{Id: 1, Head: 0, Pos: "PRON", Dep: "iobj", Tag: "PRON__Person=1", Idx: 0, Text: "Dámelo", Lemma: "yo", Index: 1}
+	if tokens[1] != want {
+		t.Fatalf("token 1 = %+v, want %+v", tokens[1], want)
+	}
+
+	// "Dámelo, dijo." then "Sí" after a space
+	if tokens[3].Idx != 6 || tokens[4].Idx != 8 || tokens[4].Head != 4 || tokens[4].Dep != "root" || tokens[2].Tag != "PRON" {
+		t.Fatalf("tokens = %+v", tokens)
+	}
+	if s := sentences[1].Tokens[0]; s.Idx != 14 || s.Id != 6 || s.Head != 0 {
+		t.Fatalf("second sentence token = %+v, want idx 14, id 6", s)
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	cases := map[string]string{
+		"gap":       "1\ta\ta\tX\t_\t_\t0\troot\t_\t_\n3\tb\tb\tX\t_\t_\t1\tdep\t_\t_\n",
+		"head":      "1\ta\ta\tX\t_\t_\t0\troot\t_\t_\n2\tb\tb\tX\t_\t_\t7\tdep\t_\t_\n",
+		"two roots": "1\ta\ta\tX\t_\t_\t0\troot\t_\t_\n2\tb\tb\tX\t_\t_\t0\troot\t_\t_\n",
+		"columns":   "1\ta\ta\tX\t_\t_\t0\troot\n",
+		"range":     "1-3\tab\t_\t_\t_\t_\t_\t_\t_\t_\n1\ta\ta\tX\t_\t_\t0\troot\t_\t_\n2\tb\tb\tX\t_\t_\t1\tdep\t_\t_\n",
+	}
+	for name, in := range cases {
+		if _, err := Read(strings.NewReader(in)); err == nil {
+			t.Fatalf("%s: Read succeeded, want an error", name)
+		}
+	}
+}
+
+// TestRoundTrip checks that the written CoNLL-U reads back to the same
+// tokens, but for the doc-wide ids and offsets.
+func TestRoundTrip(t *testing.T) {
+	sentences, err := Read(strings.NewReader(sample))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	var b strings.Builder
+	w := NewWriter(&b)
+	for _, s := range sentences {
+		s.DocId = "doc1"
+		if err := w.Write(s); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	again, err := Read(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("Read written: %v\n%s", err, b.String())
+	}
+
+	if len(again) != len(sentences) {
+		t.Fatalf("sentences = %d, want %d", len(again), len(sentences))
+	}
+	for i := range sentences {
+		if len(again[i].Tokens) != len(sentences[i].Tokens) {
+			t.Fatalf("sentence %d: %d tokens, want %d", i, len(again[i].Tokens), len(sentences[i].Tokens))
+		}
+		for j, tok := range sentences[i].Tokens {
+			if again[i].Tokens[j] != tok {
+				t.Fatalf("sentence %d token %d = %+v, want %+v", i, j, again[i].Tokens[j], tok)
+			}
+		}
+	}
+}
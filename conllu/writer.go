@@ -0,0 +1,140 @@
+// Package conllu reads and writes sentences in the CoNLL-U format of
+// Universal Dependencies (https://universaldependencies.org/format.html).
+package conllu
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	sent "github.com/revelaction/segrob/sentence"
+)
+
+// Writer writes sentences as CoNLL-U. The doc and sentence ids go in the
+// newdoc and sent_id comments; the text is rebuilt from the token offsets.
+type Writer struct {
+	w     *bufio.Writer
+	docId string
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Write writes a sentence, preceded by a newdoc comment if its document
+// differs from the previous sentence's.
+//
+// Multi-word tokens (consecutive tokens sharing idx) are written as a range
+// line with the surface text; the syntactic words, whose own forms are not
+// kept by segrob, get their lemma as form. Heads are read with
+// sent.Heads and become 1-based, the root 0. FEATS comes from the Tag. XPOS
+// and DEPS are not kept.
+//
+// A sentence whose heads do not make a tree is not written; the error wraps
+// sent.ErrTree.
+func (cw *Writer) Write(s sent.Sentence) error {
+	heads := sent.Heads(s.Tokens)
+	if err := sent.CheckHeads(heads); err != nil {
+		return fmt.Errorf("sentence %s-%d: %w", s.DocId, s.SentenceId, err)
+	}
+
+	if s.DocId != cw.docId {
+		fmt.Fprintf(cw.w, "# newdoc id = %s\n", s.DocId)
+		cw.docId = s.DocId
+	}
+
+	fmt.Fprintf(cw.w, "# sent_id = %s-%d\n", s.DocId, s.SentenceId)
+
+	words := sent.Surface(s.Tokens)
+
+	var text strings.Builder
+	for i, w := range words {
+		text.WriteString(w[0].Text)
+		if i < len(words)-1 && spaceAfter(w[0], words[i+1][0]) {
+			text.WriteString(" ")
+		}
+	}
+	fmt.Fprintf(cw.w, "# text = %s\n", field(text.String()))
+
+	id := 0 // 1-based id of the last word written
+	for i, w := range words {
+		misc := "_"
+		if i < len(words)-1 && !spaceAfter(w[0], words[i+1][0]) {
+			misc = "SpaceAfter=No"
+		}
+
+		if len(w) > 1 {
+			fmt.Fprintf(cw.w, "%d-%d\t%s\t_\t_\t_\t_\t_\t_\t_\t%s\n", id+1, id+len(w), field(w[0].Text), misc)
+			misc = "_"
+		}
+
+		for _, t := range w {
+			form := t.Text
+			if len(w) > 1 {
+				form = t.Lemma
+			}
+
+			// heads are positions, ids are positions + 1
+			head := heads[id] + 1
+			id++
+
+			_, feats, _ := strings.Cut(t.Tag, "__")
+
+			cols := []string{
+				strconv.Itoa(id),
+				field(form),
+				field(t.Lemma),
+				field(t.Pos),
+				"_",
+				field(feats),
+				strconv.Itoa(head),
+				field(deprel(t.Dep, head)),
+				"_",
+				misc,
+			}
+			fmt.Fprintln(cw.w, strings.Join(cols, "\t"))
+		}
+	}
+
+	_, err := fmt.Fprintln(cw.w)
+	return err
+}
+
+// Flush writes the buffered data to the underlying writer.
+func (cw *Writer) Flush() error {
+	return cw.w.Flush()
+}
+
+// spaceAfter reports whether the text has a space between two tokens.
+func spaceAfter(t, next sent.Token) bool {
+	return next.Idx > t.Idx+len([]rune(t.Text))
+}
+
+// deprel returns the relation of a token: root for the root only, dep (the
+// unspecified relation) for the other words spaCy splits from a root.
+func deprel(dep string, head int) string {
+	switch {
+	case head == 0:
+		return "root"
+	case strings.EqualFold(dep, "root"):
+		return "dep"
+	}
+	return dep
+}
+
+// field escapes a column value: tabs and line breaks become spaces, an empty
+// value is _.
+func field(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return ' '
+		}
+		return r
+	}, s)
+	if s == "" {
+		return "_"
+	}
+	return s
+}
@@ -0,0 +1,140 @@
+package conllu
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	sent "github.com/revelaction/segrob/sentence"
+)
+
+func TestWrite(t *testing.T) {
+	// "Dámelo." with "Dámelo" split in three words by the NLP
+	s := sent.Sentence{
+		DocId:      "doc1",
+		SentenceId: 3,
+		Tokens: []sent.Token{
+			{Index: 0, Idx: 10, Text: "Dámelo", Lemma: "dar", Pos: "VERB", Tag: "VERB__Mood=Imp|VerbForm=Fin", Dep: "root", Head: 0},
+			{Index: 1, Idx: 10, Text: "Dámelo", Lemma: "yo", Pos: "PRON", Tag: "PRON__Person=1", Dep: "iobj", Head: 0},
+			{Index: 2, Idx: 10, Text: "Dámelo", Lemma: "él", Pos: "PRON", Tag: "PRON__", Dep: "obj", Head: 0},
+			{Index: 3, Idx: 16, Text: ".", Lemma: ".", Pos: "PUNCT", Tag: "PUNCT__PunctType=Peri", Dep: "punct", Head: 0},
+		},
+	}
+
+	var b strings.Builder
+	w := NewWriter(&b)
+	if err := w.Write(s); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"# newdoc id = doc1",
+		"# sent_id = doc1-3",
+		"# text = Dámelo.",
+		"1-3\tDámelo\t_\t_\t_\t_\t_\t_\t_\tSpaceAfter=No",
+		"1\tdar\tdar\tVERB\t_\tMood=Imp|VerbForm=Fin\t0\troot\t_\t_",
+		"2\tyo\tyo\tPRON\t_\tPerson=1\t1\tiobj\t_\t_",
+		"3\tél\tél\tPRON\t_\t_\t1\tobj\t_\t_",
+		"4\t.\t.\tPUNCT\t_\tPunctType=Peri\t1\tpunct\t_\t_",
+		"", "",
+	}, "\n")
+	if b.String() != want {
+		t.Fatalf("Write =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+// writeLines writes a sentence and returns its word lines.
+func writeLines(t *testing.T, tokens []sent.Token) []string {
+	t.Helper()
+	var b strings.Builder
+	w := NewWriter(&b)
+	if err := w.Write(sent.Sentence{DocId: "doc1", Tokens: tokens}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	var lines []string
+	for _, l := range strings.Split(b.String(), "\n") {
+		if l != "" && !strings.HasPrefix(l, "#") {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+func TestWriteSpacyHeads(t *testing.T) {
+	// "Dámelo, dijo." as split by nlp_spacy.py: the words of "Dámelo" repeat
+	// the surface token, heads count surface tokens.
+	tokens := []sent.Token{
+		{Index: 0, Idx: 0, Text: "Dámelo", Lemma: "dar", Pos: "VERB", Tag: "VERB__", Dep: "ccomp", Head: 2},
+		{Index: 1, Idx: 0, Text: "Dámelo", Lemma: "yo", Pos: "VERB", Tag: "VERB__", Dep: "ccomp", Head: 2},
+		{Index: 2, Idx: 0, Text: "Dámelo", Lemma: "él", Pos: "VERB", Tag: "VERB__", Dep: "ccomp", Head: 2},
+		{Index: 3, Idx: 6, Text: ",", Lemma: ",", Pos: "PUNCT", Tag: "PUNCT__", Dep: "punct", Head: 0},
+		{Index: 4, Idx: 8, Text: "dijo", Lemma: "decir", Pos: "VERB", Tag: "VERB__", Dep: "ROOT", Head: 2},
+		{Index: 5, Idx: 12, Text: ".", Lemma: ".", Pos: "PUNCT", Tag: "PUNCT__", Dep: "punct", Head: 2},
+	}
+
+	want := []string{
+		"1-3\tDámelo\t_\t_\t_\t_\t_\t_\t_\tSpaceAfter=No",
+		"1\tdar\tdar\tVERB\t_\t_\t5\tccomp\t_\t_",
+		"2\tyo\tyo\tVERB\t_\t_\t1\tccomp\t_\t_",
+		"3\tél\tél\tVERB\t_\t_\t1\tccomp\t_\t_",
+		"4\t,\t,\tPUNCT\t_\t_\t1\tpunct\t_\t_",
+		"5\tdijo\tdecir\tVERB\t_\t_\t0\troot\t_\tSpaceAfter=No",
+		"6\t.\t.\tPUNCT\t_\t_\t5\tpunct\t_\t_",
+	}
+	if got := writeLines(t, tokens); !slices.Equal(got, want) {
+		t.Fatalf("Write =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestWriteStanzaRoot(t *testing.T) {
+	// stanza gave the root a head of 0
+	tokens := []sent.Token{
+		{Index: 0, Idx: 0, Text: "El", Lemma: "el", Pos: "DET", Dep: "det", Head: 1},
+		{Index: 1, Idx: 3, Text: "hombre", Lemma: "hombre", Pos: "NOUN", Dep: "nsubj", Head: 2},
+		{Index: 2, Idx: 10, Text: "dijo", Lemma: "decir", Pos: "VERB", Dep: "root", Head: 0},
+	}
+
+	want := []string{
+		"1\tEl\tel\tDET\t_\t_\t2\tdet\t_\t_",
+		"2\thombre\thombre\tNOUN\t_\t_\t3\tnsubj\t_\t_",
+		"3\tdijo\tdecir\tVERB\t_\t_\t0\troot\t_\t_",
+	}
+	if got := writeLines(t, tokens); !slices.Equal(got, want) {
+		t.Fatalf("Write =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestWriteInvalidTree(t *testing.T) {
+	cases := map[string][]sent.Token{
+		"two roots": {
+			{Index: 0, Idx: 0, Text: "Sí", Dep: "root", Head: 0},
+			{Index: 1, Idx: 3, Text: "no", Dep: "root", Head: 1},
+		},
+		"cycle": {
+			{Index: 0, Idx: 0, Text: "a", Dep: "dep", Head: 1},
+			{Index: 1, Idx: 2, Text: "b", Dep: "dep", Head: 0},
+			{Index: 2, Idx: 4, Text: "c", Dep: "root", Head: 2},
+		},
+	}
+
+	for name, tokens := range cases {
+		var b strings.Builder
+		w := NewWriter(&b)
+		err := w.Write(sent.Sentence{DocId: "doc1", Tokens: tokens})
+		if !errors.Is(err, sent.ErrTree) {
+			t.Fatalf("%s: Write error %v, want %v", name, err, sent.ErrTree)
+		}
+		w.Flush()
+		if b.Len() != 0 {
+			t.Fatalf("%s: Write wrote %q", name, b.String())
+		}
+	}
+}
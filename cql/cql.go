@@ -0,0 +1,307 @@
+// Package cql parses a subset of the Corpus Query Language of CWB/CQP and
+// SketchEngine and matches it against sentences:
+//
+//	[lemma="decir"] []{0,3} [pos="NOUN"]
+//
+// A query is a sequence of token patterns. A token pattern is a boolean
+// expression of attribute tests between brackets, [] being any token, or a
+// bare string standing for [word="..."]:
+//
+//	[lemma="decir" & tag=".*Tense=Past.*"]
+//	[pos="NOUN|PROPN" | dep!="nsubj"]
+//	[!(lemma="ser" | lemma="estar")]
+//	[word="Dijo"%c]
+//
+// The attributes are word, lemma, pos, tag (POS__FEATS), feats and dep. The
+// values are regular expressions matching the whole attribute, %c makes them
+// case insensitive. Lemmas are lowercase, so lemma tests always ignore case.
+// Token patterns take the quantifiers ?, *, +, {n}, {n,m}, {n,} and {,m};
+// []{0,3} is a gap of up to three tokens. Whole sequences are alternated
+// with |:
+//
+//	[lemma="decir"] [pos="NOUN"] | [lemma="hablar"] [pos="ADP"]
+//
+// Structures (<s>), labels (a:[...]), target markers (@), grouped
+// sequences, within, containing and global conditions (::) are not
+// supported.
+//
+// The sentences are fetched through the lemma index: every alternative must
+// require a literal lemma, [lemma="decir"], outside an optional pattern or a
+// negation. In an alternation of tests, [lemma="decir" | lemma="hablar"],
+// every branch must require one.
+package cql
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/revelaction/segrob/match"
+	sent "github.com/revelaction/segrob/sentence"
+)
+
+// maxCandidates bounds the lemma sets of a query: each one is a stream of
+// candidates of the lemma index.
+const maxCandidates = 64
+
+// Attributes are the token attributes of the tests.
+var Attributes = []string{"word", "lemma", "pos", "tag", "feats", "dep"}
+
+// Query is a parsed CQL query: alternative sequences of token patterns.
+type Query struct {
+	src          string
+	alternatives [][]pattern
+}
+
+// pattern is a token pattern with its quantifier. A nil cond is [], any
+// token; a max of -1 is unbounded.
+type pattern struct {
+	cond     cond
+	min, max int
+}
+
+// cond is a boolean expression on the attributes of a token.
+type cond interface {
+	test(t sent.Token) bool
+
+	// lemmas returns the lemma sets of cond: a token can only make cond
+	// true if it has all the lemmas of one of the sets. An empty set
+	// requires no lemma.
+	lemmas() [][]string
+}
+
+type attrTest struct {
+	attr    string
+	re      *regexp.Regexp
+	literal string // lowercase value of a lemma test without metacharacters
+	negated bool   // !=
+}
+
+func (a *attrTest) test(t sent.Token) bool {
+	return a.re.MatchString(attribute(t, a.attr)) != a.negated
+}
+
+func (a *attrTest) lemmas() [][]string {
+	if a.attr == "lemma" && a.literal != "" && !a.negated {
+		return [][]string{{a.literal}}
+	}
+	return [][]string{nil}
+}
+
+type andCond struct{ left, right cond }
+
+func (c *andCond) test(t sent.Token) bool { return c.left.test(t) && c.right.test(t) }
+
+func (c *andCond) lemmas() [][]string { return product(c.left.lemmas(), c.right.lemmas()) }
+
+type orCond struct{ left, right cond }
+
+func (c *orCond) test(t sent.Token) bool { return c.left.test(t) || c.right.test(t) }
+
+// lemmas of an alternation are the sets of both sides
+func (c *orCond) lemmas() [][]string { return appendSets(c.left.lemmas(), c.right.lemmas()...) }
+
+type notCond struct{ c cond }
+
+func (c *notCond) test(t sent.Token) bool { return !c.c.test(t) }
+
+func (c *notCond) lemmas() [][]string { return [][]string{nil} }
+
+// attribute returns the value of a token attribute.
+func attribute(t sent.Token, attr string) string {
+	switch attr {
+	case "word":
+		return t.Text
+	case "lemma":
+		return t.Lemma
+	case "pos":
+		return t.Pos
+	case "tag":
+		return t.Tag
+	case "feats":
+		_, feats, _ := strings.Cut(t.Tag, "__")
+		return feats
+	case "dep":
+		return t.Dep
+	}
+	return ""
+}
+
+// String returns the query as given.
+func (q *Query) String() string {
+	return q.src
+}
+
+// Candidates returns the lemma sets of the alternatives, one per branch of
+// their alternations of tests: a sentence can only match the query if it
+// has all the lemmas of one of the sets.
+func (q *Query) Candidates() [][]string {
+	var sets [][]string
+	for _, seq := range q.alternatives {
+		sets = appendSets(sets, sequenceLemmas(seq)...)
+	}
+	return sets
+}
+
+func sequenceLemmas(seq []pattern) [][]string {
+	sets := [][]string{nil}
+	for _, p := range seq {
+		if p.cond != nil && p.min > 0 {
+			sets = product(sets, p.cond.lemmas())
+		}
+	}
+	return sets
+}
+
+// MatchSentence returns the matches of the query in the sentence, or nil.
+// Matches do not overlap: from left to right, the first alternative matching
+// at a token wins, and its quantifiers take as many tokens as they can. The
+// tokens of a match are those of the patterns other than [].
+func (q *Query) MatchSentence(s sent.Sentence) *match.SentenceMatch {
+	var chains [][]sent.Token
+	for start := 0; start < len(s.Tokens); {
+		end := -1
+		for _, seq := range q.alternatives {
+			var chain []sent.Token
+			var ok bool
+			chain, end, ok = matchAt(seq, s.Tokens, start)
+			if ok && end > start {
+				chains = append(chains, chain)
+				break
+			}
+			end = -1
+		}
+
+		if end < 0 {
+			start++
+			continue
+		}
+		start = end
+	}
+
+	if len(chains) == 0 {
+		return nil
+	}
+
+	return &match.SentenceMatch{
+		Tokens:   chains,
+		Sentence: s,
+		Expr:     q.src,
+	}
+}
+
+// matchAt matches the patterns from the token at pos, backtracking from the
+// longest repetition of each pattern. It returns the matched tokens and the
+// end position.
+func matchAt(seq []pattern, tokens []sent.Token, pos int) ([]sent.Token, int, bool) {
+	m := &matcher{seq: seq, tokens: tokens, failed: make([]bool, (len(seq)+1)*(len(tokens)+1))}
+	return m.match(0, pos, nil)
+}
+
+// matcher memoizes the failures of a matchAt: whether the patterns from i
+// match from a token does not depend on the tokens matched before, so each
+// (pattern, token) pair is tried once and gaps such as []* [lemma="x"] []*
+// stay polynomial.
+type matcher struct {
+	seq    []pattern
+	tokens []sent.Token
+	failed []bool
+}
+
+func (m *matcher) match(i, pos int, chain []sent.Token) ([]sent.Token, int, bool) {
+	if i == len(m.seq) {
+		return chain, pos, true
+	}
+
+	key := i*(len(m.tokens)+1) + pos
+	if m.failed[key] {
+		return nil, 0, false
+	}
+
+	p := m.seq[i]
+	n := 0
+	for pos+n < len(m.tokens) && (p.max < 0 || n < p.max) && (p.cond == nil || p.cond.test(m.tokens[pos+n])) {
+		n++
+	}
+
+	for k := n; k >= p.min; k-- {
+		next := chain
+		if p.cond != nil {
+			next = append(slices.Clip(chain), m.tokens[pos:pos+k]...)
+		}
+		if res, end, ok := m.match(i+1, pos+k, next); ok {
+			return res, end, true
+		}
+	}
+
+	m.failed[key] = true
+	return nil, 0, false
+}
+
+func union(a, b []string) []string {
+	for _, l := range b {
+		if !slices.Contains(a, l) {
+			a = append(a, l)
+		}
+	}
+	return a
+}
+
+// product returns the union of every set of a with every set of b.
+func product(a, b [][]string) [][]string {
+	var sets [][]string
+	for _, x := range a {
+		for _, y := range b {
+			sets = appendSets(sets, union(slices.Clone(x), y))
+		}
+	}
+	return sets
+}
+
+// appendSets appends the lemma sets not already in sets.
+func appendSets(sets [][]string, more ...[]string) [][]string {
+	for _, m := range more {
+		if !slices.ContainsFunc(sets, func(s []string) bool { return sameSet(s, m) }) {
+			sets = append(sets, m)
+		}
+	}
+	return sets
+}
+
+func sameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, l := range a {
+		if !slices.Contains(b, l) {
+			return false
+		}
+	}
+	return true
+}
+
+// Parse parses a CQL query. Unsupported constructs and alternatives without
+// a required literal lemma are reported as errors.
+func Parse(src string) (*Query, error) {
+	p := &parser{src: src}
+	q, err := p.query()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, seq := range q.alternatives {
+		if slices.ContainsFunc(sequenceLemmas(seq), func(set []string) bool { return len(set) == 0 }) {
+			if len(q.alternatives) == 1 {
+				return nil, fmt.Errorf("cql: the query needs a literal lemma test such as [lemma=\"decir\"] to fetch candidates")
+			}
+			return nil, fmt.Errorf("cql: alternative %d needs a literal lemma test such as [lemma=\"decir\"] to fetch candidates", i+1)
+		}
+	}
+
+	if n := len(q.Candidates()); n > maxCandidates {
+		return nil, fmt.Errorf("cql: the alternations of lemmas expand to %d lemma sets, more than %d", n, maxCandidates)
+	}
+
+	return q, nil
+}
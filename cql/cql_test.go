@@ -0,0 +1,178 @@
+package cql
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	sent "github.com/revelaction/segrob/sentence"
+)
+
+// sentence builds a sentence from "text/lemma/pos/dep" words.
+func sentence(words ...string) sent.Sentence {
+	var s sent.Sentence
+	for i, w := range words {
+		f := strings.Split(w, "/")
+		s.Tokens = append(s.Tokens, sent.Token{Id: i, Index: i, Text: f[0], Lemma: f[1], Pos: f[2], Tag: f[2] + "__Number=Sing", Dep: f[3]})
+	}
+	return s
+}
+
+// matched returns the texts of the matches, a match per string.
+func matched(t *testing.T, query string, s sent.Sentence) []string {
+	t.Helper()
+	q, err := Parse(query)
+	if err != nil {
+		t.Fatalf("Parse(%s): %v", query, err)
+	}
+	sm := q.MatchSentence(s)
+	if sm == nil {
+		return nil
+	}
+	var res []string
+	for _, chain := range sm.Tokens {
+		var words []string
+		for _, tk := range chain {
+			words = append(words, tk.Text)
+		}
+		res = append(res, strings.Join(words, " "))
+	}
+	return res
+}
+
+func TestMatch(t *testing.T) {
+	s := sentence(
+		"El/el/DET/det",
+		"hombre/hombre/NOUN/nsubj",
+		"dijo/decir/VERB/root",
+		"que/que/SCONJ/mark",
+		"la/el/DET/det",
+		"casa/casa/NOUN/obj",
+		"era/ser/AUX/cop",
+		"grande/grande/ADJ/ccomp",
+		"y/y/CCONJ/cc",
+		"dijo/decir/VERB/conj",
+		"adiós/adiós/NOUN/obj",
+	)
+
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{`[lemma="decir"]`, []string{"dijo", "dijo"}},
+		{`[lemma="decir"] []{0,3} [pos="NOUN"]`, []string{"dijo casa", "dijo adiós"}},
+		{`[lemma="decir"] []{0,1} [pos="NOUN"]`, []string{"dijo adiós"}},
+		{`[lemma="decir"] [pos!="SCONJ"]`, []string{"dijo adiós"}},
+		{`[lemma="decir" & dep="conj"]`, []string{"dijo"}},
+		{`[lemma="el"] [pos="NOUN|ADJ"]`, []string{"El hombre", "la casa"}},
+		{`[lemma="el"] [!(pos="NOUN" | dep="obj")]`, nil},
+		{`[pos="NOUN"] [lemma="decir"] [pos="DET"]?`, []string{"hombre dijo"}},
+		{`[lemma="ser"] [pos="ADJ"]+ "y"`, []string{"era grande y"}},
+		{`[lemma="decir"] "QUE"%c`, []string{"dijo que"}},
+		{`[lemma="decir"] [tag="NOUN__.*"] | [lemma="ser"] [pos="ADJ"]`, []string{"era grande", "dijo adiós"}},
+		{`[lemma="casa"] [feats="Number=Sing"]`, []string{"casa era"}},
+		{`[lemma="decir"] [lemma="que"] | [lemma="decir"]`, []string{"dijo que", "dijo"}},
+		{`[lemma="Decir"] [lemma="QUE"]`, []string{"dijo que"}},
+		{`[lemma="decir"%c] [word="Que"]`, nil},
+	}
+
+	for _, c := range cases {
+		got := matched(t, c.query, s)
+		if !slices.Equal(got, c.want) {
+			t.Fatalf("%s: got %q, want %q", c.query, got, c.want)
+		}
+	}
+}
+
+func TestMatchGaps(t *testing.T) {
+	words := []string{"dijo/decir/VERB/root"}
+	for range 300 {
+		words = append(words, "casa/casa/NOUN/obj")
+	}
+	s := sentence(words...)
+
+	// without memoization the gaps backtrack over every split of the tokens
+	if got := matched(t, `[lemma="decir"] []* []* []* []* []* [lemma="ser"]`, s); got != nil {
+		t.Fatalf("got %q, want no match", got)
+	}
+	if got := matched(t, `[lemma="decir"] []* []* []* []* []* [lemma="casa"]`, s); !slices.Equal(got, []string{"dijo casa"}) {
+		t.Fatalf("got %q, want %q", got, []string{"dijo casa"})
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	q, err := Parse(`[lemma="decir" & pos="VERB"] [lemma="que"]? [lemma="casa|perro"] [lemma="el"] | [lemma="ser" | lemma="ser"] [!lemma="x"]`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	got := q.Candidates()
+	want := [][]string{{"decir", "el"}, {"ser"}}
+	if len(got) != len(want) || !slices.Equal(got[0], want[0]) || !slices.Equal(got[1], want[1]) {
+		t.Fatalf("Candidates = %q, want %q", got, want)
+	}
+
+	// a set per branch of an alternation of lemmas
+	q, err = Parse(`[lemma="decir" | lemma="hablar"] [lemma="que"] [lemma="el" | lemma="un"]`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	got = q.Candidates()
+	want = [][]string{{"decir", "que", "el"}, {"decir", "que", "un"}, {"hablar", "que", "el"}, {"hablar", "que", "un"}}
+	if len(got) != len(want) {
+		t.Fatalf("Candidates = %q, want %q", got, want)
+	}
+	for i := range want {
+		if !slices.Equal(got[i], want[i]) {
+			t.Fatalf("Candidates = %q, want %q", got, want)
+		}
+	}
+
+	// the lemma index is lowercase, %c is allowed
+	q, err = Parse(`[lemma="Decir"] | [lemma="SER"%c]`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	got = q.Candidates()
+	want = [][]string{{"decir"}, {"ser"}}
+	if len(got) != len(want) || !slices.Equal(got[0], want[0]) || !slices.Equal(got[1], want[1]) {
+		t.Fatalf("Candidates = %q, want %q", got, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := map[string]string{
+		`[pos="NOUN"]`:                   "literal lemma",
+		`[lemma="decir"] | [pos="NOUN"]`: "alternative 2",
+		`[lemma="decir" | pos="NOUN"]`:   "literal lemma",
+		`[lemma="d.*"]`:                  "literal lemma",
+		`[lemma="decir"] within s`:       "within is not supported",
+		`a:[lemma="decir"]`:              "labels",
+		`<s> [lemma="decir"]`:            "structures",
+		`@[lemma="decir"]`:               "target markers",
+		`([lemma="decir"] [])`:           "grouped sequences",
+		`[lemma="decir"] :: a.lemma="x"`: "global conditions",
+		`[xpos="VERB"]`:                  `unknown attribute "xpos"`,
+		`[lemma contains "x"]`:           "operator",
+		`[lemma "decir"]`:                "operator",
+		`[lemma="decir"%d]`:              "%c flag",
+		`[lemma="dec(ir"]`:               "regular expression",
+		`[lemma="decir"`:                 "expected ]",
+		`[lemma="decir]`:                 "unterminated",
+		`[lemma="decir"] []{3,1}`:        "quantifier",
+		`[lemma="decir"] []{a}`:          "quantifier",
+		`   `:                            "empty",
+		`[lemma="decir"] |`:              "expected a token pattern",
+		`[lemma="a"|lemma="b"] [lemma="c"|lemma="d"] [lemma="e"|lemma="f"] [lemma="g"|lemma="h"] [lemma="i"|lemma="j"] [lemma="k"|lemma="l"] [lemma="m"|lemma="n"]`: "128 lemma sets",
+	}
+
+	for query, want := range cases {
+		_, err := Parse(query)
+		if err == nil {
+			t.Fatalf("%s: Parse succeeded, want an error with %q", query, want)
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: error %q, want %q", query, err, want)
+		}
+	}
+}
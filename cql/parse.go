@@ -0,0 +1,374 @@
+package cql
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// parser is a recursive descent parser over the query source:
+//
+//	query    = sequence { "|" sequence }
+//	sequence = token { token }
+//	token    = ( "[" [ or ] "]" | string ) [ quantifier ]
+//	or       = and { "|" and }
+//	and      = not { "&" not }
+//	not      = "!" not | "(" or ")" | attr ( "=" | "!=" ) string [ "%c" ]
+type parser struct {
+	src string
+	pos int // byte offset
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("cql: at %d: %s", p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+// peek returns the next byte after the spaces, 0 at the end.
+func (p *parser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *parser) accept(s string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.src[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *parser) query() (*Query, error) {
+	q := &Query{src: strings.TrimSpace(p.src)}
+	if q.src == "" {
+		return nil, fmt.Errorf("cql: empty query")
+	}
+
+	for {
+		seq, err := p.sequence()
+		if err != nil {
+			return nil, err
+		}
+		q.alternatives = append(q.alternatives, seq)
+
+		if p.peek() == 0 {
+			return q, nil
+		}
+		if !p.accept("|") {
+			return nil, p.errorf("unexpected %q", p.src[p.pos])
+		}
+	}
+}
+
+func (p *parser) sequence() ([]pattern, error) {
+	var seq []pattern
+	for {
+		c := p.peek()
+		if c == 0 || c == '|' {
+			break
+		}
+
+		if err := p.unsupported(); err != nil {
+			return nil, err
+		}
+
+		var pt pattern
+		switch c {
+		case '[':
+			p.pos++
+			if !p.accept("]") {
+				cd, err := p.or()
+				if err != nil {
+					return nil, err
+				}
+				if !p.accept("]") {
+					return nil, p.errorf("expected ]")
+				}
+				pt.cond = cd
+			}
+		case '"', '\'':
+			// a bare string is a test of the word
+			t, err := p.test("word", true)
+			if err != nil {
+				return nil, err
+			}
+			pt.cond = t
+		default:
+			return nil, p.errorf("expected [ or a string, found %q", c)
+		}
+
+		if err := p.quantifier(&pt); err != nil {
+			return nil, err
+		}
+		seq = append(seq, pt)
+	}
+
+	if len(seq) == 0 {
+		return nil, p.errorf("expected a token pattern")
+	}
+	return seq, nil
+}
+
+// unsupported reports the CQL constructs outside the subset.
+func (p *parser) unsupported() error {
+	rest := p.src[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, "::"):
+		return p.errorf("global conditions (::) are not supported")
+	case rest[0] == '<':
+		return p.errorf("structures (<s>) are not supported")
+	case rest[0] == '@':
+		return p.errorf("target markers (@) are not supported")
+	case rest[0] == '(':
+		return p.errorf("grouped sequences are not supported, alternate whole queries with |")
+	}
+
+	word := p.ident()
+	if word == "" {
+		return nil
+	}
+
+	switch word {
+	case "within", "containing", "meet", "union", "not":
+		return p.errorf("%s is not supported", word)
+	}
+	if p.peek() == ':' {
+		return p.errorf("labels (%s:) are not supported", word)
+	}
+	return p.errorf("unexpected %q", word)
+}
+
+// ident reads a name, or returns "" leaving the position unchanged.
+func (p *parser) ident() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.src) && (isLetter(p.src[p.pos]) || p.pos > start && isDigit(p.src[p.pos])) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *parser) quantifier(pt *pattern) error {
+	pt.min, pt.max = 1, 1
+
+	// no space between a pattern and its quantifier
+	if p.pos >= len(p.src) {
+		return nil
+	}
+
+	switch p.src[p.pos] {
+	case '?':
+		pt.min, pt.max = 0, 1
+	case '*':
+		pt.min, pt.max = 0, -1
+	case '+':
+		pt.min, pt.max = 1, -1
+	case '{':
+		end := strings.IndexByte(p.src[p.pos:], '}')
+		if end < 0 {
+			return p.errorf("expected }")
+		}
+		body := p.src[p.pos+1 : p.pos+end]
+
+		lo, hi, isRange := strings.Cut(body, ",")
+		var err error
+		if pt.min, err = bound(lo, 0); err != nil {
+			return p.errorf("invalid quantifier {%s}", body)
+		}
+		pt.max = pt.min
+		if isRange {
+			if pt.max, err = bound(hi, -1); err != nil {
+				return p.errorf("invalid quantifier {%s}", body)
+			}
+		}
+		if pt.max >= 0 && pt.max < pt.min || pt.max == 0 {
+			return p.errorf("invalid quantifier {%s}", body)
+		}
+		p.pos += end
+	default:
+		return nil
+	}
+
+	p.pos++
+	return nil
+}
+
+// bound parses a quantifier bound, returning def if empty.
+func bound(s string, def int) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid bound %q", s)
+	}
+	return n, nil
+}
+
+func (p *parser) or() (cond, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("|") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = &orCond{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (cond, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&") {
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		left = &andCond{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) not() (cond, error) {
+	if p.accept("!") {
+		c, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return &notCond{c}, nil
+	}
+
+	if p.accept("(") {
+		c, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.errorf("expected )")
+		}
+		return c, nil
+	}
+
+	attr := p.ident()
+	if attr == "" {
+		if p.pos >= len(p.src) {
+			return nil, p.errorf("expected an attribute test")
+		}
+		return nil, p.errorf("expected an attribute, found %q", p.src[p.pos])
+	}
+	if !slices.Contains(Attributes, attr) {
+		return nil, p.errorf("unknown attribute %q (supported: %s)", attr, strings.Join(Attributes, ", "))
+	}
+	return p.test(attr, false)
+}
+
+// test parses the operator and the value of an attribute test. A bare
+// string has no operator.
+func (p *parser) test(attr string, bare bool) (*attrTest, error) {
+	t := &attrTest{attr: attr}
+
+	if !bare {
+		switch {
+		case p.accept("!="):
+			t.negated = true
+		case p.accept("="):
+		default:
+			op := p.src[p.pos:]
+			if i := strings.IndexAny(op, "\"' "); i > 0 {
+				op = op[:i]
+			}
+			return nil, p.errorf("operator %q is not supported, use = or !=", op)
+		}
+	}
+
+	value, err := p.str()
+	if err != nil {
+		return nil, err
+	}
+
+	fold := false
+	if p.pos < len(p.src) && p.src[p.pos] == '%' {
+		if !strings.HasPrefix(p.src[p.pos:], "%c") {
+			return nil, p.errorf("only the %%c flag is supported")
+		}
+		fold = true
+		p.pos += 2
+	}
+
+	// lemmas are lowercase: their tests ignore case
+	expr := "^(?:" + value + ")$"
+	if fold || attr == "lemma" {
+		expr = "(?i)" + expr
+	}
+	if t.re, err = regexp.Compile(expr); err != nil {
+		return nil, p.errorf("invalid regular expression %q: %v", value, err)
+	}
+
+	if attr == "lemma" && value != "" && regexp.QuoteMeta(value) == value {
+		t.literal = strings.ToLower(value)
+	}
+
+	return t, nil
+}
+
+// str reads a quoted string. Escapes are kept for the regular expression,
+// \" being a quote.
+func (p *parser) str() (string, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) || p.src[p.pos] != '"' && p.src[p.pos] != '\'' {
+		return "", p.errorf("expected a quoted value")
+	}
+	quote := p.src[p.pos]
+	start := p.pos
+	p.pos++
+
+	var b strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		case c == '\\' && p.pos+1 < len(p.src):
+			if p.src[p.pos+1] == quote {
+				b.WriteByte(quote)
+			} else {
+				b.WriteString(p.src[p.pos : p.pos+2])
+			}
+			p.pos += 2
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	p.pos = start
+	return "", p.errorf("unterminated string")
+}
+
+func isLetter(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
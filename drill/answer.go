@@ -0,0 +1,99 @@
+package drill
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	sent "github.com/revelaction/segrob/sentence"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Blank is one hidden word of a drilled sentence. Multi-word tokens
+// ("dámelo") are one blank with several lemmas.
+type Blank struct {
+	Text   string
+	Lemmas []string
+}
+
+// Blanks groups the hidden tokens by surface word, in sentence order.
+func Blanks(hidden []sent.Token) []Blank {
+	sorted := make([]sent.Token, len(hidden))
+	copy(sorted, hidden)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Index < sorted[j].Index })
+
+	var blanks []Blank
+	lastIdx, lastIndex := -1, -1
+	for _, t := range sorted {
+		if t.Index == lastIndex {
+			continue
+		}
+		lastIndex = t.Index
+
+		if t.Idx == lastIdx && len(blanks) > 0 {
+			last := &blanks[len(blanks)-1]
+			last.Lemmas = append(last.Lemmas, t.Lemma)
+			continue
+		}
+		lastIdx = t.Idx
+
+		blanks = append(blanks, Blank{Text: t.Text, Lemmas: []string{t.Lemma}})
+	}
+
+	return blanks
+}
+
+// Grade compares the answer, one word per blank, with the blanks. A word is
+// right if it is the hidden text or one of its lemmas, ignoring case and
+// accents. It returns the number of right words.
+func Grade(answer string, blanks []Blank) int {
+	words := strings.Fields(answer)
+
+	right := 0
+	for i, b := range blanks {
+		if i >= len(words) {
+			break
+		}
+
+		w := Fold(words[i])
+		if w == Fold(b.Text) {
+			right++
+			continue
+		}
+
+		for _, l := range b.Lemmas {
+			if w == Fold(l) {
+				right++
+				break
+			}
+		}
+	}
+
+	return right
+}
+
+// Quality maps the number of right words to a SM-2 quality: 5 when all the
+// blanks are right, 2 when only some are, 0 otherwise.
+func Quality(right, total int) int {
+	switch {
+	case total > 0 && right == total:
+		return 5
+	case right > 0:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Fold returns s lowercased and without diacritics, so that "tomaría",
+// "tomaria" and "Tomaría" compare equal.
+func Fold(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+	return strings.ToLower(folded)
+}
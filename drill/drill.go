@@ -0,0 +1,158 @@
+package drill
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/revelaction/segrob/match"
+	"github.com/revelaction/segrob/render"
+	"github.com/revelaction/segrob/storage"
+
+	prompt "github.com/c-bata/go-prompt"
+)
+
+type cardKey struct {
+	docID      string
+	sentenceID int
+}
+
+type Handler struct {
+	DrillRepo storage.DrillRepository
+	Renderer  *render.CLIRenderer
+	UserID    string
+
+	// NewCards is the maximum number of sentences never drilled before
+	// that are added to a session, after the due ones.
+	NewCards int
+}
+
+func NewHandler(dr storage.DrillRepository, r *render.CLIRenderer, userID string, newCards int) *Handler {
+	return &Handler{
+		DrillRepo: dr,
+		Renderer:  r,
+		UserID:    userID,
+		NewCards:  newCards,
+	}
+}
+
+// Run drills the given matches: every sentence is shown with its matched
+// words masked and the user types the hidden words (text or lemma). The
+// answer updates the score and the schedule of the sentence.
+func (h *Handler) Run(ctx context.Context, matches []*match.SentenceMatch) error {
+	now := time.Now()
+
+	stored, err := h.DrillRepo.ReadAll(ctx, h.UserID)
+	if err != nil {
+		return err
+	}
+
+	cards := make(map[cardKey]storage.DrillCard, len(stored))
+	for _, c := range stored {
+		cards[cardKey{c.DocID, c.SentenceID}] = c
+	}
+
+	queue, nextDue := h.queue(matches, cards, now)
+	if len(queue) == 0 {
+		if nextDue.IsZero() {
+			_, _ = fmt.Println("Nothing to drill.")
+		} else {
+			_, _ = fmt.Printf("Nothing to drill. Next review: %s\n", nextDue.Local().Format(time.DateTime))
+		}
+		return nil
+	}
+
+	_, _ = fmt.Printf("🔑 %d sentences. Type the hidden words (text or lemma), Enter to reveal, 🔧 quit\n", len(queue))
+
+	history := []string{}
+	session, passed := 0, 0
+	for i, sm := range queue {
+		key := cardKey{sm.Sentence.DocId, sm.Sentence.SentenceId}
+		card, ok := cards[key]
+		if !ok {
+			card = NewCard(key.docID, key.sentenceID, now)
+		}
+
+		hidden := sm.AllTokens()
+		blanks := Blanks(hidden)
+
+		_, _ = fmt.Printf("\n[%d/%d] %s (%d)\n", i+1, len(queue), h.Renderer.SentenceBlindedString(sm.Sentence.Tokens, hidden), len(blanks))
+
+		in := prompt.Input("      ✏️  ", func(prompt.Document) []prompt.Suggest { return nil },
+			prompt.OptionTitle("segrob drill"),
+			prompt.OptionPrefixTextColor(prompt.Yellow),
+			prompt.OptionHistory(history),
+		)
+
+		if in == "quit" {
+			break
+		}
+		history = append(history, in)
+
+		right := Grade(in, blanks)
+		quality := Quality(right, len(blanks))
+		card = Schedule(card, quality, time.Now())
+
+		if err := h.DrillRepo.Upsert(ctx, h.UserID, card); err != nil {
+			return err
+		}
+
+		session++
+		mark := "❌"
+		if quality >= passQuality {
+			passed++
+			mark = "✅"
+		}
+
+		var solutions []string
+		for _, b := range blanks {
+			solutions = append(solutions, b.Text+" = "+strings.Join(b.Lemmas, "+"))
+		}
+
+		_, _ = fmt.Printf("%s %d/%d %s\n", mark, right, len(blanks), h.Renderer.SentenceString(sm.Sentence.Tokens, hidden))
+		_, _ = fmt.Printf("   %s · next in %d days · %d✅ %d❌\n", strings.Join(solutions, "; "), card.Interval, card.Correct, card.Wrong)
+	}
+
+	_, _ = fmt.Printf("\nScore: %d/%d\n", passed, session)
+	return nil
+}
+
+// queue returns the sentences to drill now: first the due ones, most
+// overdue first, then up to NewCards sentences never drilled, in random
+// order. It also returns the earliest due date of the sentences left out.
+func (h *Handler) queue(matches []*match.SentenceMatch, cards map[cardKey]storage.DrillCard, now time.Time) ([]*match.SentenceMatch, time.Time) {
+	var due, fresh []*match.SentenceMatch
+	var nextDue time.Time
+	for _, sm := range matches {
+		c, ok := cards[cardKey{sm.Sentence.DocId, sm.Sentence.SentenceId}]
+		if !ok {
+			fresh = append(fresh, sm)
+			continue
+		}
+
+		if !c.Due.After(now) {
+			due = append(due, sm)
+			continue
+		}
+
+		if nextDue.IsZero() || c.Due.Before(nextDue) {
+			nextDue = c.Due
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		ci := cards[cardKey{due[i].Sentence.DocId, due[i].Sentence.SentenceId}]
+		cj := cards[cardKey{due[j].Sentence.DocId, due[j].Sentence.SentenceId}]
+		return ci.Due.Before(cj.Due)
+	})
+
+	rand.Shuffle(len(fresh), func(i, j int) { fresh[i], fresh[j] = fresh[j], fresh[i] })
+	if len(fresh) > h.NewCards {
+		fresh = fresh[:h.NewCards]
+	}
+
+	return append(due, fresh...), nextDue
+}
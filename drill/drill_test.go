@@ -0,0 +1,84 @@
+package drill
+
+import (
+	"testing"
+	"time"
+
+	sent "github.com/revelaction/segrob/sentence"
+)
+
+func TestFold(t *testing.T) {
+	cases := map[string]string{
+		"tomaría":  "tomaria",
+		"Tomaría":  "tomaria",
+		"DÁMELO":   "damelo",
+		"pingüino": "pinguino",
+	}
+
+	for in, want := range cases {
+		if got := Fold(in); got != want {
+			t.Fatalf("Fold(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestGrade(t *testing.T) {
+	hidden := []sent.Token{
+		{Index: 4, Idx: 20, Text: "mano", Lemma: "mano"},
+		{Index: 1, Idx: 1, Text: "Dámelo", Lemma: "dar"},
+		{Index: 2, Idx: 1, Text: "Dámelo", Lemma: "yo"},
+	}
+
+	blanks := Blanks(hidden)
+	if len(blanks) != 2 {
+		t.Fatalf("expected 2 blanks, got %d", len(blanks))
+	}
+
+	if blanks[0].Text != "Dámelo" || len(blanks[0].Lemmas) != 2 {
+		t.Fatalf("unexpected first blank %+v", blanks[0])
+	}
+
+	if got := Grade("damelo mano", blanks); got != 2 {
+		t.Fatalf("expected 2 right words, got %d", got)
+	}
+
+	if got := Grade("DAR pie", blanks); got != 1 {
+		t.Fatalf("expected 1 right word, got %d", got)
+	}
+
+	if got := Grade("", blanks); got != 0 {
+		t.Fatalf("expected 0 right words, got %d", got)
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewCard("doc", 3, now)
+
+	c = Schedule(c, 5, now)
+	if c.Interval != 1 || c.Reps != 1 || c.Correct != 1 {
+		t.Fatalf("unexpected card after first review: %+v", c)
+	}
+
+	c = Schedule(c, 5, now)
+	if c.Interval != 6 {
+		t.Fatalf("expected interval 6, got %d", c.Interval)
+	}
+
+	c = Schedule(c, 5, now)
+	if c.Interval <= 6 || !c.Due.Equal(now.AddDate(0, 0, c.Interval)) {
+		t.Fatalf("unexpected card after third review: %+v", c)
+	}
+
+	c = Schedule(c, 0, now)
+	if c.Interval != 1 || c.Reps != 0 || c.Wrong != 1 {
+		t.Fatalf("unexpected card after failed review: %+v", c)
+	}
+
+	for range 20 {
+		c = Schedule(c, 0, now)
+	}
+	if c.Ease < minEase {
+		t.Fatalf("ease %f below minimum", c.Ease)
+	}
+}
@@ -0,0 +1,61 @@
+package drill
+
+import (
+	"math"
+	"time"
+
+	"github.com/revelaction/segrob/storage"
+)
+
+const (
+	defaultEase = 2.5
+	minEase     = 1.3
+
+	// passQuality is the minimum SM-2 quality of a successful review
+	passQuality = 3
+)
+
+// NewCard returns the drill card of a sentence never drilled before. It is
+// due immediately.
+func NewCard(docID string, sentenceID int, now time.Time) storage.DrillCard {
+	return storage.DrillCard{
+		DocID:      docID,
+		SentenceID: sentenceID,
+		Ease:       defaultEase,
+		Due:        now,
+	}
+}
+
+// Schedule applies the SM-2 algorithm to the card after a review of the
+// given quality (0-5) and returns the updated card.
+//
+// A successful review (quality >= 3) grows the interval: 1 day, 6 days and
+// then the previous interval times the easiness factor. A failed review
+// resets the repetitions and the card is due again the next day. The
+// easiness factor is adjusted by the quality in both cases.
+func Schedule(c storage.DrillCard, quality int, now time.Time) storage.DrillCard {
+	quality = max(0, min(5, quality))
+
+	if quality >= passQuality {
+		switch c.Reps {
+		case 0:
+			c.Interval = 1
+		case 1:
+			c.Interval = 6
+		default:
+			c.Interval = int(math.Round(float64(c.Interval) * c.Ease))
+		}
+		c.Reps++
+		c.Correct++
+	} else {
+		c.Reps = 0
+		c.Interval = 1
+		c.Wrong++
+	}
+
+	q := float64(5 - quality)
+	c.Ease = max(minEase, c.Ease+0.1-q*(0.08+q*0.02))
+	c.Due = now.AddDate(0, 0, c.Interval)
+
+	return c
+}
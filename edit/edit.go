@@ -1,10 +1,14 @@
 package edit
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"os"
+	"os/signal"
 	"strings"
 
+	"github.com/revelaction/segrob/history"
 	"github.com/revelaction/segrob/storage"
 	"github.com/revelaction/segrob/topic"
 
@@ -21,6 +25,10 @@ type Handler struct {
 
 	TopicReader storage.TopicReader
 	TopicWriter storage.TopicWriter
+
+	// HistoryPath is the file the REPL history is persisted to. Empty
+	// keeps the history in memory only.
+	HistoryPath string
 }
 
 func NewHandler(l topic.Library, r storage.TopicReader, w storage.TopicWriter) *Handler {
@@ -31,12 +39,24 @@ func NewHandler(l topic.Library, r storage.TopicReader, w storage.TopicWriter) *
 	}
 }
 
-func (h *Handler) Run() error {
+func (h *Handler) Run(ctx context.Context) error {
+
+	// A Ctrl+C interrupts the running write only, the REPL goes on: the
+	// cancellation of the parent context is not inherited.
+	ctx = context.WithoutCancel(ctx)
 
 	_, _ = fmt.Println("🔑 Ctrl+L: clear, 🔧 quit")
 
 	// initialize prompt history
-	history := []string{}
+	hist := []string{}
+	if h.HistoryPath != "" {
+		loaded, err := history.Load(h.HistoryPath)
+		if err != nil {
+			_, _ = fmt.Printf("❌ Error loading history: %v\n", err)
+		} else {
+			hist = loaded
+		}
+	}
 
 	for {
 
@@ -48,14 +68,20 @@ func (h *Handler) Run() error {
 			prompt.OptionSelectedSuggestionBGColor(prompt.LightGray),
 			prompt.OptionSuggestionBGColor(prompt.DarkGray),
 			prompt.OptionMaxSuggestion(12),
-			prompt.OptionHistory(history),
+			prompt.OptionHistory(hist),
 		)
 
 		if in == "quit" {
 			return nil
 		}
 
-		history = append(history, in)
+		hist = append(hist, in)
+		if h.HistoryPath != "" {
+			if err := history.Append(h.HistoryPath, in); err != nil {
+				_, _ = fmt.Printf("❌ Error saving history: %v\n", err)
+			}
+		}
+
 		tp, expr, action, err := h.parse(in)
 		if err != nil {
 			_, _ = fmt.Printf("❌ %s\n", err)
@@ -80,7 +106,11 @@ func (h *Handler) Run() error {
 			tp = removeExprFromTopic(tp, expr)
 		}
 
-		updated, werr := h.TopicWriter.Upsert("", tp, nil)
+		updated, werr := h.upsert(ctx, tp)
+		if errors.Is(werr, context.Canceled) {
+			_, _ = fmt.Println("⏹  Write interrupted")
+			continue
+		}
 		if werr != nil {
 			return werr
 		}
@@ -96,6 +126,14 @@ func (h *Handler) Run() error {
 	}
 }
 
+// upsert writes the topic under a context of its own, canceled by Ctrl+C.
+func (h *Handler) upsert(ctx context.Context, tp topic.Topic) (topic.Topic, error) {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	return h.TopicWriter.Upsert(ctx, "", tp, nil)
+}
+
 func (h *Handler) completer() func(in prompt.Document) []prompt.Suggest {
 	return func(in prompt.Document) []prompt.Suggest {
 
@@ -0,0 +1,112 @@
+// Package history persists the input history of the interactive REPLs
+// (query, edit) to a plain text file, one entry per line.
+package history
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MaxEntries is the number of most recent entries loaded from a history
+// file.
+const MaxEntries = 1000
+
+// Path returns the history file of the given REPL and user, under the
+// segrob directory of the user configuration directory. The default user
+// ("") has its own file.
+func Path(repl string, userID string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	name := repl + "_history"
+	if userID != "" {
+		name = repl + "_history_" + userID
+	}
+
+	return filepath.Join(dir, "segrob", name), nil
+}
+
+// Load returns the last MaxEntries entries of the history file. A missing
+// file is an empty history.
+func Load(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		entries = append(entries, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(entries) > MaxEntries {
+		entries = entries[len(entries)-MaxEntries:]
+	}
+
+	return entries, nil
+}
+
+// Append adds an entry at the end of the history file, creating the file
+// and its directory if needed. Empty entries are ignored. The file keeps
+// only the last MaxEntries entries: it is rewritten through a temporary
+// file, so that a failed write leaves the old history intact.
+func Append(path string, entry string) (err error) {
+	entry = strings.TrimSpace(strings.ReplaceAll(entry, "\n", " "))
+	if entry == "" {
+		return nil
+	}
+
+	entries, err := Load(path)
+	if err != nil {
+		return err
+	}
+
+	entries = append(entries, entry)
+	if len(entries) > MaxEntries {
+		entries = entries[len(entries)-MaxEntries:]
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	w := bufio.NewWriter(f)
+	for _, e := range entries {
+		_, _ = fmt.Fprintln(w, e)
+	}
+	if err = errors.Join(w.Flush(), f.Close()); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,69 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAppendLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "segrob", "query_history")
+
+	entries, err := Load(path)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Load of a missing file = %q, %v, want an empty history", entries, err)
+	}
+
+	for _, e := range []string{"dar", "  ", "tomar\nmano", "quit"} {
+		if err := Append(path, e); err != nil {
+			t.Fatalf("Append(%q): %v", e, err)
+		}
+	}
+
+	entries, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := []string{"dar", "tomar mano", "quit"}
+	if !slices.Equal(entries, want) {
+		t.Fatalf("Load = %q, want %q", entries, want)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("history file mode = %o, want 600", perm)
+	}
+}
+
+func TestAppendTruncates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "edit_history")
+
+	for i := range MaxEntries + 5 {
+		if err := Append(path, fmt.Sprintf("expr %d", i)); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != MaxEntries {
+		t.Fatalf("history file has %d lines, want %d", len(lines), MaxEntries)
+	}
+	if lines[0] != "expr 5" || lines[len(lines)-1] != fmt.Sprintf("expr %d", MaxEntries+4) {
+		t.Fatalf("history file spans %q to %q, want the last %d entries", lines[0], lines[len(lines)-1], MaxEntries)
+	}
+
+	files, err := os.ReadDir(filepath.Dir(path))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("directory has %d files (%v), want only the history", len(files), err)
+	}
+}
@@ -0,0 +1,240 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/revelaction/segrob/render"
+	"github.com/revelaction/segrob/storage"
+	"github.com/revelaction/segrob/topic"
+
+	"github.com/c-bata/go-prompt"
+)
+
+// REPL meta-commands. They start with a colon so they never clash with a
+// topic name or an expression.
+var commands = []prompt.Suggest{
+	{Text: ":next", Description: "Show the next page of results"},
+	{Text: ":prev", Description: "Show the previous page of results"},
+	{Text: ":more", Description: "Resume the scan from where it stopped"},
+	{Text: ":page", Description: "Set the results per page"},
+	{Text: ":labels", Description: "Set the label filter (no argument clears it)"},
+	{Text: ":limit", Description: "Set the candidates fetched per load (0 = unlimited)"},
+	{Text: ":format", Description: "Set the output format"},
+	{Text: ":save", Description: "Append the last ad-hoc expression to a topic"},
+	{Text: ":export", Description: "Write the last results to a JSONL file"},
+	{Text: ":help", Description: "Show the commands"},
+}
+
+// exportRecord is one line of the :export JSONL file.
+type exportRecord struct {
+	DocId      string     `json:"doc_id"`
+	SentenceId int        `json:"sentence_id"`
+	Topic      string     `json:"topic,omitempty"`
+	Expr       string     `json:"expr"`
+	Text       string     `json:"text"`
+	Matches    [][]string `json:"matches"`
+}
+
+// runCommand executes a colon command line.
+func (h *Handler) runCommand(ctx context.Context, in string) error {
+	fields := strings.Fields(in)
+	name, args := fields[0], fields[1:]
+
+	switch name {
+	case ":next", ":n":
+		return h.nextPage(ctx)
+
+	case ":prev", ":p":
+		return h.prevPage()
+
+	case ":more":
+		return h.more(ctx)
+
+	case ":page":
+		if len(args) != 1 {
+			return errors.New("usage: :page N")
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid page size: %s", args[0])
+		}
+		h.PageSize = n
+		if h.scan != nil {
+			h.scan.page = 0
+		}
+		fmt.Printf("Page size set to: %d\n", n)
+		return nil
+
+	case ":labels":
+		h.Labels = args
+		if len(args) == 0 {
+			fmt.Println("Labels cleared")
+			return nil
+		}
+		fmt.Println("Labels set to: " + strings.Join(args, " "))
+		return nil
+
+	case ":limit":
+		if len(args) != 1 {
+			return errors.New("usage: :limit N")
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid limit: %s", args[0])
+		}
+		h.Limit = n
+		fmt.Printf("Limit set to: %d\n", n)
+		return nil
+
+	case ":format":
+		supported := render.SupportedFormats()
+		if len(args) != 1 || !slices.Contains(supported, args[0]) {
+			return fmt.Errorf("usage: :format {%s}", strings.Join(supported, "|"))
+		}
+		h.Renderer.Format = args[0]
+		fmt.Println("Format set to: " + h.Renderer.Format)
+		return nil
+
+	case ":save":
+		if len(args) != 1 {
+			return errors.New("usage: :save <topic>")
+		}
+		return h.save(ctx, args[0])
+
+	case ":export":
+		if len(args) != 1 {
+			return errors.New("usage: :export <file.jsonl>")
+		}
+		return h.export(args[0])
+
+	case ":help":
+		for _, c := range commands {
+			fmt.Printf("  %-10s %s\n", c.Text, c.Description)
+		}
+		fmt.Printf("  %-10s %s\n", "quit", "Exit")
+		return nil
+	}
+
+	return fmt.Errorf("unknown command %s, try :help", name)
+}
+
+// save appends the last ad-hoc expression to the topic, creating the topic
+// if it does not exist.
+func (h *Handler) save(ctx context.Context, name string) error {
+	if h.scan != nil && h.scan.pattern != nil {
+		return errors.New("CQL queries can not be saved to a topic")
+	}
+	if len(h.lastExpr.Items) == 0 {
+		return errors.New("no ad-hoc expression to save, run a query first")
+	}
+
+	expr := h.lastExpr
+	updated, err := h.TopicWriter.Upsert(ctx, h.UserID, topic.Topic{Name: name}, func(t topic.Topic) (topic.Topic, error) {
+		for _, e := range t.Exprs {
+			if topic.EqualExpr(e, expr) {
+				return t, storage.ErrNoChange
+			}
+		}
+		t.Exprs = append(t.Exprs, expr)
+		return t, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for i, t := range h.TopicLibrary {
+		if t.Name == updated.Name {
+			h.TopicLibrary[i] = updated
+			found = true
+			break
+		}
+	}
+	if !found {
+		h.TopicLibrary = append(h.TopicLibrary, updated)
+	}
+
+	fmt.Printf("✅ Topic %s has %d expressions\n", updated.Name, len(updated.Exprs))
+	return nil
+}
+
+// export writes the last results as JSON lines.
+func (h *Handler) export(path string) (err error) {
+	if h.scan == nil {
+		return errors.New("no results to export, run a query first")
+	}
+	results := h.scan.results
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, f.Close())
+	}()
+
+	r := render.NewCLIRenderer()
+	r.HasColor = false
+
+	enc := json.NewEncoder(f)
+	for _, sm := range results {
+		rec := exportRecord{
+			DocId:      sm.Sentence.DocId,
+			SentenceId: sm.Sentence.SentenceId,
+			Topic:      sm.TopicName,
+			Expr:       sm.Expr,
+			Text:       r.SentenceString(sm.Sentence.Tokens, nil),
+			Matches:    [][]string{},
+		}
+		for _, chain := range sm.Tokens {
+			var words []string
+			for _, t := range chain {
+				words = append(words, t.Text)
+			}
+			rec.Matches = append(rec.Matches, words)
+		}
+
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("✅ %d results written to %s\n", len(results), path)
+	return nil
+}
+
+func (h *Handler) completeCommand(befCursor string) (s []prompt.Suggest) {
+	fields := strings.Fields(befCursor)
+	if len(fields) == 1 && !strings.HasSuffix(befCursor, " ") {
+		return prompt.FilterHasPrefix(commands, fields[0], false)
+	}
+
+	if fields[0] == ":format" {
+		word := ""
+		if len(fields) > 1 && !strings.HasSuffix(befCursor, " ") {
+			word = fields[len(fields)-1]
+		}
+		for _, f := range render.SupportedFormats() {
+			if strings.HasPrefix(f, word) {
+				s = append(s, prompt.Suggest{Text: f})
+			}
+		}
+	}
+
+	if fields[0] == ":save" {
+		word := ""
+		if len(fields) > 1 && !strings.HasSuffix(befCursor, " ") {
+			word = fields[len(fields)-1]
+		}
+		s = append(s, h.completeTopic(word)...)
+	}
+
+	return s
+}
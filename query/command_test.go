@@ -0,0 +1,105 @@
+package query
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/revelaction/segrob/match"
+	"github.com/revelaction/segrob/render"
+	sent "github.com/revelaction/segrob/sentence"
+)
+
+// scanned returns a handler with an exhausted scan of n results.
+func scanned(n int) *Handler {
+	h := NewHandler(nil, nil, nil, render.NewCLIRenderer(), nil, "")
+	h.scan = &scan{exhausted: true}
+	for i := range n {
+		tok := sent.Token{Text: "dijo", Lemma: "decir"}
+		h.scan.results = append(h.scan.results, &match.SentenceMatch{
+			Tokens:   [][]sent.Token{{tok}},
+			Sentence: sent.Sentence{SentenceId: i, DocId: "doc1", Tokens: []sent.Token{tok}},
+		})
+	}
+	return h
+}
+
+func TestPaging(t *testing.T) {
+	ctx := context.Background()
+	h := scanned(5)
+
+	steps := []struct {
+		cmd  string
+		err  string
+		page int
+	}{
+		{":page 2", "", 0},
+		{":next", "", 1},
+		{":n", "", 2},
+		{":next", "no more results", 2},
+		{":prev", "", 1},
+		{":p", "", 0},
+		{":prev", "already at the first page", 0},
+		{":next", "", 1},
+		{":page 3", "", 0}, // a new page size shows the first page
+		{":next", "", 1},
+		{":next", "no more results", 1},
+	}
+
+	for _, s := range steps {
+		err := h.runCommand(ctx, s.cmd)
+		if s.err == "" && err != nil {
+			t.Fatalf("%s: %v", s.cmd, err)
+		}
+		if s.err != "" && (err == nil || !strings.Contains(err.Error(), s.err)) {
+			t.Fatalf("%s: error %v, want %q", s.cmd, err, s.err)
+		}
+		if h.scan.page != s.page {
+			t.Fatalf("%s: page %d, want %d", s.cmd, h.scan.page, s.page)
+		}
+	}
+
+	h.scan = nil
+	for _, cmd := range []string{":next", ":prev"} {
+		if err := h.runCommand(ctx, cmd); err == nil || !strings.Contains(err.Error(), "no query") {
+			t.Fatalf("%s without a query: error %v, want \"no query\"", cmd, err)
+		}
+	}
+}
+
+func TestPageAndLimitArgs(t *testing.T) {
+	ctx := context.Background()
+	h := scanned(0)
+
+	cases := []struct {
+		cmd   string
+		err   string
+		page  int
+		limit int
+	}{
+		{":page 7", "", 7, DefaultLimit},
+		{":page", "usage: :page N", 7, DefaultLimit},
+		{":page 1 2", "usage: :page N", 7, DefaultLimit},
+		{":page 0", "invalid page size: 0", 7, DefaultLimit},
+		{":page x", "invalid page size: x", 7, DefaultLimit},
+		{":limit 0", "", 7, 0},
+		{":limit 50", "", 7, 50},
+		{":limit", "usage: :limit N", 7, 50},
+		{":limit -1", "invalid limit: -1", 7, 50},
+		{":limit ten", "invalid limit: ten", 7, 50},
+		{":pages 3", "unknown command :pages", 7, 50},
+	}
+
+	for _, c := range cases {
+		err := h.runCommand(ctx, c.cmd)
+		if c.err == "" && err != nil {
+			t.Fatalf("%s: %v", c.cmd, err)
+		}
+		if c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)) {
+			t.Fatalf("%s: error %v, want %q", c.cmd, err, c.err)
+		}
+		if h.PageSize != c.page || h.Limit != c.limit {
+			t.Fatalf("%s: page size %d and limit %d, want %d and %d", c.cmd, h.PageSize, h.Limit, c.page, c.limit)
+		}
+	}
+}
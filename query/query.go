@@ -1,55 +1,99 @@
 package query
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"sort"
 	"strings"
 
-	"github.com/revelaction/segrob/match"
+	"github.com/revelaction/segrob/history"
 	"github.com/revelaction/segrob/render"
-	sent "github.com/revelaction/segrob/sentence"
 	"github.com/revelaction/segrob/topic"
 
 	"github.com/c-bata/go-prompt"
 	"github.com/revelaction/segrob/storage"
 )
 
+// DefaultLimit is the default maximum number of candidates fetched per
+// load.
+const DefaultLimit = 2000
+
+// DefaultPageSize is the default number of results shown per page.
+const DefaultPageSize = 20
+
+// cqlPrefix introduces a CQL query instead of a topic or an expression.
+const cqlPrefix = "cql:"
+
 type Handler struct {
 	DocRepo      storage.DocReader
+	TopicWriter  storage.TopicWriter
 	TopicLibrary topic.Library
 	Renderer     *render.CLIRenderer
 	Labels       []string
+
+	// UserID owns the topics saved with :save
+	UserID string
+
+	// Limit is the maximum number of candidates fetched per load: a new
+	// query or a :more
+	Limit int
+
+	// HistoryPath is the file the REPL history is persisted to. Empty
+	// keeps the history in memory only.
+	HistoryPath string
+
+	// PageSize is the number of results shown per page
+	PageSize int
+
+	// last ad-hoc expression, for :save
+	lastExpr topic.TopicExpr
+
+	// scan of the last query: its results are paged and exported
+	scan *scan
 }
 
-func NewHandler(dr storage.DocReader, tl topic.Library, r *render.CLIRenderer, labels []string) *Handler {
+func NewHandler(dr storage.DocReader, tw storage.TopicWriter, tl topic.Library, r *render.CLIRenderer, labels []string, userID string) *Handler {
 	return &Handler{
 		DocRepo:      dr,
+		TopicWriter:  tw,
 		TopicLibrary: tl,
 		Renderer:     r,
 		Labels:       labels,
+		UserID:       userID,
+		Limit:        DefaultLimit,
+		PageSize:     DefaultPageSize,
 	}
 }
 
-func (h *Handler) Run() error {
+func (h *Handler) Run(ctx context.Context) error {
 
-	fmt.Println("🔑 Ctrl+X: Toggle prefix, Ctrl+F: next Format, 🔧 quit")
-	// Get all topics from the library directly
-	topicNames := h.TopicLibrary.Names()
+	// Ctrl+C interrupts the running scan only, the REPL goes on: the
+	// cancellation of the parent context is not inherited.
+	ctx = context.WithoutCancel(ctx)
+
+	fmt.Println("🔑 Ctrl+X: Toggle prefix, Ctrl+F: next Format, Ctrl+C: stop a scan, :help commands, cql: queries, 🔧 quit")
 
 	// initialize prompt history
-	history := []string{}
+	hist := []string{}
+	if h.HistoryPath != "" {
+		loaded, err := history.Load(h.HistoryPath)
+		if err != nil {
+			fmt.Printf("❌ Error loading history: %v\n", err)
+		} else {
+			hist = loaded
+		}
+	}
 
 	for {
 
-		in := prompt.Input("      🔖 ", h.completer(topicNames),
+		in := prompt.Input("      🔖 ", h.completer(),
 			prompt.OptionTitle("segrob query"),
 			prompt.OptionPrefixTextColor(prompt.Yellow),
 			prompt.OptionPreviewSuggestionTextColor(prompt.Blue),
 			prompt.OptionSelectedSuggestionBGColor(prompt.LightGray),
 			prompt.OptionMaxSuggestion(12),
 			prompt.OptionSuggestionBGColor(prompt.DarkGray),
-			prompt.OptionHistory(history),
+			prompt.OptionHistory(hist),
 			prompt.OptionAddKeyBind(prompt.KeyBind{
 				Key: prompt.ControlF,
 				Fn: func(buf *prompt.Buffer) {
@@ -68,146 +112,55 @@ func (h *Handler) Run() error {
 			return nil
 		}
 
-		history = append(history, in)
-		// return the topic
-		tp, expr, err := h.parse(in)
-		if err != nil {
+		if strings.TrimSpace(in) == "" {
 			continue
 		}
 
-		// Build one matcher per expression
-		var matchers []*match.Matcher
-		for _, e := range tp.Exprs {
-			matchers = append(matchers, match.NewMatcher(e))
-		}
-
-		// If there is an ArgExpr, add its matcher
-		var argMatcher *match.Matcher
-		if len(expr.Items) > 0 {
-			argMatcher = match.NewMatcher(expr)
+		hist = append(hist, in)
+		if h.HistoryPath != "" {
+			if err := history.Append(h.HistoryPath, in); err != nil {
+				fmt.Printf("❌ Error saving history: %v\n", err)
+			}
 		}
 
-		// Fetch doc names for rendering
-		docList, err := h.DocRepo.List()
-		if err != nil {
-			fmt.Printf("Error listing docs: %v\n", err)
+		if strings.HasPrefix(in, ":") {
+			if err := h.runCommand(ctx, in); err != nil {
+				fmt.Printf("❌ %s\n", err)
+			}
 			continue
 		}
-		docNames := make(map[string]string)
-		for _, d := range docList {
-			docNames[d.Id] = d.Source
-		}
 
-		// Resolve labels to IDs
-		var labelIDs []int
-		if len(h.Labels) > 0 {
-			allLabels, err := h.DocRepo.ListLabels("")
+		var (
+			sc   *scan
+			expr topic.TopicExpr
+			err  error
+		)
+		if q, ok := strings.CutPrefix(in, cqlPrefix); ok {
+			sc, err = h.newCQLScan(ctx, q)
+		} else {
+			// return the topic
+			var tp topic.Topic
+			tp, expr, err = h.parse(in)
 			if err != nil {
-				fmt.Printf("Error listing labels: %v\n", err)
 				continue
 			}
-
-			for _, name := range h.Labels {
-				if id, ok := allLabels[name]; ok {
-					labelIDs = append(labelIDs, id)
-				}
-			}
-		}
-
-		// Extract lemmas from all relevant expressions (OR logic) for indexed retrieval.
-		// We only extract positive lemmas to find candidates in the database.
-		// Fine-grained matching (including negative '!' lemmas) is performed
-		// by the Matcher on the retrieved candidates.
-		var queries [][]string
-		for _, e := range tp.Exprs {
-			lemmas := e.Lemmas()
-			if len(lemmas) > 0 {
-				queries = append(queries, lemmas)
-			}
+			sc, err = h.newScan(ctx, tp, expr)
 		}
-		lemmas := expr.Lemmas()
-		if len(lemmas) > 0 {
-			queries = append(queries, lemmas)
-		}
-
-		limit := 2000 // Limit candidates per expression to avoid hang
-
-		var results []*match.SentenceMatch
-
-		for _, lemmas := range queries {
-			cursor := storage.Cursor(0)
-			fetched := 0
-			// doc := sent.Doc{Tokens: make([][]sent.Token, 1)} // No longer needed
-			for {
-				// Fetch batch
-				newCursor, err := h.DocRepo.FindCandidates(lemmas, labelIDs, cursor, 500, func(s sent.Sentence) error {
-					fetched++
-					h.Renderer.AddDocName(s.DocId, docNames[s.DocId])
-
-					// Use MatchSentence directly to avoid "Tártaro" bug (overwrite due to missing SentenceId)
-					sm := matchWithMatchers(s, tp.Name, matchers, argMatcher)
-					if sm != nil {
-						results = append(results, sm)
-					}
-					return nil
-				})
-				if err != nil {
-					fmt.Printf("Error fetching candidates: %v\n", err)
-					break
-				}
-				if cursor == newCursor {
-					break // No more progress
-				}
-
-				if fetched >= limit {
-					break
-				}
-				cursor = newCursor
-			}
-		}
-
-		// Sort results to match previous behavior (DocID > SentenceID)
-		// We implement the sort here since we bypassed matcher.Sentences()
-		sort.Slice(results, func(i, j int) bool {
-			if results[i].Sentence.DocId != results[j].Sentence.DocId {
-				return results[i].Sentence.DocId < results[j].Sentence.DocId
-			}
-			return results[i].Sentence.SentenceId < results[j].Sentence.SentenceId
-		})
-
-		h.Renderer.Render(results)
-	}
-}
-
-// matchWithMatchers applies the ArgExpr AND gate, then tries each topic
-// expression matcher (OR). Returns nil if no match.
-// Sets TopicName on the returned SentenceMatch.
-func matchWithMatchers(s sent.Sentence, topicName string, matchers []*match.Matcher, argMatcher *match.Matcher) *match.SentenceMatch {
-	// ArgExpr AND gate
-	if argMatcher != nil {
-		if argMatcher.MatchSentence(s) == nil {
-			return nil
+		if err != nil {
+			fmt.Printf("❌ %s\n", err)
+			continue
 		}
-	}
 
-	// Topic expressions OR — return the first match
-	for _, m := range matchers {
-		sm := m.MatchSentence(s)
-		if sm != nil {
-			sm.TopicName = topicName
-			return sm
-		}
-	}
+		h.lastExpr = expr
+		h.scan = sc
 
-	// No topic, no argMatcher — should not happen, but guard
-	if len(matchers) == 0 && argMatcher != nil {
-		return argMatcher.MatchSentence(s)
+		// Only the first page is fetched, the rest is loaded on demand
+		h.fetch(ctx, h.PageSize, h.Limit)
+		h.showPage()
 	}
-
-	return nil
 }
 
-func (h *Handler) completer(topicNames []string) func(in prompt.Document) []prompt.Suggest {
+func (h *Handler) completer() func(in prompt.Document) []prompt.Suggest {
 	return func(in prompt.Document) []prompt.Suggest {
 
 		s := []prompt.Suggest{}
@@ -218,6 +171,14 @@ func (h *Handler) completer(topicNames []string) func(in prompt.Document) []prom
 			return s
 		}
 
+		if strings.HasPrefix(befCursor, ":") {
+			return h.completeCommand(befCursor)
+		}
+
+		if strings.HasPrefix(befCursor, cqlPrefix) {
+			return s
+		}
+
 		tokens := strings.Split(befCursor, " ")
 		firstToken := tokens[0]
 
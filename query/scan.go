@@ -0,0 +1,213 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/revelaction/segrob/cql"
+	"github.com/revelaction/segrob/match"
+	"github.com/revelaction/segrob/search"
+	"github.com/revelaction/segrob/storage"
+	"github.com/revelaction/segrob/topic"
+)
+
+// scan is the state of a query: the search options, the saved cursor and
+// the results loaded so far. Loading more results resumes from the saved
+// cursor instead of restarting the search.
+type scan struct {
+	topic    topic.Topic
+	expr     topic.TopicExpr
+	pattern  search.Pattern // a CQL query, instead of topic and expr
+	labelIDs []int
+	docNames map[string]string
+
+	cursor    storage.Cursor
+	exhausted bool
+	results   []*match.SentenceMatch
+
+	// page is the 0-based page shown
+	page int
+}
+
+func (s *scan) numPages(pageSize int) int {
+	return max(1, (len(s.results)+pageSize-1)/pageSize)
+}
+
+// newScan prepares the scan of the topic and the ad-hoc expression. No
+// candidate is fetched yet.
+func (h *Handler) newScan(ctx context.Context, tp topic.Topic, expr topic.TopicExpr) (*scan, error) {
+	s := &scan{
+		topic:    tp,
+		expr:     expr,
+		docNames: map[string]string{},
+	}
+
+	// Fetch doc names for rendering
+	docList, err := h.DocRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing docs: %w", err)
+	}
+	for _, d := range docList {
+		s.docNames[d.Id] = d.Source
+	}
+
+	// Resolve labels to IDs
+	if len(h.Labels) > 0 {
+		allLabels, err := h.DocRepo.ListLabels(ctx, "")
+		if err != nil {
+			return nil, fmt.Errorf("listing labels: %w", err)
+		}
+
+		for _, name := range h.Labels {
+			if id, ok := allLabels[name]; ok {
+				s.labelIDs = append(s.labelIDs, id)
+			}
+		}
+	}
+
+	return s, nil
+}
+
+// newCQLScan prepares the scan of a CQL query.
+func (h *Handler) newCQLScan(ctx context.Context, query string) (*scan, error) {
+	q, err := cql.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := h.newScan(ctx, topic.Topic{}, topic.TopicExpr{})
+	if err != nil {
+		return nil, err
+	}
+	s.pattern = q
+	return s, nil
+}
+
+// fetch resumes the scan of the current query until it holds want results,
+// budget candidates have been examined or the candidates are exhausted. A
+// want or budget of 0 means no limit. Ctrl+C interrupts the scan; the
+// results found so far are kept.
+func (h *Handler) fetch(ctx context.Context, want int, budget int) {
+	s := h.scan
+	if s == nil || s.exhausted {
+		return
+	}
+
+	limit := 0
+	if want > 0 {
+		limit = want - len(s.results)
+		if limit <= 0 {
+			return
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	sr := search.New(h.DocRepo, search.Options{
+		Topic:    s.topic,
+		Expr:     s.expr,
+		Pattern:  s.pattern,
+		LabelIDs: s.labelIDs,
+		After:    s.cursor,
+		Limit:    limit,
+		Budget:   budget,
+	})
+
+	for sm, err := range sr.All(ctx) {
+		if errors.Is(err, context.Canceled) {
+			fmt.Println("⏹  Scan interrupted, :more resumes it")
+			break
+		}
+		if err != nil {
+			fmt.Printf("Error fetching candidates: %v\n", err)
+			break
+		}
+
+		h.Renderer.AddDocName(sm.Sentence.DocId, s.docNames[sm.Sentence.DocId])
+		s.results = append(s.results, sm)
+	}
+
+	s.cursor = sr.Cursor()
+	s.exhausted = sr.Exhausted()
+}
+
+// showPage renders the current page of the scan and a status line.
+func (h *Handler) showPage() {
+	s := h.scan
+	if s == nil {
+		fmt.Println("No query, type a topic or an expression first")
+		return
+	}
+
+	from := min(s.page*h.PageSize, len(s.results))
+	to := min(from+h.PageSize, len(s.results))
+	h.Renderer.Render(s.results[from:to])
+
+	status := fmt.Sprintf("📄 page %d/%d · %d results", s.page+1, s.numPages(h.PageSize), len(s.results))
+	if !s.exhausted {
+		status += " so far · :more loads more"
+	}
+	fmt.Println(status)
+}
+
+// nextPage shows the next page, loading the results it needs first.
+func (h *Handler) nextPage(ctx context.Context) error {
+	s := h.scan
+	if s == nil {
+		return errors.New("no query, type a topic or an expression first")
+	}
+
+	if !s.exhausted {
+		h.fetch(ctx, (s.page+2)*h.PageSize, h.Limit)
+	}
+
+	if (s.page+1)*h.PageSize >= len(s.results) {
+		return errors.New("no more results")
+	}
+
+	s.page++
+	h.showPage()
+	return nil
+}
+
+func (h *Handler) prevPage() error {
+	s := h.scan
+	if s == nil {
+		return errors.New("no query, type a topic or an expression first")
+	}
+
+	if s.page == 0 {
+		return errors.New("already at the first page")
+	}
+
+	s.page--
+	h.showPage()
+	return nil
+}
+
+// more resumes the scan for another Limit candidates and shows the page
+// with the first new result.
+func (h *Handler) more(ctx context.Context) error {
+	s := h.scan
+	if s == nil {
+		return errors.New("no query, type a topic or an expression first")
+	}
+
+	if s.exhausted {
+		return errors.New("all candidates already scanned")
+	}
+
+	before := len(s.results)
+	h.fetch(ctx, 0, h.Limit)
+	fmt.Printf("%d new results\n", len(s.results)-before)
+
+	if len(s.results) > before {
+		s.page = before / h.PageSize
+	}
+	h.showPage()
+	return nil
+}
@@ -0,0 +1,266 @@
+package render
+
+import (
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/revelaction/segrob/match"
+	sent "github.com/revelaction/segrob/sentence"
+)
+
+// numChainColors is the number of distinct highlight colors used for match
+// chains. Chains beyond this number reuse the colors cyclically.
+const numChainColors = 6
+
+// HTMLRenderer renders match results as a self-contained HTML report, meant
+// to be shared with readers that cannot use the terminal output.
+//
+// Results are grouped by document (title, creator and date are taken from
+// the document labels) and, within a document, by topic expression. Each
+// match chain in SentenceMatch.Tokens gets its own highlight color, and every
+// token shows its lemma, POS and tag when hovered.
+type HTMLRenderer struct {
+	// Title is the heading of the report
+	Title string
+
+	docs map[string]htmlDocInfo
+}
+
+type htmlDocInfo struct {
+	Source string
+	Labels []string
+}
+
+// template data
+type htmlReport struct {
+	Title    string
+	NumMatch int
+	Docs     []*htmlDoc
+}
+
+type htmlDoc struct {
+	Id      string
+	Title   string
+	Creator string
+	Date    string
+	Source  string
+	Exprs   []*htmlExpr
+	Count   int
+}
+
+type htmlExpr struct {
+	Topic     string
+	Expr      string
+	Sentences []htmlSentence
+}
+
+type htmlSentence struct {
+	Id       int
+	Segments []htmlSegment
+}
+
+// htmlSegment is one surface word of the sentence. Multi-word tokens (same
+// idx) are folded into one segment whose tooltip lists every part.
+type htmlSegment struct {
+	Space string
+	Text  string
+	Tip   string
+	Chain int // -1 when not matched
+}
+
+func NewHTMLRenderer() *HTMLRenderer {
+	return &HTMLRenderer{docs: map[string]htmlDocInfo{}}
+}
+
+// AddDoc registers the source and the label names of a document. Documents
+// not registered are rendered with their ID as title.
+func (r *HTMLRenderer) AddDoc(docId string, source string, labels []string) {
+	r.docs[docId] = htmlDocInfo{Source: source, Labels: labels}
+}
+
+// Render writes the HTML report for the given results to w.
+func (r *HTMLRenderer) Render(w io.Writer, results []*match.SentenceMatch) error {
+	report := htmlReport{Title: r.Title, NumMatch: len(results)}
+
+	docs := map[string]*htmlDoc{}
+	for _, sm := range results {
+		docId := sm.Sentence.DocId
+		d, ok := docs[docId]
+		if !ok {
+			d = r.newDoc(docId)
+			docs[docId] = d
+			report.Docs = append(report.Docs, d)
+		}
+
+		var e *htmlExpr
+		for _, candidate := range d.Exprs {
+			if candidate.Topic == sm.TopicName && candidate.Expr == sm.Expr {
+				e = candidate
+				break
+			}
+		}
+		if e == nil {
+			e = &htmlExpr{Topic: sm.TopicName, Expr: sm.Expr}
+			d.Exprs = append(d.Exprs, e)
+		}
+
+		e.Sentences = append(e.Sentences, htmlSentence{
+			Id:       sm.Sentence.SentenceId,
+			Segments: segments(sm.Sentence.Tokens, sm.Tokens),
+		})
+		d.Count++
+	}
+
+	sort.SliceStable(report.Docs, func(i, j int) bool {
+		return report.Docs[i].Title < report.Docs[j].Title
+	})
+
+	for _, d := range report.Docs {
+		sort.SliceStable(d.Exprs, func(i, j int) bool {
+			if d.Exprs[i].Topic != d.Exprs[j].Topic {
+				return d.Exprs[i].Topic < d.Exprs[j].Topic
+			}
+			return d.Exprs[i].Expr < d.Exprs[j].Expr
+		})
+		for _, e := range d.Exprs {
+			sort.SliceStable(e.Sentences, func(i, j int) bool {
+				return e.Sentences[i].Id < e.Sentences[j].Id
+			})
+		}
+	}
+
+	return htmlTemplate.Execute(w, report)
+}
+
+func (r *HTMLRenderer) newDoc(docId string) *htmlDoc {
+	info := r.docs[docId]
+	d := &htmlDoc{
+		Id:      docId,
+		Source:  info.Source,
+		Title:   labelValue(info.Labels, "title:"),
+		Creator: labelValue(info.Labels, "creator:"),
+		Date:    labelValue(info.Labels, "date:"),
+	}
+
+	if d.Title == "" {
+		d.Title = info.Source
+	}
+	if d.Title == "" {
+		d.Title = docId
+	}
+
+	return d
+}
+
+// labelValue returns the human readable value of the first label with the
+// given prefix, or "" if there is none. Normalized labels use underscores
+// for spaces, they are restored here.
+func labelValue(labels []string, prefix string) string {
+	for _, l := range labels {
+		if strings.HasPrefix(l, prefix) {
+			return strings.ReplaceAll(l[len(prefix):], "_", " ")
+		}
+	}
+	return ""
+}
+
+// segments splits the sentence into surface words, following the same
+// spacing rules as CLIRenderer: the gap between two words is derived from
+// the idx field, and tokens sharing the same idx (multi-word tokens) are
+// rendered once.
+func segments(tokens []sent.Token, chains [][]sent.Token) []htmlSegment {
+	chainOf := map[int]int{}
+	for c, chain := range chains {
+		for _, t := range chain {
+			if _, ok := chainOf[t.Id]; !ok {
+				chainOf[t.Id] = c % numChainColors
+			}
+		}
+	}
+
+	var segs []htmlSegment
+	var lastEnd int
+	for i, t := range tokens {
+		tip := tokenTip(t)
+		chain, matched := chainOf[t.Id]
+		if !matched {
+			chain = -1
+		}
+
+		if i > 0 && t.Idx == tokens[i-1].Idx {
+			last := &segs[len(segs)-1]
+			last.Tip += "\n" + tip
+			if last.Chain == -1 {
+				last.Chain = chain
+			}
+			continue
+		}
+
+		seg := htmlSegment{
+			Text:  strings.ReplaceAll(t.Text, "\n", " "),
+			Tip:   tip,
+			Chain: chain,
+		}
+		if i > 0 && t.Idx > lastEnd {
+			seg.Space = strings.Repeat(" ", t.Idx-lastEnd)
+		}
+
+		segs = append(segs, seg)
+		lastEnd = t.Idx + len([]rune(t.Text))
+	}
+
+	return segs
+}
+
+// tokenTip returns the hover text of a token: lemma, POS and tag. The POS
+// prefix of the tag ("VERB__Mood=Ind") is not repeated.
+func tokenTip(t sent.Token) string {
+	tip := t.Lemma + " · " + t.Pos
+	if tag := strings.TrimPrefix(t.Tag, t.Pos+"__"); tag != "" && tag != t.Pos {
+		tip += " · " + tag
+	}
+	return tip
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="es">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: Georgia, serif; max-width: 60em; margin: 2em auto; padding: 0 1em; color: #222; line-height: 1.5; }
+h1 { font-size: 1.6em; margin-bottom: 0.2em; }
+h2 { font-size: 1.25em; margin: 1.8em 0 0.2em; border-bottom: 1px solid #ccc; }
+h3 { font-size: 1em; font-family: monospace; margin: 1.2em 0 0.4em; color: #555; }
+.summary, .meta { color: #777; font-size: 0.9em; }
+.topic { color: #a0522d; }
+ol { padding-left: 3.5em; }
+li { margin: 0.4em 0; }
+li::marker { color: #999; font-size: 0.8em; }
+.t { white-space: pre-wrap; }
+.t[title]:hover { background: #eee; cursor: help; }
+.m { font-weight: bold; border-radius: 3px; padding: 0 1px; }
+.m0 { background: #d4edda; } .m1 { background: #fff3cd; } .m2 { background: #d1ecf1; }
+.m3 { background: #f8d7da; } .m4 { background: #e2d9f3; } .m5 { background: #fde2c8; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="summary">{{.NumMatch}} matches in {{len .Docs}} documents. Hover over a word to see its lemma, POS and tag.</p>
+{{range .Docs}}
+<h2 id="doc-{{.Id}}">{{.Title}}</h2>
+<p class="meta">{{if .Creator}}{{.Creator}}{{end}}{{if .Date}} · {{.Date}}{{end}} · {{.Count}} matches · <code>{{.Id}}</code></p>
+{{range .Exprs}}
+<h3>{{if .Topic}}<span class="topic">{{.Topic}}</span> · {{end}}{{.Expr}}</h3>
+<ol>
+{{- range .Sentences}}
+<li value="{{.Id}}">{{range .Segments}}{{.Space}}<span class="t{{if ge .Chain 0}} m m{{.Chain}}{{end}}" title="{{.Tip}}">{{.Text}}</span>{{end}}</li>
+{{- end}}
+</ol>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,104 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/revelaction/segrob/match"
+	sent "github.com/revelaction/segrob/sentence"
+)
+
+func htmlMatch(docId string, sentId int, topic, expr string, text string) *match.SentenceMatch {
+	tok := sent.Token{Id: sentId, Text: text, Lemma: strings.ToLower(text), Pos: "NOUN"}
+	return &match.SentenceMatch{
+		Tokens:    [][]sent.Token{{tok}},
+		Sentence:  sent.Sentence{SentenceId: sentId, DocId: docId, Tokens: []sent.Token{tok}},
+		Expr:      expr,
+		TopicName: topic,
+	}
+}
+
+func TestHTMLRenderGroups(t *testing.T) {
+	r := NewHTMLRenderer()
+	r.Title = "report"
+	r.AddDoc("doc2", "b.epub", []string{"title:Alpha_Book", "creator:borges"})
+
+	results := []*match.SentenceMatch{
+		htmlMatch("doc1", 7, "", "zeta", "uno"),
+		htmlMatch("doc2", 9, "t", "beta", "dos"),
+		htmlMatch("doc1", 3, "", "zeta", "tres"),
+		htmlMatch("doc2", 1, "t", "alfa", "cuatro"),
+		htmlMatch("doc1", 5, "", "eta", "cinco"),
+	}
+
+	var buf bytes.Buffer
+	if err := r.Render(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	if n := strings.Count(out, "<h2 "); n != 2 {
+		t.Fatalf("got %d documents, want 2:\n%s", n, out)
+	}
+	if n := strings.Count(out, "<h3>"); n != 4 {
+		t.Fatalf("got %d expressions, want 4:\n%s", n, out)
+	}
+	if !strings.Contains(out, "5 matches in 2 documents") {
+		t.Errorf("missing summary:\n%s", out)
+	}
+	if !strings.Contains(out, "borges · 2 matches") {
+		t.Errorf("missing creator and count of doc2:\n%s", out)
+	}
+
+	// documents by title (doc2 has a title label, doc1 its id), expressions
+	// by topic and expression, sentences by id
+	order := []string{
+		`<h2 id="doc-doc2">Alpha Book</h2>`,
+		`alfa</h3>`,
+		`<li value="1">`,
+		`beta</h3>`,
+		`<li value="9">`,
+		`<h2 id="doc-doc1">doc1</h2>`,
+		`<h3>eta</h3>`,
+		`<li value="5">`,
+		`<h3>zeta</h3>`,
+		`<li value="3">`,
+		`<li value="7">`,
+	}
+	pos := 0
+	for _, s := range order {
+		i := strings.Index(out[pos:], s)
+		if i < 0 {
+			t.Fatalf("%q not found after position %d:\n%s", s, pos, out)
+		}
+		pos += i + len(s)
+	}
+}
+
+func TestHTMLRenderEscapes(t *testing.T) {
+	r := NewHTMLRenderer()
+	r.Title = "a <i>report</i>"
+	r.AddDoc("doc1", "", []string{"title:Tom_&_Jerry"})
+
+	results := []*match.SentenceMatch{
+		htmlMatch("doc1", 0, "", `<script>alert("x")</script>`, "<b>"),
+	}
+
+	var buf bytes.Buffer
+	if err := r.Render(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, s := range []string{"<i>", "<script>", "<b>"} {
+		if strings.Contains(out, s) {
+			t.Errorf("output contains unescaped %q:\n%s", s, out)
+		}
+	}
+	for _, s := range []string{"a &lt;i&gt;report&lt;/i&gt;", "Tom &amp; Jerry", "&lt;script&gt;", "&lt;b&gt;</span>", `title="&lt;b&gt; · NOUN"`} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output lacks %q:\n%s", s, out)
+		}
+	}
+}
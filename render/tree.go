@@ -0,0 +1,269 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	sent "github.com/revelaction/segrob/sentence"
+)
+
+// The dependency tree renderers read the heads with sent.Heads. Tokens in
+// matches are highlighted.
+
+// Tree writes the dependency tree of the tokens as indented branches, a
+// token per line with its POS and relation:
+//
+//	dijo VERB root
+//	├── hombre NOUN nsubj
+//	│   └── El DET det
+//	└── . PUNCT punct
+func Tree(w io.Writer, tokens []sent.Token, matches []sent.Token, hasColor bool) error {
+	// children and roots are positions in tokens
+	children := make(map[int][]int)
+	var roots []int
+	for i, h := range sent.Heads(tokens) {
+		if h < 0 {
+			roots = append(roots, i)
+			continue
+		}
+		children[h] = append(children[h], i)
+	}
+
+	seen := make(map[int]bool)
+	var walk func(i int, prefix, branch string) error
+	walk = func(i int, prefix, branch string) error {
+		// A malformed tree may have cycles
+		if seen[i] {
+			return nil
+		}
+		seen[i] = true
+		t := tokens[i]
+
+		_, err := fmt.Fprintf(w, "%s%s%s %s %s\n", prefix, branch, colorToken(t, matches, hasColor), t.Pos, t.Dep)
+		if err != nil {
+			return err
+		}
+
+		switch branch {
+		case "├── ":
+			prefix += "│   "
+		case "└── ":
+			prefix += "    "
+		}
+
+		kids := children[i]
+		for j, k := range kids {
+			b := "├── "
+			if j == len(kids)-1 {
+				b = "└── "
+			}
+			if err := walk(k, prefix, b); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, r := range roots {
+		if err := walk(r, "", ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// box drawing directions of a cell of the arc diagram
+const (
+	up = 1 << iota
+	down
+	left
+	right
+)
+
+var boxChars = map[int]rune{
+	left | right:             '─',
+	up | down:                '│',
+	right | down:             '┌',
+	left | down:              '┐',
+	up | right:               '└',
+	up | left:                '┘',
+	left | right | down:      '┬',
+	left | right | up:        '┴',
+	up | down | right:        '├',
+	up | down | left:         '┤',
+	up | down | left | right: '┼',
+}
+
+// arc is a dependency between the columns of two tokens.
+type arc struct {
+	from, to int // columns of the head and the dependent
+	dep      string
+	level    int
+}
+
+// Arcs writes the sentence on one line with the dependencies drawn as arcs
+// above it, labelled with their relation when it fits. The arrows point to
+// the dependents.
+func Arcs(w io.Writer, tokens []sent.Token, matches []sent.Token, hasColor bool) error {
+	// center column of each token in the sentence line
+	center := make([]int, len(tokens))
+	col := 0
+	var words []string
+	for i, t := range tokens {
+		n := len([]rune(t.Text))
+		center[i] = col + (n-1)/2
+		col += n + 1
+		words = append(words, colorToken(t, matches, hasColor))
+	}
+	width := max(col-1, 0)
+
+	var arcs []*arc
+	for i, h := range sent.Heads(tokens) {
+		if h < 0 {
+			continue
+		}
+		arcs = append(arcs, &arc{from: center[h], to: center[i], dep: tokens[i].Dep})
+	}
+
+	// Shorter arcs go below: an arc is placed above every placed arc it
+	// overlaps, so that arcs never cross horizontally.
+	sort.SliceStable(arcs, func(i, j int) bool {
+		return span(arcs[i]) < span(arcs[j])
+	})
+	levels := 0
+	for i, a := range arcs {
+		lo, hi := bounds(a)
+		for _, b := range arcs[:i] {
+			blo, bhi := bounds(b)
+			if lo <= bhi && blo <= hi {
+				a.level = max(a.level, b.level)
+			}
+		}
+		a.level++
+		levels = max(levels, a.level)
+	}
+
+	// box drawing directions of each cell of the arc rows
+	cells := make([][]int, levels)
+	for i := range cells {
+		cells[i] = make([]int, width)
+	}
+	labels := make([][]rune, levels)
+	for i := range labels {
+		labels[i] = make([]rune, width)
+	}
+	arrows := []rune(strings.Repeat(" ", width))
+
+	for _, a := range arcs {
+		row := levels - a.level
+		lo, hi := bounds(a)
+		cells[row][lo] |= right | down
+		cells[row][hi] |= left | down
+		for c := lo + 1; c < hi; c++ {
+			cells[row][c] |= left | right
+		}
+		for r := row + 1; r < levels; r++ {
+			cells[r][lo] |= up | down
+			cells[r][hi] |= up | down
+		}
+
+		if arrows[a.from] != '▼' {
+			arrows[a.from] = '│'
+		}
+		arrows[a.to] = '▼'
+	}
+
+	// Labels go on the horizontal part of the arcs, not over the vertical
+	// lines of the taller ones
+	for _, a := range arcs {
+		row := levels - a.level
+		lo, hi := bounds(a)
+		label := []rune(" " + a.dep + " ")
+		if len(label) > hi-lo-1 {
+			continue
+		}
+		start := lo + 1 + (hi-lo-1-len(label))/2
+		free := true
+		for c := start; c < start+len(label); c++ {
+			free = free && cells[row][c]&(up|down) == 0
+		}
+		if free {
+			copy(labels[row][start:], label)
+		}
+	}
+
+	for r := range cells {
+		line := make([]rune, width)
+		for c, mask := range cells[r] {
+			switch {
+			case labels[r][c] != 0:
+				line[c] = labels[r][c]
+			case mask != 0:
+				line[c] = boxChars[mask]
+			default:
+				line[c] = ' '
+			}
+		}
+		if _, err := fmt.Fprintln(w, strings.TrimRight(string(line), " ")); err != nil {
+			return err
+		}
+	}
+	if levels > 0 {
+		if _, err := fmt.Fprintln(w, strings.TrimRight(string(arrows), " ")); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(words, " "))
+	return err
+}
+
+// Dot writes the dependency tree as a Graphviz digraph, with edges from the
+// heads to the dependents.
+func Dot(w io.Writer, tokens []sent.Token, matches []sent.Token) error {
+	var b strings.Builder
+	b.WriteString("digraph sentence {\n")
+	b.WriteString("  node [shape=box, fontname=\"sans-serif\"];\n")
+	b.WriteString("  edge [fontname=\"sans-serif\", fontsize=10];\n")
+
+	for _, t := range tokens {
+		style := ""
+		if isMatch(t, matches) {
+			style = ", style=filled, fillcolor=palegreen"
+		}
+		fmt.Fprintf(&b, "  t%d [label=%q%s];\n", t.Index, t.Text+"\n"+t.Pos, style)
+	}
+
+	for i, h := range sent.Heads(tokens) {
+		if h < 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "  t%d -> t%d [label=%q];\n", tokens[h].Index, tokens[i].Index, tokens[i].Dep)
+	}
+
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func isMatch(t sent.Token, matches []sent.Token) bool {
+	for _, m := range matches {
+		if m.Id == t.Id {
+			return true
+		}
+	}
+	return false
+}
+
+func bounds(a *arc) (int, int) {
+	return min(a.from, a.to), max(a.from, a.to)
+}
+
+func span(a *arc) int {
+	lo, hi := bounds(a)
+	return hi - lo
+}
@@ -0,0 +1,96 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	sent "github.com/revelaction/segrob/sentence"
+)
+
+// stanza gave the root a head of 0
+var stanzaTokens = []sent.Token{
+	{Id: 0, Index: 0, Idx: 0, Text: "El", Pos: "DET", Dep: "det", Head: 1},
+	{Id: 1, Index: 1, Idx: 3, Text: "hombre", Pos: "NOUN", Dep: "nsubj", Head: 2},
+	{Id: 2, Index: 2, Idx: 10, Text: "dijo", Pos: "VERB", Dep: "root", Head: 0},
+	{Id: 3, Index: 3, Idx: 14, Text: ".", Pos: "PUNCT", Dep: "punct", Head: 2},
+}
+
+// "Dámelo, dijo." as split by nlp_spacy.py: heads count surface tokens
+var spacyTokens = []sent.Token{
+	{Id: 0, Index: 0, Idx: 0, Text: "Dámelo", Pos: "VERB", Dep: "ccomp", Head: 2},
+	{Id: 1, Index: 1, Idx: 0, Text: "Dámelo", Pos: "VERB", Dep: "ccomp", Head: 2},
+	{Id: 2, Index: 2, Idx: 0, Text: "Dámelo", Pos: "VERB", Dep: "ccomp", Head: 2},
+	{Id: 3, Index: 3, Idx: 6, Text: ",", Pos: "PUNCT", Dep: "punct", Head: 0},
+	{Id: 4, Index: 4, Idx: 8, Text: "dijo", Pos: "VERB", Dep: "ROOT", Head: 2},
+	{Id: 5, Index: 5, Idx: 12, Text: ".", Pos: "PUNCT", Dep: "punct", Head: 2},
+}
+
+func TestTree(t *testing.T) {
+	cases := []struct {
+		name   string
+		tokens []sent.Token
+		want   string
+	}{
+		{"stanza", stanzaTokens, `dijo VERB root
+├── hombre NOUN nsubj
+│   └── El DET det
+└── . PUNCT punct
+`},
+		{"spacy", spacyTokens, `dijo VERB ROOT
+├── Dámelo VERB ccomp
+│   ├── Dámelo VERB ccomp
+│   ├── Dámelo VERB ccomp
+│   └── , PUNCT punct
+└── . PUNCT punct
+`},
+	}
+
+	for _, c := range cases {
+		var b strings.Builder
+		if err := Tree(&b, c.tokens, nil, false); err != nil {
+			t.Fatalf("%s: Tree: %v", c.name, err)
+		}
+		if b.String() != c.want {
+			t.Fatalf("%s: Tree =\n%s\nwant\n%s", c.name, b.String(), c.want)
+		}
+	}
+}
+
+func TestArcs(t *testing.T) {
+	var b strings.Builder
+	if err := Arcs(&b, stanzaTokens, nil, false); err != nil {
+		t.Fatalf("Arcs: %v", err)
+	}
+
+	// two levels of arcs, the arrows and the sentence
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != 4 || lines[3] != "El hombre dijo ." {
+		t.Fatalf("Arcs =\n%s", b.String())
+	}
+	if arrows := strings.Count(lines[2], "▼"); arrows != 3 {
+		t.Fatalf("Arcs: %d arrows, want 3:\n%s", arrows, b.String())
+	}
+}
+
+func TestDot(t *testing.T) {
+	var b strings.Builder
+	if err := Dot(&b, spacyTokens, spacyTokens[4:5]); err != nil {
+		t.Fatalf("Dot: %v", err)
+	}
+
+	for _, edge := range []string{
+		`t4 -> t0 [label="ccomp"]`,
+		`t0 -> t1 [label="ccomp"]`,
+		`t0 -> t2 [label="ccomp"]`,
+		`t0 -> t3 [label="punct"]`,
+		`t4 -> t5 [label="punct"]`,
+		`t4 [label="dijo\nVERB", style=filled, fillcolor=palegreen]`,
+	} {
+		if !strings.Contains(b.String(), edge) {
+			t.Fatalf("Dot: no %s in\n%s", edge, b.String())
+		}
+	}
+	if n := strings.Count(b.String(), "->"); n != 5 {
+		t.Fatalf("Dot: %d edges, want 5", n)
+	}
+}
@@ -2,12 +2,13 @@
 package topic
 
 import (
+	"context"
 	"fmt"
 	"math/rand/v2"
 
 	"github.com/revelaction/segrob/match"
 	"github.com/revelaction/segrob/sample"
-	sent "github.com/revelaction/segrob/sentence"
+	"github.com/revelaction/segrob/search"
 	"github.com/revelaction/segrob/storage"
 	t "github.com/revelaction/segrob/topic"
 )
@@ -61,7 +62,7 @@ type sampler struct {
 	opts Options
 }
 
-func (s *sampler) Sample() ([]*match.SentenceMatch, error) {
+func (s *sampler) Sample(ctx context.Context) ([]*match.SentenceMatch, error) {
 	if len(s.tp.Exprs) == 0 {
 		return nil, nil
 	}
@@ -75,7 +76,7 @@ func (s *sampler) Sample() ([]*match.SentenceMatch, error) {
 			s.opts.MinExpressions, s.opts.MinSizePerExpression, s.opts.Size)
 	}
 
-	minRowid, maxRowid, err := s.dr.SentenceRowidRange(s.opts.LabelID)
+	minRowid, maxRowid, err := s.dr.SentenceRowidRange(ctx, s.opts.LabelID)
 	if err != nil {
 		return nil, fmt.Errorf("sample/topic: rowid range: %w", err)
 	}
@@ -102,9 +103,7 @@ func (s *sampler) Sample() ([]*match.SentenceMatch, error) {
 	totalMatches := 0
 
 	for _, expr := range exprs {
-		lemmas := expr.Lemmas()
-		if len(lemmas) > 0 {
-			m := match.NewMatcher(expr)
+		if len(expr.Lemmas()) > 0 {
 			key := expr.String()
 
 			// Pick a random entry point so each expression starts from a
@@ -112,8 +111,8 @@ func (s *sampler) Sample() ([]*match.SentenceMatch, error) {
 			randomCursor := minRowid + rand.Int64N(maxRowid-minRowid+1)
 
 			// Forward scan: from randomCursor to the end of the book.
-			forward, forwardFetched, err := s.scanRange(
-				lemmas, m,
+			forward, forwardFetched, err := s.scanRange(ctx,
+				expr,
 				storage.Cursor(randomCursor-1), maxRowid,
 				s.opts.CandidateBudget,
 			)
@@ -131,8 +130,8 @@ func (s *sampler) Sample() ([]*match.SentenceMatch, error) {
 			// only the budget not consumed by the forward scan.
 			remaining := s.opts.CandidateBudget - forwardFetched
 			if remaining > 0 {
-				wrap, _, err := s.scanRange(
-					lemmas, m,
+				wrap, _, err := s.scanRange(ctx,
+					expr,
 					storage.Cursor(minRowid-1), randomCursor,
 					remaining,
 				)
@@ -160,65 +159,37 @@ func (s *sampler) Sample() ([]*match.SentenceMatch, error) {
 	return selectDistributed(results, s.opts.Size, s.opts.MinSizePerExpression), nil
 }
 
-// scanRange fetches candidates for the given lemmas, starting from cursor,
-// and matches them against the Matcher. It stops when the candidate budget
-// is exhausted or when a candidate's Rowid exceeds maxRowid.
+// scanRange searches expr from cursor up to maxRowid, examining at most
+// budget candidates.
 //
 // Returns the collected matches and the number of candidates examined.
 func (s *sampler) scanRange(
-	lemmas []string,
-	m *match.Matcher,
+	ctx context.Context,
+	expr t.TopicExpr,
 	cursor storage.Cursor,
 	maxRowid int64,
 	budget int,
 ) ([]*match.SentenceMatch, int, error) {
 
-	var matches []*match.SentenceMatch
-	batchSize := maxBatchSize
-	if budget < batchSize {
-		batchSize = budget
-	}
-
-	labelIDs := []int{s.opts.LabelID}
-	fetched := 0
-
-	for budget > 0 {
-		batchFetched := 0
-		newCursor, err := s.dr.FindCandidates(lemmas, labelIDs, cursor, batchSize, func(ss sent.Sentence) error {
-			if ss.Rowid > maxRowid {
-				return storage.ErrStopScan
-			}
-
-			batchFetched++
-
-			sm := m.MatchSentence(ss)
-			if sm != nil {
-				sm.TopicName = s.tp.Name
-				matches = append(matches, sm)
-			}
-			return nil
-		})
-		if err != nil {
-			return matches, fetched, err
-		}
-
-		fetched += batchFetched
-		budget -= batchFetched
-
-		// No progress — the scan is exhausted for this lemma set.
-		if newCursor == cursor {
-			break
-		}
+	sr := search.New(s.dr, search.Options{
+		Expr:      expr,
+		LabelIDs:  []int{s.opts.LabelID},
+		After:     cursor,
+		MaxRowid:  maxRowid,
+		Budget:    budget,
+		BatchSize: maxBatchSize,
+	})
 
-		cursor = newCursor
+	matches, err := sr.Collect(ctx)
+	if err != nil {
+		return nil, sr.Fetched(), err
+	}
 
-		// Ensures that subsequent queries respect the remaining budget.
-		if batchSize > budget {
-			batchSize = budget
-		}
+	for _, sm := range matches {
+		sm.TopicName = s.tp.Name
 	}
 
-	return matches, fetched, nil
+	return matches, sr.Fetched(), nil
 }
 
 // selectDistributed picks `size` matches from collected results using a
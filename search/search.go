@@ -0,0 +1,369 @@
+// Package search runs topic and expression searches against a live
+// DocReader: candidates are fetched through the lemma index and matched
+// sentence by sentence.
+//
+//	s := search.New(dr, search.Options{Topic: tp, Limit: 50})
+//	for sm, err := range s.All(ctx) {
+//		if err != nil {
+//			return err
+//		}
+//		// use sm
+//	}
+//	next := s.Cursor() // pass as Options.After to resume
+package search
+
+import (
+	"context"
+	"iter"
+
+	"github.com/revelaction/segrob/match"
+	sent "github.com/revelaction/segrob/sentence"
+	"github.com/revelaction/segrob/storage"
+	"github.com/revelaction/segrob/topic"
+)
+
+// DefaultBatchSize is the number of candidates fetched from storage per
+// FindCandidates call.
+const DefaultBatchSize = 500
+
+// Pattern is a sentence matcher with its own candidate lemmas, such as a
+// CQL query.
+type Pattern interface {
+	// Candidates returns the lemma sets of the candidates: a sentence is a
+	// candidate if it has all the lemmas of one of the sets.
+	Candidates() [][]string
+
+	MatchSentence(s sent.Sentence) *match.SentenceMatch
+}
+
+// Options configures a Search.
+//
+// The expressions of Topic are OR-ed: a sentence matches if any of them
+// matches. Expr is an AND gate: when set, a sentence must also match it. If
+// Topic has no expressions, Expr alone is searched. Pattern, when set, is
+// searched instead of Topic and Expr.
+type Options struct {
+	Topic   topic.Topic
+	Expr    topic.TopicExpr
+	Pattern Pattern
+
+	// LabelIDs restricts the search to sentences having ALL these labels
+	LabelIDs []int
+
+	// Limit is the maximum number of matches yielded (0 = unlimited)
+	Limit int
+
+	// Budget is the maximum number of candidates examined (0 = unlimited)
+	Budget int
+
+	// After resumes the search after this sentence rowid
+	After storage.Cursor
+
+	// MaxRowid ends the search at this sentence rowid, inclusive (0 = no
+	// bound)
+	MaxRowid int64
+
+	// BatchSize is the number of candidates fetched per storage call
+	// (default DefaultBatchSize)
+	BatchSize int
+
+	// Index, when set, provides the candidates of Topic from the
+	// sentence→topic index instead of the lemma index. Only default topics
+	// are indexed; the caller checks that Topic is indexed and fresh.
+	Index storage.TopicIndexReader
+}
+
+// Search is a resumable search. Candidates of all the expressions are
+// merged in rowid order, so that a sentence is examined once and the cursor
+// is a single rowid.
+type Search struct {
+	dr   storage.DocReader
+	opts Options
+
+	clauses    []*clause
+	matchers   []*match.Matcher
+	argMatcher *match.Matcher
+
+	cursor    storage.Cursor
+	fetched   int
+	matched   int
+	exhausted bool
+}
+
+// clause is the candidate stream of one topic expression: the lemmas
+// queried and a buffer of fetched candidates, in rowid order.
+type clause struct {
+	lemmas []string
+	topic  string // set for the candidates of the topic index
+
+	after  storage.Cursor
+	buffer []sent.Sentence
+	done   bool
+}
+
+func New(dr storage.DocReader, opts Options) *Search {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+
+	s := &Search{dr: dr, opts: opts, cursor: opts.After}
+
+	if opts.Pattern != nil {
+		for _, lemmas := range opts.Pattern.Candidates() {
+			s.addClause(lemmas)
+		}
+		return s
+	}
+
+	// The lemmas of the AND gate are added to the lemmas of every topic
+	// expression: a candidate must contain both.
+	var gateLemmas []string
+	if len(opts.Expr.Items) > 0 {
+		s.argMatcher = match.NewMatcher(opts.Expr)
+		gateLemmas = opts.Expr.Lemmas()
+	}
+
+	for _, e := range opts.Topic.Exprs {
+		s.matchers = append(s.matchers, match.NewMatcher(e))
+		if opts.Index == nil {
+			s.addClause(append(e.Lemmas(), gateLemmas...))
+		}
+	}
+
+	// The index holds the sentences of the topic: one clause replaces the
+	// lemma clauses, the AND gate is applied by the matcher.
+	if opts.Index != nil && len(opts.Topic.Exprs) > 0 {
+		s.clauses = append(s.clauses, &clause{topic: opts.Topic.Name, after: opts.After})
+	}
+
+	if len(opts.Topic.Exprs) == 0 {
+		s.addClause(gateLemmas)
+	}
+
+	return s
+}
+
+func (s *Search) addClause(lemmas []string) {
+	// Expressions without lemmas can not use the lemma index
+	if len(lemmas) == 0 {
+		return
+	}
+
+	seen := map[string]bool{}
+	var unique []string
+	for _, l := range lemmas {
+		if !seen[l] {
+			seen[l] = true
+			unique = append(unique, l)
+		}
+	}
+
+	s.clauses = append(s.clauses, &clause{lemmas: unique, after: s.opts.After})
+}
+
+// Cursor returns the rowid of the last candidate examined. Passed as
+// Options.After, a new Search resumes where this one stopped.
+func (s *Search) Cursor() storage.Cursor {
+	return s.cursor
+}
+
+// Exhausted reports whether all the candidates have been examined.
+func (s *Search) Exhausted() bool {
+	return s.exhausted
+}
+
+// Fetched returns the number of candidates examined.
+func (s *Search) Fetched() int {
+	return s.fetched
+}
+
+// All yields the matches in rowid order. It stops at the Limit, the Budget,
+// the end of the candidates, or when the caller breaks the loop. A storage
+// error (including the cancellation of ctx) is yielded once and ends the
+// iteration.
+func (s *Search) All(ctx context.Context) iter.Seq2[*match.SentenceMatch, error] {
+	return func(yield func(*match.SentenceMatch, error) bool) {
+		for {
+			if s.opts.Limit > 0 && s.matched >= s.opts.Limit {
+				return
+			}
+			if s.opts.Budget > 0 && s.fetched >= s.opts.Budget {
+				return
+			}
+
+			candidate, ok, err := s.next(ctx)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !ok {
+				s.exhausted = true
+				return
+			}
+
+			s.fetched++
+			s.cursor = storage.Cursor(candidate.Rowid)
+
+			sm := s.match(candidate)
+			if sm == nil {
+				continue
+			}
+
+			s.matched++
+			if !yield(sm, nil) {
+				return
+			}
+		}
+	}
+}
+
+// next pops the candidate with the lowest rowid across the clauses. The
+// same sentence at the head of several clauses is popped from all of them.
+func (s *Search) next(ctx context.Context) (sent.Sentence, bool, error) {
+	var head *sent.Sentence
+	for _, c := range s.clauses {
+		if err := s.fill(ctx, c); err != nil {
+			return sent.Sentence{}, false, err
+		}
+		if len(c.buffer) == 0 {
+			continue
+		}
+		if head == nil || c.buffer[0].Rowid < head.Rowid {
+			head = &c.buffer[0]
+		}
+	}
+
+	if head == nil {
+		return sent.Sentence{}, false, nil
+	}
+
+	candidate := *head
+	for _, c := range s.clauses {
+		if len(c.buffer) > 0 && c.buffer[0].Rowid == candidate.Rowid {
+			c.buffer = c.buffer[1:]
+		}
+	}
+
+	return candidate, true, nil
+}
+
+// fill fetches the next batch of the clause if its buffer is empty.
+func (s *Search) fill(ctx context.Context, c *clause) error {
+	if len(c.buffer) > 0 || c.done {
+		return nil
+	}
+
+	batch := s.opts.BatchSize
+	if s.opts.Budget > 0 {
+		batch = max(1, min(batch, s.opts.Budget-s.fetched))
+	}
+
+	got := 0
+	onCandidate := func(ss sent.Sentence) error {
+		if s.opts.MaxRowid > 0 && ss.Rowid > s.opts.MaxRowid {
+			c.done = true
+			return storage.ErrStopScan
+		}
+		got++
+		c.buffer = append(c.buffer, ss)
+		return nil
+	}
+
+	var newCursor storage.Cursor
+	var err error
+	if c.topic != "" {
+		newCursor, err = s.opts.Index.TopicSentences(ctx, c.topic, s.opts.LabelIDs, c.after, batch, onCandidate)
+	} else {
+		newCursor, err = s.dr.FindCandidates(ctx, c.lemmas, s.opts.LabelIDs, c.after, batch, onCandidate)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.after = newCursor
+	if got < batch {
+		c.done = true // Short batch: no more candidates
+	}
+
+	return nil
+}
+
+// match applies the pattern, or the AND gate then the topic expressions
+// (OR). It returns the first match, or nil.
+func (s *Search) match(candidate sent.Sentence) *match.SentenceMatch {
+	if s.opts.Pattern != nil {
+		return s.opts.Pattern.MatchSentence(candidate)
+	}
+
+	if s.argMatcher != nil {
+		sm := s.argMatcher.MatchSentence(candidate)
+		if sm == nil {
+			return nil
+		}
+		if len(s.opts.Topic.Exprs) == 0 {
+			return sm
+		}
+	}
+
+	for _, m := range s.matchers {
+		if sm := m.MatchSentence(candidate); sm != nil {
+			sm.TopicName = s.opts.Topic.Name
+			return sm
+		}
+	}
+
+	return nil
+}
+
+// MatchTopic matches the topic against a single sentence with the semantics
+// of a Search of the topic: the sentence must contain the lemmas of one of
+// the expressions (expressions without lemmas yield no candidates), then the
+// first matching expression wins. It returns nil if the topic does not match.
+func MatchTopic(tp topic.Topic, s sent.Sentence) *match.SentenceMatch {
+	return NewTopicMatcher(tp).Match(s)
+}
+
+// TopicMatcher is MatchTopic with the matchers of the topic built once, for
+// matching many sentences.
+type TopicMatcher struct {
+	sr *Search
+}
+
+func NewTopicMatcher(tp topic.Topic) *TopicMatcher {
+	return &TopicMatcher{sr: New(nil, Options{Topic: tp})}
+}
+
+// Match returns the match of the topic in the sentence, or nil.
+func (tm *TopicMatcher) Match(s sent.Sentence) *match.SentenceMatch {
+	lemmas := make(map[string]bool, len(s.Tokens))
+	for _, t := range s.Tokens {
+		lemmas[t.Lemma] = true
+	}
+
+	for _, c := range tm.sr.clauses {
+		candidate := true
+		for _, l := range c.lemmas {
+			if !lemmas[l] {
+				candidate = false
+				break
+			}
+		}
+		if candidate {
+			return tm.sr.match(s)
+		}
+	}
+
+	return nil
+}
+
+// Collect runs the search and returns all the matches.
+func (s *Search) Collect(ctx context.Context) ([]*match.SentenceMatch, error) {
+	var results []*match.SentenceMatch
+	for sm, err := range s.All(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, sm)
+	}
+	return results, nil
+}
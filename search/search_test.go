@@ -0,0 +1,182 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/revelaction/segrob/cql"
+	sent "github.com/revelaction/segrob/sentence"
+	"github.com/revelaction/segrob/storage"
+	"github.com/revelaction/segrob/topic"
+)
+
+// fakeReader serves FindCandidates from memory. Other DocReader methods are
+// not used by the search.
+type fakeReader struct {
+	storage.DocReader
+	sentences []sent.Sentence
+	calls     int
+}
+
+func (f *fakeReader) FindCandidates(ctx context.Context, lemmas []string, labelIDs []int, after storage.Cursor, limit int, onCandidate func(sent.Sentence) error) (storage.Cursor, error) {
+	f.calls++
+	cursor := after
+	n := 0
+	for _, s := range f.sentences {
+		if storage.Cursor(s.Rowid) <= after || !hasLemmas(s, lemmas) {
+			continue
+		}
+		if n == limit {
+			break
+		}
+		n++
+		if err := onCandidate(s); err != nil {
+			if errors.Is(err, storage.ErrStopScan) {
+				return cursor, nil
+			}
+			return after, err
+		}
+		cursor = storage.Cursor(s.Rowid)
+	}
+	return cursor, nil
+}
+
+func hasLemmas(s sent.Sentence, lemmas []string) bool {
+	for _, l := range lemmas {
+		found := false
+		for _, t := range s.Tokens {
+			if t.Lemma == l {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func newSentence(rowid int64, lemmas ...string) sent.Sentence {
+	s := sent.Sentence{Rowid: rowid, DocId: "doc", SentenceId: int(rowid)}
+	for i, l := range lemmas {
+		s.Tokens = append(s.Tokens, sent.Token{Id: i, Index: i, Head: i, Lemma: l, Text: l})
+	}
+	return s
+}
+
+func expr(lemma string) topic.TopicExpr {
+	return topic.TopicExpr{Items: []topic.TopicExprItem{{Lemma: lemma}}}
+}
+
+func rowids(t *testing.T, s *Search) []int64 {
+	t.Helper()
+	var ids []int64
+	for sm, err := range s.All(context.Background()) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids = append(ids, sm.Sentence.Rowid)
+	}
+	return ids
+}
+
+func equal(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func testReader() *fakeReader {
+	return &fakeReader{sentences: []sent.Sentence{
+		newSentence(1, "a"),
+		newSentence(2, "b"),
+		newSentence(3, "a", "b"),
+		newSentence(4, "c"),
+		newSentence(5, "b", "c"),
+		newSentence(6, "a", "c"),
+	}}
+}
+
+func TestTopicOrMerge(t *testing.T) {
+	tp := topic.Topic{Name: "ab", Exprs: []topic.TopicExpr{expr("a"), expr("b")}}
+	s := New(testReader(), Options{Topic: tp, BatchSize: 2})
+
+	got := rowids(t, s)
+	if want := []int64{1, 2, 3, 5, 6}; !equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	if !s.Exhausted() {
+		t.Fatalf("expected exhausted search")
+	}
+}
+
+func TestExprAndGate(t *testing.T) {
+	tp := topic.Topic{Name: "ab", Exprs: []topic.TopicExpr{expr("a"), expr("b")}}
+	s := New(testReader(), Options{Topic: tp, Expr: expr("c")})
+
+	got := rowids(t, s)
+	if want := []int64{5, 6}; !equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestPattern(t *testing.T) {
+	q, err := cql.Parse(`[lemma="a"] [lemma="b"] | [lemma="c"] []? [lemma="a"]`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	// 3 and 6 hold the lemmas of a clause, only 3 in the order of the query
+	dr := testReader()
+	dr.sentences = append(dr.sentences, newSentence(7, "c", "x", "a"))
+	s := New(dr, Options{Pattern: q, Expr: expr("b"), BatchSize: 2})
+
+	if got, want := rowids(t, s), []int64{3, 7}; !equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestLimitAndResume(t *testing.T) {
+	dr := testReader()
+	opts := Options{Expr: expr("a"), Limit: 2, BatchSize: 1}
+
+	s := New(dr, opts)
+	if got, want := rowids(t, s), []int64{1, 3}; !equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if s.Exhausted() {
+		t.Fatalf("search stopped by the limit must not be exhausted")
+	}
+
+	opts.After = s.Cursor()
+	s = New(dr, opts)
+	if got, want := rowids(t, s), []int64{6}; !equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestBudgetAndMaxRowid(t *testing.T) {
+	tp := topic.Topic{Exprs: []topic.TopicExpr{expr("a"), expr("b"), expr("c")}}
+
+	s := New(testReader(), Options{Topic: tp, Budget: 3})
+	if got, want := rowids(t, s), []int64{1, 2, 3}; !equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if s.Fetched() != 3 || s.Cursor() != 3 {
+		t.Fatalf("unexpected fetched %d, cursor %d", s.Fetched(), s.Cursor())
+	}
+
+	s = New(testReader(), Options{Topic: tp, After: 1, MaxRowid: 4})
+	if got, want := rowids(t, s), []int64{2, 3, 4}; !equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,132 @@
+package sentence
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Surface groups the tokens by surface token: the words of a multi-word
+// token, consecutive tokens with the same idx and text, go together.
+func Surface(tokens []Token) [][]Token {
+	var words [][]Token
+	for _, t := range tokens {
+		if n := len(words); n > 0 {
+			last := words[n-1][0]
+			if last.Idx == t.Idx && last.Text == t.Text {
+				words[n-1] = append(words[n-1], t)
+				continue
+			}
+		}
+		words = append(words, []Token{t})
+	}
+	return words
+}
+
+// Heads returns the position in tokens of the head of each token, -1 for a
+// root. It reads the heads written by both NLP scripts:
+//
+//   - the root has dep root: spaCy makes it its own head, stanza gives it 0;
+//   - spaCy heads are positions among the surface tokens, the words it splits
+//     from a multi-word token repeating the pos, tag, dep and head of the
+//     surface token. They point to the first word of the surface token, and
+//     the other words attach to that first word;
+//   - otherwise heads are the index of the head word.
+//
+// A head that is not in the sentence makes a root; see CheckHeads.
+func Heads(tokens []Token) []int {
+	// surface token of each position, and first position of each surface token
+	group := make([]int, len(tokens))
+	var firsts []int
+	i := 0
+	for _, w := range Surface(tokens) {
+		for range w {
+			group[i] = len(firsts)
+			i++
+		}
+		firsts = append(firsts, i-len(w))
+	}
+
+	spacy := isSpacySplit(tokens)
+
+	positions := make(map[int]int, len(tokens))
+	for i, t := range tokens {
+		positions[t.Index] = i
+	}
+
+	heads := make([]int, len(tokens))
+	for i, t := range tokens {
+		first := firsts[group[i]]
+		if spacy && i != first {
+			heads[i] = first
+			continue
+		}
+		if strings.EqualFold(t.Dep, "root") {
+			heads[i] = -1
+			continue
+		}
+
+		h, ok := -1, false
+		if spacy {
+			if ok = t.Head >= 0 && t.Head < len(firsts); ok {
+				h = firsts[t.Head]
+			}
+		} else {
+			h, ok = positions[t.Head]
+		}
+		if !ok || h == i {
+			h = -1
+		}
+		heads[i] = h
+	}
+
+	return heads
+}
+
+// isSpacySplit reports whether the sentence has a multi-word token split by
+// spaCy: all its words with the pos, tag, dep and head of the surface token.
+func isSpacySplit(tokens []Token) bool {
+	for _, w := range Surface(tokens) {
+		if len(w) < 2 {
+			continue
+		}
+		same := true
+		for _, t := range w[1:] {
+			same = same && t.Pos == w[0].Pos && t.Tag == w[0].Tag && t.Dep == w[0].Dep && t.Head == w[0].Head
+		}
+		if same {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrTree is returned by CheckHeads for heads that do not make a tree.
+var ErrTree = errors.New("invalid dependency tree")
+
+// CheckHeads checks that the heads returned by Heads make a tree: exactly
+// one root and no cycle.
+func CheckHeads(heads []int) error {
+	roots := 0
+	for _, h := range heads {
+		if h < 0 {
+			roots++
+		}
+	}
+	if roots != 1 {
+		return fmt.Errorf("%w: %d roots", ErrTree, roots)
+	}
+
+	for i := range heads {
+		// no path to the root is longer than the sentence
+		d, j := 0, i
+		for heads[j] >= 0 {
+			if d++; d > len(heads) {
+				return fmt.Errorf("%w: cycle at token %d", ErrTree, i)
+			}
+			j = heads[j]
+		}
+	}
+
+	return nil
+}
@@ -1,5 +1,7 @@
 package sentence
 
+import "strings"
+
 // Sentence represents a distinct syntactic unit.
 // Identity = (DocId, Id)
 type Sentence struct {
@@ -50,3 +52,20 @@ type Token struct {
 	// The index of the word in the sentence, starting at 0.
 	Index int `json:"index"`
 }
+
+// Features returns the UD morphological features of the Tag, e.g.
+// "VERB__Mood=Ind|Tense=Past" gives {Mood: Ind, Tense: Past}.
+func (t Token) Features() map[string]string {
+	_, feats, ok := strings.Cut(t.Tag, "__")
+	if !ok || feats == "" || feats == "_" {
+		return nil
+	}
+
+	res := make(map[string]string)
+	for _, f := range strings.Split(feats, "|") {
+		if name, value, ok := strings.Cut(f, "="); ok {
+			res[name] = value
+		}
+	}
+	return res
+}
@@ -0,0 +1,553 @@
+// Package server exposes the live database as an HTTP JSON API.
+//
+//	GET    /api/docs                                 list documents
+//	GET    /api/docs/{id}?start=&count=              sentences of a document
+//	GET    /api/docs/{id}/sentences/{sid}            a sentence
+//	GET    /api/docs/{id}/sentences/{sid}/topics     topics matching a sentence
+//	GET    /api/find?expr=&topic=&user=&label=&limit=&cursor=&budget=
+//	GET    /api/topics                               default topics
+//	GET    /api/topics/{name}
+//	GET    /api/users/{user}/topics                  topics of a user
+//	GET    /api/users/{user}/topics/{name}
+//	PUT    /api/users/{user}/topics/{name}           create or replace
+//	DELETE /api/users/{user}/topics/{name}
+//	POST   /api/users/{user}/topics/{name}/rename    body {"name": "new"}
+//	POST   /api/users/{user}/topics/copy-default     copy the default topics
+//
+// A user without topics is a new user: listing, reading or writing its
+// topics first copies the default topics to it.
+//
+// Errors are returned as {"error": "..."} with status 400, 404 or 500.
+//
+// Every other path is served from the embedded web UI in web/, a static
+// front-end over the same API.
+package server
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/revelaction/segrob/match"
+	"github.com/revelaction/segrob/search"
+	sent "github.com/revelaction/segrob/sentence"
+	"github.com/revelaction/segrob/storage"
+	"github.com/revelaction/segrob/topic"
+)
+
+const (
+	// DefaultLimit is the number of matches returned by /api/find when the
+	// request has no limit.
+	DefaultLimit = 50
+
+	// MaxLimit caps the limit of /api/find and the count of /api/docs/{id}.
+	MaxLimit = 1000
+
+	// MaxBudget caps the candidates examined by a /api/find request, and is
+	// its default budget. A request stopping at the budget returns the
+	// matches so far and the cursor to continue from.
+	MaxBudget = 20000
+)
+
+// webFiles embeds the web UI.
+//
+//go:embed web
+var webFiles embed.FS
+
+// Server serves the JSON API. Handlers only read and write through the
+// storage interfaces, so concurrent requests share the WAL pool behind them.
+type Server struct {
+	docs   storage.DocReader
+	topics storage.TopicRepository
+	mux    *http.ServeMux
+}
+
+// New returns the API server for the given live repositories.
+func New(dr storage.DocReader, tr storage.TopicRepository) *Server {
+	s := &Server{docs: dr, topics: tr, mux: http.NewServeMux()}
+
+	s.mux.HandleFunc("GET /api/docs", s.handleDocs)
+	s.mux.HandleFunc("GET /api/docs/{id}", s.handleDoc)
+	s.mux.HandleFunc("GET /api/docs/{id}/sentences/{sid}", s.handleSentence)
+	s.mux.HandleFunc("GET /api/docs/{id}/sentences/{sid}/topics", s.handleSentenceTopics)
+	s.mux.HandleFunc("GET /api/find", s.handleFind)
+
+	s.mux.HandleFunc("GET /api/topics", s.handleTopics)
+	s.mux.HandleFunc("GET /api/topics/{name}", s.handleTopic)
+	s.mux.HandleFunc("GET /api/users/{user}/topics", s.handleTopics)
+	s.mux.HandleFunc("GET /api/users/{user}/topics/{name}", s.handleTopic)
+	s.mux.HandleFunc("PUT /api/users/{user}/topics/{name}", s.handlePutTopic)
+	s.mux.HandleFunc("DELETE /api/users/{user}/topics/{name}", s.handleDeleteTopic)
+	s.mux.HandleFunc("POST /api/users/{user}/topics/{name}/rename", s.handleRenameTopic)
+	s.mux.HandleFunc("POST /api/users/{user}/topics/copy-default", s.handleCopyDefault)
+
+	// Unknown API paths get a JSON error, not the file server 404
+	s.mux.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, fmt.Errorf("endpoint %w: %s %s", storage.ErrNotFound, r.Method, r.URL.Path))
+	})
+
+	web, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		panic(err) // the embedded directory exists at build time
+	}
+	s.mux.Handle("/", http.FileServerFS(web))
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Doc is a document of the /api/docs list.
+type Doc struct {
+	ID     string   `json:"id"`
+	Source string   `json:"source"`
+	Labels []string `json:"labels"`
+}
+
+// FindResult is the response of /api/find. Cursor is passed back as the
+// cursor parameter to fetch the next page; Exhausted tells there is none.
+type FindResult struct {
+	Matches   []*match.SentenceMatch `json:"matches"`
+	Cursor    storage.Cursor         `json:"cursor"`
+	Exhausted bool                   `json:"exhausted"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type renameRequest struct {
+	Name string `json:"name"`
+}
+
+// badRequest marks errors caused by the request parameters.
+type badRequest struct{ error }
+
+func (s *Server) handleDocs(w http.ResponseWriter, r *http.Request) {
+	metas, err := s.docs.List(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	labels, err := s.docs.ListLabels(r.Context(), "")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	names := labels.Reverse()
+
+	docs := make([]Doc, 0, len(metas))
+	for _, m := range metas {
+		d := Doc{ID: m.Id, Source: m.Source, Labels: []string{}}
+		for _, id := range m.LabelIDs {
+			if name, ok := names[id]; ok {
+				d.Labels = append(d.Labels, name)
+			}
+		}
+		docs = append(docs, d)
+	}
+
+	writeJSON(w, http.StatusOK, docs)
+}
+
+func (s *Server) handleDoc(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	q := r.URL.Query()
+
+	start, err := intParam(q.Get("start"), 0)
+	if err != nil {
+		writeError(w, badRequest{fmt.Errorf("start: %w", err)})
+		return
+	}
+
+	count, err := intParam(q.Get("count"), 0)
+	if err != nil {
+		writeError(w, badRequest{fmt.Errorf("count: %w", err)})
+		return
+	}
+
+	if err := s.docExists(r, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// The offset of Nlp is inclusive; without count the document is
+	// returned up to MaxLimit sentences.
+	if count == 0 || count > MaxLimit {
+		count = MaxLimit
+	}
+	offset := count - 1
+
+	sentences, err := s.docs.Nlp(r.Context(), id, start, &offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if sentences == nil {
+		sentences = []sent.Sentence{}
+	}
+
+	writeJSON(w, http.StatusOK, sentences)
+}
+
+func (s *Server) handleSentence(w http.ResponseWriter, r *http.Request) {
+	sentence, err := s.sentence(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, sentence)
+}
+
+func (s *Server) handleSentenceTopics(w http.ResponseWriter, r *http.Request) {
+	sentence, err := s.sentence(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	lib, err := s.topics.ReadAll(r.Context(), r.URL.Query().Get("user"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	matches := []*match.SentenceMatch{}
+	for _, tp := range lib {
+		if sm := search.MatchTopic(tp, sentence); sm != nil {
+			matches = append(matches, sm)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, matches)
+}
+
+func (s *Server) handleFind(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	opts := search.Options{}
+
+	if exprStr := q.Get("expr"); exprStr != "" {
+		expr, err := topic.Parse(strings.Fields(exprStr))
+		if err != nil {
+			writeError(w, badRequest{fmt.Errorf("expr: %w", err)})
+			return
+		}
+		opts.Expr = expr
+	}
+
+	if name := q.Get("topic"); name != "" {
+		tp, err := s.topics.Read(r.Context(), q.Get("user"), name)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		opts.Topic = tp
+	}
+
+	if len(opts.Topic.Exprs) == 0 && len(opts.Expr.Items) == 0 {
+		writeError(w, badRequest{errors.New("expr or topic is required")})
+		return
+	}
+
+	limit, err := intParam(q.Get("limit"), DefaultLimit)
+	if err != nil || limit < 1 {
+		writeError(w, badRequest{fmt.Errorf("invalid limit: %q", q.Get("limit"))})
+		return
+	}
+	opts.Limit = min(limit, MaxLimit)
+
+	budget, err := intParam(q.Get("budget"), MaxBudget)
+	if err != nil || budget < 1 {
+		writeError(w, badRequest{fmt.Errorf("invalid budget: %q", q.Get("budget"))})
+		return
+	}
+	opts.Budget = min(budget, MaxBudget)
+
+	cursor, err := intParam(q.Get("cursor"), 0)
+	if err != nil {
+		writeError(w, badRequest{fmt.Errorf("cursor: %w", err)})
+		return
+	}
+	opts.After = storage.Cursor(cursor)
+
+	labelIDs, err := s.labelIDs(r, q["label"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	opts.LabelIDs = labelIDs
+
+	sr := search.New(s.docs, opts)
+	matches, err := sr.Collect(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if matches == nil {
+		matches = []*match.SentenceMatch{}
+	}
+
+	writeJSON(w, http.StatusOK, FindResult{
+		Matches:   matches,
+		Cursor:    sr.Cursor(),
+		Exhausted: sr.Exhausted(),
+	})
+}
+
+func (s *Server) handleTopics(w http.ResponseWriter, r *http.Request) {
+	if err := s.seedUser(r); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	lib, err := s.topics.ReadAll(r.Context(), r.PathValue("user"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if lib == nil {
+		lib = topic.Library{}
+	}
+
+	writeJSON(w, http.StatusOK, lib)
+}
+
+func (s *Server) handleTopic(w http.ResponseWriter, r *http.Request) {
+	if err := s.seedUser(r); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	tp, err := s.topics.Read(r.Context(), r.PathValue("user"), r.PathValue("name"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tp)
+}
+
+// handlePutTopic replaces the expressions of the topic with those of the
+// body. The name of the path wins over a name in the body.
+func (s *Server) handlePutTopic(w http.ResponseWriter, r *http.Request) {
+	var tp topic.Topic
+	if err := json.NewDecoder(r.Body).Decode(&tp); err != nil {
+		writeError(w, badRequest{fmt.Errorf("invalid topic: %w", err)})
+		return
+	}
+
+	tp.Name = r.PathValue("name")
+	for _, expr := range tp.Exprs {
+		if len(expr.Items) == 0 {
+			writeError(w, badRequest{errors.New("invalid topic: empty expression")})
+			return
+		}
+	}
+	tp.Exprs = topic.Deduplicate(tp.Exprs)
+
+	if err := s.seedUser(r); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	saved, err := s.topics.Upsert(r.Context(), r.PathValue("user"), tp, nil)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, saved)
+}
+
+func (s *Server) handleDeleteTopic(w http.ResponseWriter, r *http.Request) {
+	user, name := r.PathValue("user"), r.PathValue("name")
+
+	if _, err := s.topics.Read(r.Context(), user, name); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.topics.Delete(r.Context(), user, name); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleRenameTopic(w http.ResponseWriter, r *http.Request) {
+	user, name := r.PathValue("user"), r.PathValue("name")
+
+	var req renameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, badRequest{fmt.Errorf("invalid body: %w", err)})
+		return
+	}
+
+	if req.Name == "" {
+		writeError(w, badRequest{errors.New("name is required")})
+		return
+	}
+
+	if _, err := s.topics.Read(r.Context(), user, name); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.topics.Rename(r.Context(), user, name, req.Name); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	tp, err := s.topics.Read(r.Context(), user, req.Name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tp)
+}
+
+func (s *Server) handleCopyDefault(w http.ResponseWriter, r *http.Request) {
+	user := r.PathValue("user")
+
+	if err := s.topics.CopyDefault(r.Context(), user); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	s.handleTopics(w, r)
+}
+
+// seedUser copies the default topics to the {user} of the path if it has no
+// topics yet. The default library (no {user}) is left as is.
+func (s *Server) seedUser(r *http.Request) error {
+	user := r.PathValue("user")
+	if user == "" {
+		return nil
+	}
+
+	lib, err := s.topics.ReadAll(r.Context(), user)
+	if err != nil {
+		return err
+	}
+	if len(lib) > 0 {
+		return nil
+	}
+
+	return s.topics.CopyDefault(r.Context(), user)
+}
+
+// sentence returns the sentence of the {id} and {sid} path values.
+func (s *Server) sentence(r *http.Request) (sent.Sentence, error) {
+	id := r.PathValue("id")
+
+	sid, err := strconv.Atoi(r.PathValue("sid"))
+	if err != nil || sid < 0 {
+		return sent.Sentence{}, badRequest{fmt.Errorf("invalid sentence id: %q", r.PathValue("sid"))}
+	}
+
+	if err := s.docExists(r, id); err != nil {
+		return sent.Sentence{}, err
+	}
+
+	zero := 0
+	sentences, err := s.docs.Nlp(r.Context(), id, sid, &zero)
+	if err != nil {
+		return sent.Sentence{}, err
+	}
+
+	if len(sentences) == 0 {
+		return sent.Sentence{}, fmt.Errorf("sentence %w: %s %d", storage.ErrNotFound, id, sid)
+	}
+
+	return sentences[0], nil
+}
+
+func (s *Server) docExists(r *http.Request, id string) error {
+	ok, err := s.docs.Exists(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return fmt.Errorf("document %w: %s", storage.ErrNotFound, id)
+	}
+
+	return nil
+}
+
+// labelIDs resolves label names to their IDs. Unlike the CLI, an unknown
+// label is an error: silently dropping it would widen the search.
+func (s *Server) labelIDs(r *http.Request, names []string) ([]int, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	labels, err := s.docs.ListLabels(r.Context(), "")
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(names))
+	for _, name := range names {
+		id, ok := labels[name]
+		if !ok {
+			return nil, fmt.Errorf("label %w: %s", storage.ErrNotFound, name)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// intParam parses an integer query parameter, returning def when it is empty.
+func intParam(v string, def int) (int, error) {
+	if v == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer: %q", v)
+	}
+
+	if n < 0 {
+		return 0, fmt.Errorf("negative integer: %q", v)
+	}
+
+	return n, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+
+	var br badRequest
+	switch {
+	case errors.As(err, &br):
+		status = http.StatusBadRequest
+	case errors.Is(err, storage.ErrNotFound):
+		status = http.StatusNotFound
+	}
+
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,286 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/revelaction/segrob/match"
+	sent "github.com/revelaction/segrob/sentence"
+	"github.com/revelaction/segrob/storage"
+	"github.com/revelaction/segrob/storage/sqlite/zombiezen"
+	"github.com/revelaction/segrob/topic"
+)
+
+// newTestServer creates a live database in a temp dir with one document of
+// five sentences, the odd ones containing "tomar ... mano", and a default
+// topic "tomar-mano".
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	ctx := context.Background()
+
+	pool, err := zombiezen.NewPool(filepath.Join(t.TempDir(), "live.db"))
+	if err != nil {
+		t.Fatalf("NewPool: %v", err)
+	}
+	t.Cleanup(func() { _ = pool.Close() })
+
+	mgr := zombiezen.NewSchemaManager(pool)
+	for _, schema := range []string{"live_canonical.sql", "live_optimization.sql"} {
+		if err := mgr.Create(ctx, schema); err != nil {
+			t.Fatalf("Create %s: %v", schema, err)
+		}
+	}
+
+	ds := zombiezen.NewDocStore(pool)
+	labelIDs, err := ds.WriteMeta(ctx, "doc1", "doc1.epub", []string{"title:uno", "date:1944"})
+	if err != nil {
+		t.Fatalf("WriteMeta: %v", err)
+	}
+
+	var ingest []storage.SentenceIngest
+	for i := range 5 {
+		lemmas := []string{"él", "comer", "pan"}
+		if i%2 == 1 {
+			lemmas = []string{"él", "tomar", "el", "mano"}
+		}
+		var tokens []sent.Token
+		for j, l := range lemmas {
+			tokens = append(tokens, sent.Token{Id: j, Index: j, Text: l, Lemma: l, Pos: "X"})
+		}
+		b, err := json.Marshal(tokens)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		ingest = append(ingest, storage.SentenceIngest{ID: i, Lemmas: lemmas, Tokens: b})
+	}
+
+	if err := ds.WriteNlpData(ctx, "doc1", ingest); err != nil {
+		t.Fatalf("WriteNlpData: %v", err)
+	}
+	if err := ds.WriteLabelsOptimization(ctx, "doc1", labelIDs); err != nil {
+		t.Fatalf("WriteLabelsOptimization: %v", err)
+	}
+	if err := ds.WriteLemmaOptimization(ctx, "doc1", ingest, nil); err != nil {
+		t.Fatalf("WriteLemmaOptimization: %v", err)
+	}
+
+	ts := zombiezen.NewLiveTopicStore(pool)
+	tp := topic.Topic{Name: "tomar-mano", Exprs: []topic.TopicExpr{
+		{Items: []topic.TopicExprItem{{Lemma: "tomar"}, {Lemma: "mano", Near: 2}}},
+	}}
+	if _, err := ts.Upsert(ctx, "", tp, nil); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	srv := httptest.NewServer(New(ds, ts))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func do(t *testing.T, srv *httptest.Server, method, path, body string, v any) int {
+	t.Helper()
+
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if v != nil && resp.StatusCode < 300 {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("%s %s: decode: %v", method, path, err)
+		}
+	}
+
+	return resp.StatusCode
+}
+
+func TestDocs(t *testing.T) {
+	srv := newTestServer(t)
+
+	var docs []Doc
+	if code := do(t, srv, "GET", "/api/docs", "", &docs); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if len(docs) != 1 || docs[0].ID != "doc1" || len(docs[0].Labels) != 2 {
+		t.Fatalf("docs = %+v", docs)
+	}
+
+	var sentences []sent.Sentence
+	if code := do(t, srv, "GET", "/api/docs/doc1?start=1&count=2", "", &sentences); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if len(sentences) != 2 || sentences[0].SentenceId != 1 {
+		t.Fatalf("sentences = %+v", sentences)
+	}
+
+	var s sent.Sentence
+	if code := do(t, srv, "GET", "/api/docs/doc1/sentences/3", "", &s); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if s.SentenceId != 3 || s.DocId != "doc1" {
+		t.Fatalf("sentence = %+v", s)
+	}
+
+	for path, want := range map[string]int{
+		"/api/docs/nope":               http.StatusNotFound,
+		"/api/docs/doc1/sentences/99":  http.StatusNotFound,
+		"/api/docs/doc1/sentences/abc": http.StatusBadRequest,
+		"/api/docs/doc1?count=-1":      http.StatusBadRequest,
+	} {
+		if code := do(t, srv, "GET", path, "", nil); code != want {
+			t.Fatalf("GET %s: status = %d, want %d", path, code, want)
+		}
+	}
+}
+
+func TestFindPaginates(t *testing.T) {
+	srv := newTestServer(t)
+
+	var seen []int
+	cursor := storage.Cursor(0)
+	for range 3 {
+		var res FindResult
+		path := "/api/find?topic=tomar-mano&label=title:uno&limit=1&cursor=" + strconv.FormatInt(int64(cursor), 10)
+		if code := do(t, srv, "GET", path, "", &res); code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", code)
+		}
+		for _, m := range res.Matches {
+			seen = append(seen, m.Sentence.SentenceId)
+			if m.TopicName != "tomar-mano" {
+				t.Fatalf("TopicName = %q", m.TopicName)
+			}
+		}
+		cursor = res.Cursor
+		if res.Exhausted {
+			break
+		}
+	}
+
+	if len(seen) != 2 || seen[0] != 1 || seen[1] != 3 {
+		t.Fatalf("seen = %v, want [1 3]", seen)
+	}
+
+	// The budget stops the scan at the first candidate: "pan" is in 0, 2, 4
+	var res FindResult
+	if code := do(t, srv, "GET", "/api/find?expr=pan&budget=1", "", &res); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if len(res.Matches) != 1 || res.Matches[0].Sentence.SentenceId != 0 || res.Exhausted || res.Cursor != storage.Cursor(res.Matches[0].Sentence.Rowid) {
+		t.Fatalf("budget 1: result = %+v", res)
+	}
+
+	for path, want := range map[string]int{
+		"/api/find":                     http.StatusBadRequest,
+		"/api/find?expr=pan&budget=0":   http.StatusBadRequest,
+		"/api/find?expr=2":              http.StatusBadRequest,
+		"/api/find?topic=nope":          http.StatusNotFound,
+		"/api/find?expr=pan&label=nope": http.StatusNotFound,
+		"/api/find?expr=pan&limit=x":    http.StatusBadRequest,
+	} {
+		if code := do(t, srv, "GET", path, "", nil); code != want {
+			t.Fatalf("GET %s: status = %d, want %d", path, code, want)
+		}
+	}
+}
+
+func TestSentenceTopics(t *testing.T) {
+	srv := newTestServer(t)
+
+	for sid, want := range map[string]int{"1": 1, "2": 0} {
+		var matches []*match.SentenceMatch
+		if code := do(t, srv, "GET", "/api/docs/doc1/sentences/"+sid+"/topics", "", &matches); code != http.StatusOK {
+			t.Fatalf("sentence %s: status = %d, want 200", sid, code)
+		}
+		if len(matches) != want {
+			t.Fatalf("sentence %s: %d matches, want %d", sid, len(matches), want)
+		}
+		if want > 0 && matches[0].TopicName != "tomar-mano" {
+			t.Fatalf("sentence %s: TopicName = %q", sid, matches[0].TopicName)
+		}
+	}
+}
+
+func TestTopicCRUD(t *testing.T) {
+	srv := newTestServer(t)
+
+	var lib topic.Library
+	if code := do(t, srv, "POST", "/api/users/ana/topics/copy-default", "", &lib); code != http.StatusOK {
+		t.Fatalf("copy-default: status = %d, want 200", code)
+	}
+	if len(lib) != 1 || lib[0].Name != "tomar-mano" {
+		t.Fatalf("library = %+v", lib)
+	}
+
+	body := `{"exprs":[{"items":[{"lemma":"comer"}]},{"items":[{"lemma":"comer"}]}]}`
+	var tp topic.Topic
+	if code := do(t, srv, "PUT", "/api/users/ana/topics/comer", body, &tp); code != http.StatusOK {
+		t.Fatalf("put: status = %d, want 200", code)
+	}
+	if tp.Name != "comer" || len(tp.Exprs) != 1 {
+		t.Fatalf("topic = %+v", tp)
+	}
+
+	if code := do(t, srv, "POST", "/api/users/ana/topics/comer/rename", `{"name":"pan"}`, &tp); code != http.StatusOK {
+		t.Fatalf("rename: status = %d, want 200", code)
+	}
+	if code := do(t, srv, "GET", "/api/users/ana/topics/comer", "", nil); code != http.StatusNotFound {
+		t.Fatalf("get old name: status = %d, want 404", code)
+	}
+
+	if code := do(t, srv, "DELETE", "/api/users/ana/topics/pan", "", nil); code != http.StatusNoContent {
+		t.Fatalf("delete: status = %d, want 204", code)
+	}
+	if code := do(t, srv, "DELETE", "/api/users/ana/topics/pan", "", nil); code != http.StatusNotFound {
+		t.Fatalf("delete again: status = %d, want 404", code)
+	}
+
+	// A new user gets the default topics on first use
+	if code := do(t, srv, "GET", "/api/users/bea/topics", "", &lib); code != http.StatusOK || len(lib) != 1 || lib[0].Name != "tomar-mano" {
+		t.Fatalf("new user list: status = %d, library = %+v", code, lib)
+	}
+	if code := do(t, srv, "PUT", "/api/users/carl/topics/comer", body, &tp); code != http.StatusOK {
+		t.Fatalf("new user put: status = %d, want 200", code)
+	}
+	if code := do(t, srv, "GET", "/api/users/carl/topics", "", &lib); code != http.StatusOK || len(lib) != 2 {
+		t.Fatalf("new user after put: status = %d, library = %+v", code, lib)
+	}
+
+	// The default library is untouched
+	if code := do(t, srv, "GET", "/api/topics", "", &lib); code != http.StatusOK || len(lib) != 1 {
+		t.Fatalf("default topics: status = %d, library = %+v", code, lib)
+	}
+
+	if code := do(t, srv, "PUT", "/api/users/ana/topics/bad", "{", nil); code != http.StatusBadRequest {
+		t.Fatalf("put invalid: status = %d, want 400", code)
+	}
+}
+
+func TestWebUI(t *testing.T) {
+	srv := newTestServer(t)
+
+	resp, err := srv.Client().Get(srv.URL + "/")
+	if err != nil {
+		t.Fatalf("GET /: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		t.Fatalf("GET /: status = %d, content type = %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	if code := do(t, srv, "GET", "/api/nope", "", nil); code != http.StatusNotFound {
+		t.Fatalf("GET /api/nope: status = %d, want 404", code)
+	}
+}
@@ -0,0 +1,368 @@
+// segrob web UI. A hash router over the JSON API of the server package:
+//
+//   #/docs?label=L           documents, filtered by labels
+//   #/doc/ID?start=N         a document, sentence by sentence
+//   #/sent/ID/SID            a sentence: token table and matching topics
+//   #/search?topic=&expr=&label=
+"use strict";
+
+const PAGE_SIZE = 50;
+const NUM_CHAIN_COLORS = 6;
+
+const main = document.getElementById("main");
+const userInput = document.getElementById("user");
+
+userInput.value = localStorage.getItem("segrob.user") || "";
+userInput.addEventListener("change", () => {
+  localStorage.setItem("segrob.user", userInput.value.trim());
+  route();
+});
+
+// el creates an element. Strings and nodes in children are appended; text
+// is never parsed as HTML.
+function el(tag, attrs, ...children) {
+  const e = document.createElement(tag);
+  for (const [k, v] of Object.entries(attrs || {})) {
+    if (k.startsWith("on")) {
+      e.addEventListener(k.slice(2), v);
+    } else if (v !== undefined && v !== null && v !== false) {
+      e.setAttribute(k, v);
+    }
+  }
+  for (const c of children.flat()) {
+    if (c !== undefined && c !== null) {
+      e.append(c);
+    }
+  }
+  return e;
+}
+
+async function api(path) {
+  const resp = await fetch(path);
+  const body = await resp.json();
+  if (!resp.ok) {
+    throw new Error(body.error || resp.statusText);
+  }
+  return body;
+}
+
+function user() {
+  return userInput.value.trim();
+}
+
+function topicsPath() {
+  return user() ? `/api/users/${encodeURIComponent(user())}/topics` : "/api/topics";
+}
+
+function show(...nodes) {
+  main.replaceChildren(...nodes);
+}
+
+function showError(err) {
+  show(el("p", { class: "error" }, String(err.message || err)));
+}
+
+// docs is cached: document titles are needed by every view.
+let docsCache = null;
+
+async function docs() {
+  if (!docsCache) {
+    docsCache = await api("/api/docs");
+  }
+  return docsCache;
+}
+
+function labelValue(labels, prefix) {
+  const l = labels.find((l) => l.startsWith(prefix));
+  return l ? l.slice(prefix.length).replaceAll("_", " ") : "";
+}
+
+function docTitle(doc) {
+  return labelValue(doc.labels, "title:") || doc.source || doc.id;
+}
+
+// segments splits the tokens into surface words with the spacing rules of
+// the CLI renderer: gaps come from idx, and tokens sharing an idx
+// (multi-word tokens) are shown once.
+function segments(tokens, chains) {
+  const chainOf = new Map();
+  (chains || []).forEach((chain, c) => {
+    for (const t of chain) {
+      if (!chainOf.has(t.id)) {
+        chainOf.set(t.id, c % NUM_CHAIN_COLORS);
+      }
+    }
+  });
+
+  const segs = [];
+  let lastEnd = 0;
+  tokens.forEach((t, i) => {
+    const chain = chainOf.has(t.id) ? chainOf.get(t.id) : -1;
+    const tip = tokenTip(t);
+
+    if (i > 0 && t.idx === tokens[i - 1].idx) {
+      const last = segs[segs.length - 1];
+      last.tip += "\n" + tip;
+      if (last.chain === -1) {
+        last.chain = chain;
+      }
+      return;
+    }
+
+    const space = i > 0 && t.idx > lastEnd ? " ".repeat(t.idx - lastEnd) : "";
+    segs.push({ space, text: t.text.replaceAll("\n", " "), tip, chain });
+    lastEnd = t.idx + [...t.text].length;
+  });
+
+  return segs;
+}
+
+function tokenTip(t) {
+  let tip = `${t.lemma} · ${t.pos}`;
+  const tag = t.tag.startsWith(t.pos + "__") ? t.tag.slice(t.pos.length + 2) : t.tag;
+  if (tag && tag !== t.pos) {
+    tip += ` · ${tag}`;
+  }
+  return tip;
+}
+
+function sentenceNode(tokens, chains) {
+  const span = el("span");
+  for (const s of segments(tokens, chains)) {
+    span.append(s.space);
+    const cls = s.chain >= 0 ? `t m m${s.chain}` : "t";
+    span.append(el("span", { class: cls, title: s.tip }, s.text));
+  }
+  return span;
+}
+
+function sentenceLink(docID, sid, node) {
+  return el("a", { href: `#/sent/${encodeURIComponent(docID)}/${sid}`, class: "plain" }, node);
+}
+
+// --- views ---
+
+async function viewDocs(params) {
+  const selected = params.getAll("label");
+  const all = await docs();
+
+  const counts = new Map();
+  for (const d of all) {
+    for (const l of d.labels) {
+      counts.set(l, (counts.get(l) || 0) + 1);
+    }
+  }
+
+  const filtered = all.filter((d) => selected.every((l) => d.labels.includes(l)));
+
+  const chips = el("div", { class: "labels" });
+  for (const l of [...counts.keys()].sort()) {
+    const on = selected.includes(l);
+    const next = new URLSearchParams();
+    for (const s of selected) {
+      if (s !== l) {
+        next.append("label", s);
+      }
+    }
+    if (!on) {
+      next.append("label", l);
+    }
+    chips.append(el("a", { class: on ? "chip on" : "chip", href: `#/docs?${next}` }, `${l} (${counts.get(l)})`));
+  }
+
+  const rows = filtered.map((d) =>
+    el("tr", {},
+      el("td", {}, el("a", { href: `#/doc/${encodeURIComponent(d.id)}` }, docTitle(d))),
+      el("td", {}, labelValue(d.labels, "creator:")),
+      el("td", {}, labelValue(d.labels, "date:")),
+      el("td", {}, labelValue(d.labels, "language:")),
+      el("td", { class: "tag" }, d.id)));
+
+  show(
+    el("h1", {}, "Documents"),
+    el("p", { class: "meta" }, `${filtered.length} of ${all.length} documents. Click a label to filter.`),
+    chips,
+    el("table", {},
+      el("tr", {}, ["Title", "Creator", "Date", "Lang", "ID"].map((h) => el("th", {}, h))),
+      rows));
+}
+
+async function viewDoc(id, params) {
+  const start = Math.max(0, parseInt(params.get("start") || "0", 10) || 0);
+  const [all, sentences] = await Promise.all([
+    docs(),
+    api(`/api/docs/${encodeURIComponent(id)}?start=${start}&count=${PAGE_SIZE}`),
+  ]);
+  const doc = all.find((d) => d.id === id) || { id, labels: [] };
+
+  const list = el("ol", { class: "sentences", start: start });
+  for (const s of sentences) {
+    list.append(el("li", { value: s.id }, sentenceLink(id, s.id, sentenceNode(s.tokens))));
+  }
+
+  const base = `#/doc/${encodeURIComponent(id)}?start=`;
+  const pager = el("div", { class: "pager" },
+    start > 0 ? el("a", { href: base + Math.max(0, start - PAGE_SIZE) }, "← previous") : null,
+    sentences.length === PAGE_SIZE ? el("a", { href: base + (start + PAGE_SIZE) }, "next →") : null);
+
+  show(
+    el("h1", {}, docTitle(doc)),
+    el("p", { class: "meta" }, [labelValue(doc.labels, "creator:"), labelValue(doc.labels, "date:"), id].filter(Boolean).join(" · ")),
+    sentences.length ? list : el("p", { class: "empty" }, "No sentences."),
+    pager);
+}
+
+async function viewSentence(id, sid) {
+  const base = `/api/docs/${encodeURIComponent(id)}/sentences/${sid}`;
+  const userParam = user() ? `?user=${encodeURIComponent(user())}` : "";
+  const [all, s, matches] = await Promise.all([docs(), api(base), api(`${base}/topics${userParam}`)]);
+  const doc = all.find((d) => d.id === id) || { id, labels: [] };
+
+  const tokens = el("table", {},
+    el("tr", {}, ["Text", "Lemma", "POS", "Id", "Head", "Dep", "Tag"].map((h) => el("th", {}, h))),
+    s.tokens.map((t) =>
+      el("tr", {},
+        el("td", {}, t.text),
+        el("td", {}, t.lemma),
+        el("td", {}, t.pos),
+        el("td", { class: "num" }, String(t.id)),
+        el("td", { class: "num" }, String(t.head)),
+        el("td", {}, t.dep),
+        el("td", { class: "tag" }, t.tag))));
+
+  const topics = el("ol", { class: "sentences" });
+  for (const m of matches) {
+    topics.append(el("li", {},
+      el("span", { class: "topic" }, m.topic_name), " · ", el("code", {}, m.expr), el("br"),
+      sentenceNode(s.tokens, m.tokens)));
+  }
+
+  const docLink = `#/doc/${encodeURIComponent(id)}?start=${Math.floor(sid / PAGE_SIZE) * PAGE_SIZE}`;
+  const sentBase = `#/sent/${encodeURIComponent(id)}/`;
+
+  show(
+    el("h1", {}, el("a", { href: docLink }, docTitle(doc)), ` · ${sid}`),
+    el("p", {}, sentenceNode(s.tokens)),
+    el("div", { class: "pager" },
+      sid > 0 ? el("a", { href: sentBase + (sid - 1) }, "← previous") : null,
+      el("a", { href: sentBase + (sid + 1) }, "next →")),
+    el("h2", {}, "Tokens"),
+    tokens,
+    el("h2", {}, "Topics"),
+    matches.length ? topics : el("p", { class: "empty" }, "No topic matches this sentence."));
+}
+
+async function viewSearch(params) {
+  const [all, lib] = await Promise.all([docs(), api(topicsPath())]);
+
+  const labels = new Set();
+  for (const d of all) {
+    d.labels.forEach((l) => labels.add(l));
+  }
+
+  const topicSelect = el("select", { name: "topic" },
+    el("option", { value: "" }, "—"),
+    lib.map((t) => el("option", { value: t.name, selected: t.name === params.get("topic") }, t.name)));
+
+  const labelSelect = el("select", { name: "label" },
+    el("option", { value: "" }, "—"),
+    [...labels].sort().map((l) => el("option", { value: l, selected: l === params.get("label") }, l)));
+
+  const exprInput = el("input", { name: "expr", value: params.get("expr") || "", placeholder: "tomar 3 mano", size: 40 });
+
+  const form = el("form", {
+    class: "search",
+    onsubmit: (e) => {
+      e.preventDefault();
+      const q = new URLSearchParams();
+      for (const [k, v] of new FormData(form)) {
+        if (v) {
+          q.set(k, v);
+        }
+      }
+      location.hash = `#/search?${q}`;
+    },
+  },
+  el("label", {}, "Topic"), topicSelect,
+  el("label", {}, "Expression"), exprInput,
+  el("label", {}, "Label"), labelSelect,
+  el("button", { type: "submit" }, "Search"));
+
+  const results = el("div");
+  show(el("h1", {}, "Search"), form, results);
+
+  if (!params.get("topic") && !params.get("expr")) {
+    results.append(el("p", { class: "empty" }, "Choose a topic, an expression or both. With both, the expression must also match."));
+    return;
+  }
+
+  const titles = new Map(all.map((d) => [d.id, docTitle(d)]));
+  const list = el("ol", { class: "sentences" });
+  const status = el("p", { class: "meta" });
+  const more = el("button", { type: "button" }, "More");
+  results.append(status, list, el("div", { class: "pager" }, more));
+
+  let cursor = 0;
+  let total = 0;
+
+  async function fetchPage() {
+    more.disabled = true;
+    const q = new URLSearchParams();
+    for (const k of ["topic", "expr", "label"]) {
+      if (params.get(k)) {
+        q.set(k, params.get(k));
+      }
+    }
+    if (user()) {
+      q.set("user", user());
+    }
+    q.set("limit", PAGE_SIZE);
+    q.set("cursor", cursor);
+
+    const res = await api(`/api/find?${q}`);
+    for (const m of res.matches) {
+      const s = m.sentence;
+      list.append(el("li", {},
+        el("span", { class: "meta" }, titles.get(s.doc_id) || s.doc_id, ` · ${s.id}`),
+        m.topic_name ? [" · ", el("span", { class: "topic" }, m.topic_name)] : null,
+        " · ", el("code", {}, m.expr), el("br"),
+        sentenceLink(s.doc_id, s.id, sentenceNode(s.tokens, m.tokens))));
+    }
+
+    total += res.matches.length;
+    cursor = res.cursor;
+    status.textContent = `${total} matches${res.exhausted ? "" : " so far"}.`;
+    more.hidden = res.exhausted;
+    more.disabled = false;
+  }
+
+  more.addEventListener("click", () => fetchPage().catch((err) => status.replaceChildren(el("span", { class: "error" }, err.message))));
+  await fetchPage();
+}
+
+// --- router ---
+
+async function route() {
+  const hash = location.hash.slice(1) || "/docs";
+  const [path, query] = hash.split("?");
+  const params = new URLSearchParams(query || "");
+  const parts = path.split("/").filter(Boolean).map(decodeURIComponent);
+
+  try {
+    switch (parts[0]) {
+      case "doc":
+        return await viewDoc(parts[1], params);
+      case "sent":
+        return await viewSentence(parts[1], parseInt(parts[2], 10));
+      case "search":
+        return await viewSearch(params);
+      default:
+        return await viewDocs(params);
+    }
+  } catch (err) {
+    showError(err);
+  }
+}
+
+window.addEventListener("hashchange", route);
+route();
@@ -0,0 +1,99 @@
+package stat
+
+import "math"
+
+// Contingency is the 2x2 table of the co-occurrence of two items in a sample
+// of N units (sentences, tokens):
+//
+//	        B        not B
+//	A       O11      R1-O11
+//	not A   C1-O11   N-R1-C1+O11
+type Contingency struct {
+	O11 float64 // units with A and B
+	R1  float64 // units with A
+	C1  float64 // units with B
+	N   float64 // all units
+}
+
+// E11 returns the expected number of units with A and B if they were
+// independent.
+func (c Contingency) E11() float64 {
+	if c.N == 0 {
+		return 0
+	}
+	return c.R1 * c.C1 / c.N
+}
+
+// PMI returns the pointwise mutual information log2(O11/E11).
+func (c Contingency) PMI() float64 {
+	e := c.E11()
+	if c.O11 == 0 || e == 0 {
+		return math.Inf(-1)
+	}
+	return math.Log2(c.O11 / e)
+}
+
+// TScore returns (O11-E11)/sqrt(O11).
+func (c Contingency) TScore() float64 {
+	if c.O11 == 0 {
+		return 0
+	}
+	return (c.O11 - c.E11()) / math.Sqrt(c.O11)
+}
+
+// LogLikelihood returns Dunning's G² over the four cells of the table.
+func (c Contingency) LogLikelihood() float64 {
+	if c.N == 0 {
+		return 0
+	}
+
+	observed := [4]float64{
+		c.O11,
+		c.R1 - c.O11,
+		c.C1 - c.O11,
+		c.N - c.R1 - c.C1 + c.O11,
+	}
+	rows := [2]float64{c.R1, c.N - c.R1}
+	cols := [2]float64{c.C1, c.N - c.C1}
+
+	var g float64
+	for i, o := range observed {
+		e := rows[i/2] * cols[i%2] / c.N
+		if o > 0 && e > 0 {
+			g += o * math.Log(o/e)
+		}
+	}
+
+	return 2 * g
+}
+
+// Keyness compares the frequency of an item in a target and a reference
+// corpus.
+type Keyness struct {
+	Target    float64 // frequency in the target corpus
+	Reference float64 // frequency in the reference corpus
+	TargetN   float64 // size of the target corpus
+	RefN      float64 // size of the reference corpus
+}
+
+// LogLikelihood returns the G² of the item between the two corpora. It is
+// positive; see LogRatio for the direction.
+func (k Keyness) LogLikelihood() float64 {
+	return Contingency{
+		O11: k.Target,
+		R1:  k.TargetN,
+		C1:  k.Target + k.Reference,
+		N:   k.TargetN + k.RefN,
+	}.LogLikelihood()
+}
+
+// LogRatio returns the binary log of the ratio of the relative frequencies,
+// positive when the item is more frequent in the target. A zero frequency is
+// replaced by 0.5 to keep the ratio finite.
+func (k Keyness) LogRatio() float64 {
+	if k.TargetN == 0 || k.RefN == 0 {
+		return 0
+	}
+	t, r := max(k.Target, 0.5), max(k.Reference, 0.5)
+	return math.Log2((t / k.TargetN) / (r / k.RefN))
+}
@@ -0,0 +1,49 @@
+package stat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestContingencyIndependent(t *testing.T) {
+	c := Contingency{O11: 4, R1: 20, C1: 20, N: 100}
+
+	if !almostEqual(c.E11(), 4) || !almostEqual(c.PMI(), 0) || !almostEqual(c.TScore(), 0) || !almostEqual(c.LogLikelihood(), 0) {
+		t.Fatalf("E11 = %f, PMI = %f, t = %f, LL = %f, want 4, 0, 0, 0", c.E11(), c.PMI(), c.TScore(), c.LogLikelihood())
+	}
+}
+
+func TestContingencyAttraction(t *testing.T) {
+	c := Contingency{O11: 10, R1: 20, C1: 20, N: 100}
+
+	wantLL := 2 * (10*math.Log(10.0/4) + 2*10*math.Log(10.0/16) + 70*math.Log(70.0/64))
+	if !almostEqual(c.LogLikelihood(), wantLL) {
+		t.Fatalf("LL = %f, want %f", c.LogLikelihood(), wantLL)
+	}
+	if !almostEqual(c.PMI(), math.Log2(2.5)) {
+		t.Fatalf("PMI = %f, want %f", c.PMI(), math.Log2(2.5))
+	}
+	if !almostEqual(c.TScore(), 6/math.Sqrt(10)) {
+		t.Fatalf("t = %f, want %f", c.TScore(), 6/math.Sqrt(10))
+	}
+}
+
+func TestKeyness(t *testing.T) {
+	// 20 in 1000 against 10 in 2000: four times as frequent in the target
+	k := Keyness{Target: 20, Reference: 10, TargetN: 1000, RefN: 2000}
+
+	if !almostEqual(k.LogRatio(), 2) {
+		t.Fatalf("LogRatio = %f, want 2", k.LogRatio())
+	}
+
+	want := Contingency{O11: 20, R1: 1000, C1: 30, N: 3000}.LogLikelihood()
+	if k.LogLikelihood() <= 0 || !almostEqual(k.LogLikelihood(), want) {
+		t.Fatalf("LogLikelihood = %f, want %f", k.LogLikelihood(), want)
+	}
+
+	// Swapping the corpora reverses the ratio, not the G²
+	swapped := Keyness{Target: 10, Reference: 20, TargetN: 2000, RefN: 1000}
+	if !almostEqual(swapped.LogRatio(), -2) || !almostEqual(swapped.LogLikelihood(), want) {
+		t.Fatalf("swapped: LogRatio = %f, LogLikelihood = %f", swapped.LogRatio(), swapped.LogLikelihood())
+	}
+}
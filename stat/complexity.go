@@ -0,0 +1,94 @@
+package stat
+
+import (
+	"strings"
+
+	sent "github.com/revelaction/segrob/sentence"
+)
+
+// clauseDeps are the UD relations heading a subordinate clause. Subtypes
+// (acl:relcl) count as their base relation.
+var clauseDeps = map[string]bool{
+	"csubj": true,
+	"ccomp": true,
+	"xcomp": true,
+	"advcl": true,
+	"acl":   true,
+}
+
+// Complexity describes the syntactic complexity and the readability of a
+// sentence. The words of a multi-word token count as one surface token.
+type Complexity struct {
+	Tokens      int     // all the surface tokens, punctuation included
+	Words       int     // surface tokens that are not punctuation
+	Syllables   int     // syllables of the words
+	Clauses     int     // the main clause and the subordinate ones
+	Depth       int     // see TreeDepth
+	Readability float64 // Fernández-Huerta: higher is easier
+}
+
+// Measure returns the complexity of the tokens of a sentence.
+func Measure(tokens []sent.Token) Complexity {
+	words := sent.Surface(tokens)
+	c := Complexity{Tokens: len(words), Clauses: 1, Depth: TreeDepth(tokens)}
+	for _, w := range words {
+		// spaCy repeats the relation in the words it splits
+		deps := map[string]bool{}
+		for _, t := range w {
+			dep, _, _ := strings.Cut(t.Dep, ":")
+			if clauseDeps[dep] && !deps[dep] {
+				deps[dep] = true
+				c.Clauses++
+			}
+		}
+		if w[0].Pos == "PUNCT" || w[0].Pos == "SPACE" {
+			continue
+		}
+		c.Words++
+		c.Syllables += Syllables(w[0].Text)
+	}
+
+	c.Readability = FernandezHuerta(c.Syllables, c.Words, 1)
+	return c
+}
+
+// FernandezHuerta returns the Fernández-Huerta readability of a Spanish text,
+// 206.84 - 0.60 P - 1.02 F, with P the syllables and F the sentences per 100
+// words. Scores above 90 are very easy; below 30 very difficult.
+func FernandezHuerta(syllables, words, sentences int) float64 {
+	if words == 0 {
+		return 0
+	}
+	p := 100 * float64(syllables) / float64(words)
+	f := 100 * float64(sentences) / float64(words)
+	return 206.84 - 0.60*p - 1.02*f
+}
+
+// Syllables returns the number of syllables of a Spanish word, counting the
+// vowel nuclei: diphthongs and triphthongs are one nucleus, two strong
+// vowels (a, e, o or an accented i, u) are a hiatus. Words without vowels
+// (numbers, symbols) have one syllable.
+func Syllables(word string) int {
+	n := 0
+	prev := rune(0) // previous vowel, 0 after a consonant
+	for _, r := range strings.ToLower(word) {
+		if !isVowel(r) {
+			prev = 0
+			continue
+		}
+		if prev == 0 || (isStrongVowel(prev) && isStrongVowel(r)) {
+			n++
+		}
+		prev = r
+	}
+
+	return max(n, 1)
+}
+
+func isVowel(r rune) bool {
+	return strings.ContainsRune("aeiouáéíóúü", r)
+}
+
+func isStrongVowel(r rune) bool {
+	return strings.ContainsRune("aeoáéóíú", r)
+}
@@ -0,0 +1,62 @@
+package stat
+
+import (
+	"testing"
+
+	sent "github.com/revelaction/segrob/sentence"
+)
+
+func TestSyllables(t *testing.T) {
+	cases := map[string]int{
+		"casa":       2,
+		"ciudad":     2, // diphthong
+		"leer":       2, // hiatus
+		"país":       2, // accented weak vowel
+		"murciélago": 4,
+		"Buey":       1,
+		"1944":       1,
+	}
+	for word, want := range cases {
+		if got := Syllables(word); got != want {
+			t.Fatalf("Syllables(%q) = %d, want %d", word, got, want)
+		}
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	// "Dijo que vendría." with "vendría" as a ccomp of "dijo"
+	tokens := []sent.Token{
+		{Index: 0, Text: "Dijo", Pos: "VERB", Dep: "root", Head: 0},
+		{Index: 1, Text: "que", Pos: "SCONJ", Dep: "mark", Head: 2},
+		{Index: 2, Text: "vendría", Pos: "VERB", Dep: "ccomp", Head: 0},
+		{Index: 3, Text: ".", Pos: "PUNCT", Dep: "punct", Head: 0},
+	}
+
+	c := Measure(tokens)
+	if c.Tokens != 4 || c.Words != 3 || c.Syllables != 6 || c.Clauses != 2 || c.Depth != 2 {
+		t.Fatalf("Measure = %+v, want 4 tokens, 3 words, 6 syllables, 2 clauses, depth 2", c)
+	}
+	// 206.84 - 0.60*200 - 1.02*100/3
+	if !almostEqual(c.Readability, 52.84) {
+		t.Fatalf("Readability = %f, want 52.84", c.Readability)
+	}
+}
+
+func TestMeasureMultiWord(t *testing.T) {
+	// "Dámelo, dijo." as split by nlp_spacy.py: the words of "Dámelo" repeat
+	// the surface token, heads count surface tokens
+	tokens := []sent.Token{
+		{Index: 0, Idx: 0, Text: "Dámelo", Pos: "VERB", Dep: "ccomp", Head: 2},
+		{Index: 1, Idx: 0, Text: "Dámelo", Pos: "VERB", Dep: "ccomp", Head: 2},
+		{Index: 2, Idx: 0, Text: "Dámelo", Pos: "VERB", Dep: "ccomp", Head: 2},
+		{Index: 3, Idx: 6, Text: ",", Pos: "PUNCT", Dep: "punct", Head: 0},
+		{Index: 4, Idx: 8, Text: "dijo", Pos: "VERB", Dep: "ROOT", Head: 2},
+		{Index: 5, Idx: 12, Text: ".", Pos: "PUNCT", Dep: "punct", Head: 2},
+	}
+
+	c := Measure(tokens)
+	// dijo -> Dámelo -> its other words and the comma
+	if c.Tokens != 4 || c.Words != 2 || c.Syllables != 5 || c.Clauses != 2 || c.Depth != 2 {
+		t.Fatalf("Measure = %+v, want 4 tokens, 2 words, 5 syllables, 2 clauses, depth 2", c)
+	}
+}
@@ -0,0 +1,88 @@
+package stat
+
+import "math"
+
+// Dispersion describes how evenly the hits of a linguistic item are spread
+// over a text divided into parts.
+type Dispersion struct {
+	Hits  int
+	Parts []int // hits per part
+
+	// JuillandD is Juilland's D, from 0 (all hits in one part) to 1
+	// (perfectly even). It is computed on the frequencies relative to the
+	// part sizes.
+	JuillandD float64
+
+	// DP is Gries' deviation of proportions, from 0 (perfectly even) to
+	// almost 1. DPNorm is DP divided by its maximum for the part sizes, so
+	// that it reaches 1.
+	DP     float64
+	DPNorm float64
+}
+
+// Disperse computes the dispersion of the hits at the given positions of a
+// text of size positions divided into the given number of parts. Positions
+// outside [0, size) are ignored. The part sizes differ by at most one
+// position when size is not a multiple of parts.
+func Disperse(positions []int, size, parts int) Dispersion {
+	if size <= 0 || parts <= 0 {
+		return Dispersion{}
+	}
+	parts = min(parts, size)
+
+	d := Dispersion{Parts: make([]int, parts)}
+	for _, p := range positions {
+		if p < 0 || p >= size {
+			continue
+		}
+		d.Parts[p*parts/size]++
+		d.Hits++
+	}
+
+	if d.Hits == 0 {
+		return d
+	}
+
+	// Part i covers the positions [ceil(i*size/parts), ceil((i+1)*size/parts))
+	sizes := make([]float64, parts)
+	for i := range parts {
+		sizes[i] = float64(ceilDiv((i+1)*size, parts) - ceilDiv(i*size, parts))
+	}
+
+	// Juilland's D = 1 - V/sqrt(n-1), V the coefficient of variation
+	if parts > 1 {
+		var mean float64
+		freqs := make([]float64, parts)
+		for i, v := range d.Parts {
+			freqs[i] = float64(v) / sizes[i]
+			mean += freqs[i]
+		}
+		mean /= float64(parts)
+
+		var variance float64
+		for _, f := range freqs {
+			variance += (f - mean) * (f - mean)
+		}
+		sd := math.Sqrt(variance / float64(parts))
+
+		d.JuillandD = max(0, 1-(sd/mean)/math.Sqrt(float64(parts-1)))
+	}
+
+	// DP = sum of |observed - expected proportions| / 2
+	minSize := 1.0
+	for i, v := range d.Parts {
+		expected := sizes[i] / float64(size)
+		d.DP += math.Abs(float64(v)/float64(d.Hits) - expected)
+		minSize = min(minSize, expected)
+	}
+	d.DP /= 2
+	if minSize < 1 {
+		d.DPNorm = d.DP / (1 - minSize)
+	}
+
+	return d
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
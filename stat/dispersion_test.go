@@ -0,0 +1,64 @@
+package stat
+
+import (
+	"math"
+	"testing"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-4
+}
+
+func TestDisperseEven(t *testing.T) {
+	d := Disperse([]int{5, 15, 25, 35, 45, 55, 65, 75, 85, 95}, 100, 10)
+
+	if d.Hits != 10 {
+		t.Fatalf("Hits = %d, want 10", d.Hits)
+	}
+	if !almostEqual(d.JuillandD, 1) || !almostEqual(d.DP, 0) || !almostEqual(d.DPNorm, 0) {
+		t.Fatalf("D = %f, DP = %f, DPNorm = %f, want 1, 0, 0", d.JuillandD, d.DP, d.DPNorm)
+	}
+}
+
+func TestDisperseClustered(t *testing.T) {
+	d := Disperse([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, 100, 10)
+
+	if d.Parts[0] != 10 {
+		t.Fatalf("Parts = %v, want all hits in the first part", d.Parts)
+	}
+	if !almostEqual(d.JuillandD, 0) || !almostEqual(d.DP, 0.9) || !almostEqual(d.DPNorm, 1) {
+		t.Fatalf("D = %f, DP = %f, DPNorm = %f, want 0, 0.9, 1", d.JuillandD, d.DP, d.DPNorm)
+	}
+}
+
+func TestDisperseGradient(t *testing.T) {
+	// 1, 2, 3, 4 and 5 hits in five parts of 10 positions
+	positions := []int{0, 10, 11, 20, 21, 22, 30, 31, 32, 33, 40, 41, 42, 43, 44}
+	d := Disperse(positions, 50, 5)
+
+	if !almostEqual(d.JuillandD, 1-math.Sqrt(2)/3/2) {
+		t.Fatalf("D = %f, want %f", d.JuillandD, 1-math.Sqrt(2)/3/2)
+	}
+	if !almostEqual(d.DP, 0.2) || !almostEqual(d.DPNorm, 0.25) {
+		t.Fatalf("DP = %f, DPNorm = %f, want 0.2, 0.25", d.DP, d.DPNorm)
+	}
+}
+
+func TestDisperseUnequalParts(t *testing.T) {
+	// Parts of 3, 2 and 2 positions, one hit per position
+	d := Disperse([]int{0, 1, 2, 3, 4, 5, 6, 99, -1}, 7, 3)
+
+	if d.Hits != 7 || d.Parts[0] != 3 || d.Parts[1] != 2 || d.Parts[2] != 2 {
+		t.Fatalf("Hits = %d, Parts = %v, want 7, [3 2 2]", d.Hits, d.Parts)
+	}
+	if !almostEqual(d.JuillandD, 1) || !almostEqual(d.DP, 0) {
+		t.Fatalf("D = %f, DP = %f, want 1, 0", d.JuillandD, d.DP)
+	}
+}
+
+func TestDisperseNoHits(t *testing.T) {
+	d := Disperse(nil, 100, 10)
+	if d.Hits != 0 || d.JuillandD != 0 || d.DP != 0 {
+		t.Fatalf("dispersion = %+v, want zero values", d)
+	}
+}
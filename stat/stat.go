@@ -1,41 +1,190 @@
 package stat
 
 import (
+	"sort"
+	"strings"
+
 	sent "github.com/revelaction/segrob/sentence"
 )
 
+// MATTRWindow is the number of words of the moving window of the MATTR.
+const MATTRWindow = 50
+
+// TopLemmasLen is the number of lemmas in Stats.TopLemmas.
+const TopLemmasLen = 20
+
+// dialogueMarks open a line of dialogue: the Spanish raya, dashes and quotes.
+var dialogueMarks = []string{"—", "–", "-", "«", "“", "\""}
+
+// Handler accumulates the statistics of the sentences passed to Aggregate,
+// so that a corpus can be read in batches.
 type Handler struct {
-	stats Stats
+	stats  Stats
+	forms  map[string]int
+	lemmas map[string]int
+
+	// window holds the last MATTRWindow word forms, windowForms their counts
+	window      []string
+	windowForms map[string]int
+	mattrSum    float64
+	mattrN      int
 }
 
+// Stats describes a set of sentences. Words are the tokens that are not
+// punctuation; types are their distinct lowercased forms.
 type Stats struct {
-	NumSentences          int
-	NumTokens             int
-	TokensPerSentenceMean int
-	TokensPerSentenceDis  map[int]int
+	NumSentences          int         `json:"sentences"`
+	NumTokens             int         `json:"tokens"`
+	TokensPerSentenceMean int         `json:"tokens_per_sentence_mean"`
+	TokensPerSentenceDis  map[int]int `json:"tokens_per_sentence"`
+
+	NumWords int     `json:"words"`
+	NumTypes int     `json:"types"`
+	TTR      float64 `json:"ttr"`
+	MATTR    float64 `json:"mattr"`
+
+	PosDis    map[string]int `json:"pos"`
+	DepthDis  map[int]int    `json:"tree_depth"`
+	TopLemmas []LemmaCount   `json:"top_lemmas"`
+
+	DialogueSentences int     `json:"dialogue_sentences"`
+	DialogueRatio     float64 `json:"dialogue_ratio"`
+}
+
+// LemmaCount is the number of words of a lemma.
+type LemmaCount struct {
+	Lemma string `json:"lemma"`
+	Count int    `json:"count"`
 }
 
+// Get returns the statistics of the sentences aggregated so far.
 func (h *Handler) Get() Stats {
-	return h.stats
+	stats := h.stats
+	if stats.NumSentences > 0 {
+		stats.TokensPerSentenceMean = stats.NumTokens / stats.NumSentences
+		stats.DialogueRatio = float64(stats.DialogueSentences) / float64(stats.NumSentences)
+	}
+
+	stats.NumTypes = len(h.forms)
+	if stats.NumWords > 0 {
+		stats.TTR = float64(stats.NumTypes) / float64(stats.NumWords)
+	}
+
+	// Texts shorter than the window have a single window: the whole text
+	stats.MATTR = stats.TTR
+	if h.mattrN > 0 {
+		stats.MATTR = h.mattrSum / float64(h.mattrN)
+	}
+
+	stats.TopLemmas = make([]LemmaCount, 0, len(h.lemmas))
+	for lemma, n := range h.lemmas {
+		stats.TopLemmas = append(stats.TopLemmas, LemmaCount{Lemma: lemma, Count: n})
+	}
+	sort.Slice(stats.TopLemmas, func(i, j int) bool {
+		if stats.TopLemmas[i].Count != stats.TopLemmas[j].Count {
+			return stats.TopLemmas[i].Count > stats.TopLemmas[j].Count
+		}
+		return stats.TopLemmas[i].Lemma < stats.TopLemmas[j].Lemma
+	})
+	if len(stats.TopLemmas) > TopLemmasLen {
+		stats.TopLemmas = stats.TopLemmas[:TopLemmasLen]
+	}
+
+	return stats
 }
 
 func NewHandler() *Handler {
-	stats := Stats{TokensPerSentenceDis: map[int]int{}}
+	stats := Stats{
+		TokensPerSentenceDis: map[int]int{},
+		PosDis:               map[string]int{},
+		DepthDis:             map[int]int{},
+	}
 	return &Handler{
-		stats: stats,
+		stats:       stats,
+		forms:       map[string]int{},
+		lemmas:      map[string]int{},
+		windowForms: map[string]int{},
 	}
 }
 
+// Aggregate adds the sentences to the statistics. It can be called several
+// times; the words of consecutive sentences share the MATTR window.
 func (h *Handler) Aggregate(sentences []sent.Sentence) {
-	h.stats.NumSentences = len(sentences)
-	if h.stats.NumSentences == 0 {
-		return
-	}
-	//
 	for _, sentence := range sentences {
+		h.stats.NumSentences++
 		h.stats.NumTokens += len(sentence.Tokens)
 		h.stats.TokensPerSentenceDis[len(sentence.Tokens)]++
+		if len(sentence.Tokens) > 0 {
+			h.stats.DepthDis[TreeDepth(sentence.Tokens)]++
+		}
+		if isDialogue(sentence.Tokens) {
+			h.stats.DialogueSentences++
+		}
+
+		for _, t := range sentence.Tokens {
+			h.stats.PosDis[t.Pos]++
+			if t.Pos == "PUNCT" || t.Pos == "SPACE" {
+				continue
+			}
+			h.stats.NumWords++
+			h.lemmas[t.Lemma]++
+			h.addForm(strings.ToLower(t.Text))
+		}
 	}
+}
 
-	h.stats.TokensPerSentenceMean = h.stats.NumTokens / h.stats.NumSentences
+// addForm counts a word form and slides the MATTR window over it.
+func (h *Handler) addForm(form string) {
+	h.forms[form]++
+
+	h.window = append(h.window, form)
+	h.windowForms[form]++
+	if len(h.window) > MATTRWindow {
+		old := h.window[0]
+		h.window = h.window[1:]
+		if h.windowForms[old]--; h.windowForms[old] == 0 {
+			delete(h.windowForms, old)
+		}
+	}
+
+	if len(h.window) == MATTRWindow {
+		h.mattrSum += float64(len(h.windowForms)) / MATTRWindow
+		h.mattrN++
+	}
+}
+
+// TreeDepth returns the number of arcs of the longest path from the root to a
+// token of the dependency tree: 0 for a single token. Heads are read with
+// sent.Heads.
+func TreeDepth(tokens []sent.Token) int {
+	heads := sent.Heads(tokens)
+
+	depth := 0
+	for i := range heads {
+		d, j := 0, i
+		// A malformed tree may have cycles: no path is longer than the sentence
+		for d < len(heads) && heads[j] >= 0 {
+			d++
+			j = heads[j]
+		}
+		depth = max(depth, d)
+	}
+
+	return depth
+}
+
+// isDialogue reports whether the sentence opens with a dialogue mark.
+func isDialogue(tokens []sent.Token) bool {
+	for _, t := range tokens {
+		if t.Pos == "SPACE" {
+			continue
+		}
+		for _, m := range dialogueMarks {
+			if strings.HasPrefix(t.Text, m) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
 }
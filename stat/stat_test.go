@@ -0,0 +1,95 @@
+package stat
+
+import (
+	"testing"
+
+	sent "github.com/revelaction/segrob/sentence"
+)
+
+// sentence builds a sentence from (text, lemma, pos, head) tuples.
+func sentence(tokens ...[4]string) sent.Sentence {
+	var s sent.Sentence
+	for i, t := range tokens {
+		head := i
+		if t[3] != "" {
+			head = int(t[3][0] - '0')
+		}
+		s.Tokens = append(s.Tokens, sent.Token{Index: i, Text: t[0], Lemma: t[1], Pos: t[2], Head: head})
+	}
+	return s
+}
+
+func TestAggregate(t *testing.T) {
+	// "—Dijo que sí." with "Dijo" as root, "sí" under "que"
+	s1 := sentence(
+		[4]string{"—", "—", "PUNCT", "1"},
+		[4]string{"Dijo", "decir", "VERB", ""},
+		[4]string{"que", "que", "SCONJ", "3"},
+		[4]string{"sí", "sí", "ADV", "1"},
+		[4]string{".", ".", "PUNCT", "1"},
+	)
+	// "Dijo no." as a flat tree
+	s2 := sentence(
+		[4]string{"Dijo", "decir", "VERB", ""},
+		[4]string{"no", "no", "ADV", "0"},
+		[4]string{".", ".", "PUNCT", "0"},
+	)
+
+	h := NewHandler()
+	h.Aggregate([]sent.Sentence{s1})
+	h.Aggregate([]sent.Sentence{s2})
+	stats := h.Get()
+
+	if stats.NumSentences != 2 || stats.NumTokens != 8 || stats.NumWords != 5 {
+		t.Fatalf("sentences, tokens, words = %d, %d, %d, want 2, 8, 5", stats.NumSentences, stats.NumTokens, stats.NumWords)
+	}
+	if stats.TokensPerSentenceDis[5] != 1 || stats.TokensPerSentenceDis[3] != 1 {
+		t.Fatalf("TokensPerSentenceDis = %v, want 5:1 3:1", stats.TokensPerSentenceDis)
+	}
+	// dijo, que, sí, no
+	if stats.NumTypes != 4 || !almostEqual(stats.TTR, 0.8) || !almostEqual(stats.MATTR, 0.8) {
+		t.Fatalf("types, TTR, MATTR = %d, %f, %f, want 4, 0.8, 0.8", stats.NumTypes, stats.TTR, stats.MATTR)
+	}
+	if stats.PosDis["PUNCT"] != 3 || stats.PosDis["VERB"] != 2 {
+		t.Fatalf("PosDis = %v, want PUNCT:3 VERB:2", stats.PosDis)
+	}
+	if stats.DepthDis[2] != 1 || stats.DepthDis[1] != 1 {
+		t.Fatalf("DepthDis = %v, want 2:1 1:1", stats.DepthDis)
+	}
+	if stats.DialogueSentences != 1 || !almostEqual(stats.DialogueRatio, 0.5) {
+		t.Fatalf("dialogue = %d, %f, want 1, 0.5", stats.DialogueSentences, stats.DialogueRatio)
+	}
+	if stats.TopLemmas[0] != (LemmaCount{Lemma: "decir", Count: 2}) {
+		t.Fatalf("TopLemmas = %v, want decir first", stats.TopLemmas)
+	}
+}
+
+func TestMATTR(t *testing.T) {
+	// Two words alternating: every window has 2 types
+	var s sent.Sentence
+	for i := range 2 * MATTRWindow {
+		form := "a"
+		if i%2 == 1 {
+			form = "b"
+		}
+		s.Tokens = append(s.Tokens, sent.Token{Index: i, Text: form, Lemma: form, Pos: "NOUN", Head: i})
+	}
+
+	h := NewHandler()
+	h.Aggregate([]sent.Sentence{s})
+	stats := h.Get()
+
+	if !almostEqual(stats.MATTR, 2.0/MATTRWindow) {
+		t.Fatalf("MATTR = %f, want %f", stats.MATTR, 2.0/MATTRWindow)
+	}
+}
+
+func TestTreeDepthCycle(t *testing.T) {
+	s := sentence(
+		[4]string{"a", "a", "X", "1"},
+		[4]string{"b", "b", "X", "0"},
+	)
+	if d := TreeDepth(s.Tokens); d != 2 {
+		t.Fatalf("TreeDepth = %d, want 2 (the sentence length)", d)
+	}
+}
@@ -23,8 +23,8 @@ func NewCorpusStore(pool *sqlitex.Pool) *CorpusStore {
 }
 
 // Exists returns true if a record with the given ID is present in the docs table.
-func (s *CorpusStore) Exists(id string) (bool, error) {
-	conn, err := s.pool.Take(context.TODO())
+func (s *CorpusStore) Exists(ctx context.Context, id string) (bool, error) {
+	conn, err := s.pool.Take(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -43,8 +43,8 @@ func (s *CorpusStore) Exists(id string) (bool, error) {
 	return exists, err
 }
 
-func (s *CorpusStore) List() ([]storage.CorpusMeta, error) {
-	conn, err := s.pool.Take(context.TODO())
+func (s *CorpusStore) List(ctx context.Context) ([]storage.CorpusMeta, error) {
+	conn, err := s.pool.Take(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -75,8 +75,8 @@ func (s *CorpusStore) List() ([]storage.CorpusMeta, error) {
 	return records, nil
 }
 
-func (s *CorpusStore) WriteStream(seq func(yield func(storage.CorpusRecord, error) bool)) (err error) {
-	conn, err := s.pool.Take(context.TODO())
+func (s *CorpusStore) WriteStream(ctx context.Context, seq func(yield func(storage.CorpusRecord, error) bool)) (err error) {
+	conn, err := s.pool.Take(ctx)
 	if err != nil {
 		return err
 	}
@@ -89,6 +89,9 @@ func (s *CorpusStore) WriteStream(seq func(yield func(storage.CorpusRecord, erro
 		if iterErr != nil {
 			return iterErr
 		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 
 		err = sqlitex.Execute(conn,
 			`INSERT INTO corpus (id, labels, epub, txt, txt_hash, txt_created_at,
@@ -131,8 +134,8 @@ func (s *CorpusStore) WriteStream(seq func(yield func(storage.CorpusRecord, erro
 }
 
 // ReadMeta retrieves full metadata for a given document ID.
-func (s *CorpusStore) ReadMeta(id string) (storage.CorpusMeta, error) {
-	conn, err := s.pool.Take(context.TODO())
+func (s *CorpusStore) ReadMeta(ctx context.Context, id string) (storage.CorpusMeta, error) {
+	conn, err := s.pool.Take(ctx)
 	if err != nil {
 		return storage.CorpusMeta{}, err
 	}
@@ -228,8 +231,8 @@ func buildCorpusMeta(stmt *sqlite.Stmt) (storage.CorpusMeta, error) {
 }
 
 // ReadTxt retrieves the txt field for a given document ID as raw bytes.
-func (s *CorpusStore) ReadTxt(id string) ([]byte, error) {
-	conn, err := s.pool.Take(context.TODO())
+func (s *CorpusStore) ReadTxt(ctx context.Context, id string) ([]byte, error) {
+	conn, err := s.pool.Take(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -259,8 +262,8 @@ func (s *CorpusStore) ReadTxt(id string) ([]byte, error) {
 	return txt, nil
 }
 
-func (s *CorpusStore) WriteNlp(id string, nlp []byte) error {
-	conn, err := s.pool.Take(context.TODO())
+func (s *CorpusStore) WriteNlp(ctx context.Context, id string, nlp []byte) error {
+	conn, err := s.pool.Take(ctx)
 	if err != nil {
 		return err
 	}
@@ -273,8 +276,8 @@ func (s *CorpusStore) WriteNlp(id string, nlp []byte) error {
 		})
 }
 
-func (s *CorpusStore) ReadNlp(id string) ([]byte, error) {
-	conn, err := s.pool.Take(context.TODO())
+func (s *CorpusStore) ReadNlp(ctx context.Context, id string) ([]byte, error) {
+	conn, err := s.pool.Take(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -306,8 +309,8 @@ func (s *CorpusStore) ReadNlp(id string) ([]byte, error) {
 }
 
 // ListLabels returns all labels (unique names) found in the corpus.
-func (s *CorpusStore) ListLabels(labelSubStr string) ([]string, error) {
-	conn, err := s.pool.Take(context.TODO())
+func (s *CorpusStore) ListLabels(ctx context.Context, labelSubStr string) ([]string, error) {
+	conn, err := s.pool.Take(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -335,8 +338,8 @@ func (s *CorpusStore) ListLabels(labelSubStr string) ([]string, error) {
 	return keys, nil
 }
 
-func (s *CorpusStore) ClearNlp(id string) error {
-	conn, err := s.pool.Take(context.TODO())
+func (s *CorpusStore) ClearNlp(ctx context.Context, id string) error {
+	conn, err := s.pool.Take(ctx)
 	if err != nil {
 		return err
 	}
@@ -349,8 +352,8 @@ func (s *CorpusStore) ClearNlp(id string) error {
 		})
 }
 
-func (s *CorpusStore) UpdateTxt(id string, txt []byte, txtHash string, by string, notes string) error {
-	conn, err := s.pool.Take(context.TODO())
+func (s *CorpusStore) UpdateTxt(ctx context.Context, id string, txt []byte, txtHash string, by string, notes string) error {
+	conn, err := s.pool.Take(ctx)
 	if err != nil {
 		return err
 	}
@@ -371,8 +374,8 @@ func (s *CorpusStore) UpdateTxt(id string, txt []byte, txtHash string, by string
 		})
 }
 
-func (s *CorpusStore) AckTxt(id string, by string) error {
-	conn, err := s.pool.Take(context.TODO())
+func (s *CorpusStore) AckTxt(ctx context.Context, id string, by string) error {
+	conn, err := s.pool.Take(ctx)
 	if err != nil {
 		return err
 	}
@@ -390,8 +393,8 @@ func (s *CorpusStore) AckTxt(id string, by string) error {
 		})
 }
 
-func (s *CorpusStore) AckNlp(id string, by string) error {
-	conn, err := s.pool.Take(context.TODO())
+func (s *CorpusStore) AckNlp(ctx context.Context, id string, by string) error {
+	conn, err := s.pool.Take(ctx)
 	if err != nil {
 		return err
 	}
@@ -410,8 +413,8 @@ func (s *CorpusStore) AckNlp(id string, by string) error {
 }
 
 // AddLabel adds labels to a document in the corpus.
-func (s *CorpusStore) AddLabel(id string, labels ...string) (err error) {
-	conn, err := s.pool.Take(context.TODO())
+func (s *CorpusStore) AddLabel(ctx context.Context, id string, labels ...string) (err error) {
+	conn, err := s.pool.Take(ctx)
 	if err != nil {
 		return err
 	}
@@ -464,8 +467,8 @@ func (s *CorpusStore) AddLabel(id string, labels ...string) (err error) {
 }
 
 // DeleteLabel deletes labels from a document in the corpus.
-func (s *CorpusStore) DeleteLabel(id string, labels ...string) (err error) {
-	conn, err := s.pool.Take(context.TODO())
+func (s *CorpusStore) DeleteLabel(ctx context.Context, id string, labels ...string) (err error) {
+	conn, err := s.pool.Take(ctx)
 	if err != nil {
 		return err
 	}
@@ -520,8 +523,8 @@ func (s *CorpusStore) DeleteLabel(id string, labels ...string) (err error) {
 }
 
 // Delete removes a document from the corpus by its ID.
-func (s *CorpusStore) Delete(id string) error {
-	conn, err := s.pool.Take(context.TODO())
+func (s *CorpusStore) Delete(ctx context.Context, id string) error {
+	conn, err := s.pool.Take(ctx)
 	if err != nil {
 		return err
 	}
@@ -20,6 +20,13 @@ type DocStore struct {
 
 var _ storage.DocRepository = (*DocStore)(nil)
 
+// liveDocs selects the IDs of the live documents, those past the live switch
+// of publish (sentence_lemmas rows). The rows written before the switch, or
+// left by an interrupted unpublish, belong to documents that are not live.
+const liveDocs = `SELECT id FROM docs WHERE EXISTS (
+	SELECT 1 FROM sentence_lemmas
+	WHERE sentence_rowid IN (SELECT rowid FROM sentences WHERE doc_id = docs.id))`
+
 func NewDocStore(pool *sqlitex.Pool) *DocStore {
 	return &DocStore{pool: pool}
 }
@@ -59,8 +66,8 @@ func (h *DocStore) insertLemmaOptimize(conn *sqlite.Conn, sentenceRowID int64, l
 	})
 }
 
-func (h *DocStore) List() ([]sent.Meta, error) {
-	conn, err := h.pool.Take(context.TODO())
+func (h *DocStore) List(ctx context.Context) ([]sent.Meta, error) {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -97,8 +104,8 @@ func (h *DocStore) List() ([]sent.Meta, error) {
 	return metas, nil
 }
 
-func (h *DocStore) Nlp(id string, sentenceStartIndex int, sentenceOffset *int) ([]sent.Sentence, error) {
-	conn, err := h.pool.Take(context.TODO())
+func (h *DocStore) Nlp(ctx context.Context, id string, sentenceStartIndex int, sentenceOffset *int) ([]sent.Sentence, error) {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -143,8 +150,8 @@ func (h *DocStore) Nlp(id string, sentenceStartIndex int, sentenceOffset *int) (
 }
 
 // HasSentences returns true if at least one sentence exists for the given doc ID.
-func (h *DocStore) HasSentences(id string) (bool, error) {
-	conn, err := h.pool.Take(context.TODO())
+func (h *DocStore) HasSentences(ctx context.Context, id string) (bool, error) {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -164,12 +171,14 @@ func (h *DocStore) HasSentences(id string) (bool, error) {
 	return has, err
 }
 
-func (h *DocStore) FindCandidates(lemmas []string, labelIDs []int, after storage.Cursor, limit int, onCandidate func(sent.Sentence) error) (storage.Cursor, error) {
+func (h *DocStore) FindCandidates(ctx context.Context, lemmas []string, labelIDs []int, after storage.Cursor, limit int, onCandidate func(sent.Sentence) error) (storage.Cursor, error) {
 	if len(lemmas) == 0 {
 		return after, nil
 	}
 
-	conn, err := h.pool.Take(context.TODO())
+	// Take sets ctx as the interrupt of the connection: cancelling ctx
+	// aborts the running statements.
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return after, err
 	}
@@ -185,6 +194,9 @@ func (h *DocStore) FindCandidates(lemmas []string, labelIDs []int, after storage
 			return nil
 		},
 	})
+	if ctx.Err() != nil {
+		return after, ctx.Err()
+	}
 	if err != nil {
 		return after, err
 	}
@@ -193,6 +205,13 @@ func (h *DocStore) FindCandidates(lemmas []string, labelIDs []int, after storage
 		return after, nil
 	}
 
+	return fetchSentences(ctx, conn, rowIDs, after, onCandidate)
+}
+
+// fetchSentences loads the sentences of rowIDs in rowid order and passes
+// them to onSentence. It returns the rowid of the last sentence passed, or
+// after if none. onSentence may return storage.ErrStopScan to stop early.
+func fetchSentences(ctx context.Context, conn *sqlite.Conn, rowIDs []int64, after storage.Cursor, onSentence func(sent.Sentence) error) (storage.Cursor, error) {
 	// TODO: Consolidate into a single query using a subquery for better performance.
 	// For now, we use a second bulk query to fetch the sentence data.
 	idStrings := make([]string, len(rowIDs))
@@ -204,7 +223,7 @@ func (h *DocStore) FindCandidates(lemmas []string, labelIDs []int, after storage
 	bulkQuery := fmt.Sprintf("SELECT rowid, doc_id, sentence_id, data FROM sentences WHERE rowid IN (%s) ORDER BY rowid", idList)
 
 	newCursor := after
-	err = sqlitex.Execute(conn, bulkQuery, &sqlitex.ExecOptions{
+	err := sqlitex.Execute(conn, bulkQuery, &sqlitex.ExecOptions{
 		ResultFunc: func(stmt *sqlite.Stmt) error {
 			rowID := stmt.ColumnInt64(0)
 
@@ -223,10 +242,14 @@ func (h *DocStore) FindCandidates(lemmas []string, labelIDs []int, after storage
 				return err
 			}
 
-			return onCandidate(s)
+			return onSentence(s)
 		},
 	})
 
+	if ctx.Err() != nil {
+		return after, ctx.Err()
+	}
+
 	if err != nil {
 		// Intercept the graceful stop signal and return the safely updated cursor
 		if errors.Is(err, storage.ErrStopScan) {
@@ -275,8 +298,8 @@ func (h *DocStore) buildCandidateQuery(lemmas []string, labelIDs []int, after st
 	return queryBuilder.String(), args
 }
 
-func (h *DocStore) ListLabels(labelSubStr string) (sent.Labels, error) {
-	conn, err := h.pool.Take(context.TODO())
+func (h *DocStore) ListLabels(ctx context.Context, labelSubStr string) (sent.Labels, error) {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -324,8 +347,8 @@ func (h *DocStore) buildListLabelsQuery(labelSubStr string) (string, []interface
 	return queryBuilder.String(), args
 }
 
-func (h *DocStore) WriteMeta(id string, source string, labels []string) (labelIDs []int, err error) {
-	conn, err := h.pool.Take(context.TODO())
+func (h *DocStore) WriteMeta(ctx context.Context, id string, source string, labels []string) (labelIDs []int, err error) {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -358,8 +381,8 @@ func (h *DocStore) WriteMeta(id string, source string, labels []string) (labelID
 // UpdateLabels upserts labels and updates the docs row for docID.
 // Note: This operation does not remove labels from the labels table that are
 // no longer referenced by this or any other document (orphaned labels).
-func (h *DocStore) UpdateLabels(docID string, labels []string) (labelIDs []int, err error) {
-	conn, err := h.pool.Take(context.TODO())
+func (h *DocStore) UpdateLabels(ctx context.Context, docID string, labels []string) (labelIDs []int, err error) {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -389,8 +412,8 @@ func (h *DocStore) UpdateLabels(docID string, labels []string) (labelIDs []int,
 	return labelIDs, nil
 }
 
-func (h *DocStore) WriteNlpData(docID string, sentences []storage.SentenceIngest) (err error) {
-	conn, err := h.pool.Take(context.TODO())
+func (h *DocStore) WriteNlpData(ctx context.Context, docID string, sentences []storage.SentenceIngest) (err error) {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return err
 	}
@@ -408,8 +431,8 @@ func (h *DocStore) WriteNlpData(docID string, sentences []storage.SentenceIngest
 }
 
 // Signature change: receives labelIDs directly from WriteMeta
-func (h *DocStore) WriteLabelsOptimization(docID string, labelIDs []int) (err error) {
-	conn, err := h.pool.Take(context.TODO())
+func (h *DocStore) WriteLabelsOptimization(ctx context.Context, docID string, labelIDs []int) (err error) {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return err
 	}
@@ -432,8 +455,11 @@ func (h *DocStore) WriteLabelsOptimization(docID string, labelIDs []int) (err er
 	return nil
 }
 
-func (h *DocStore) WriteLemmaOptimization(docID string, sentences []storage.SentenceIngest) (err error) {
-	conn, err := h.pool.Take(context.TODO())
+// WriteLemmaOptimization inserts the sentence_lemmas rows of docID and its
+// lemma frequencies in the same transaction, so that a live document always
+// has them.
+func (h *DocStore) WriteLemmaOptimization(ctx context.Context, docID string, sentences []storage.SentenceIngest, freqs []storage.LemmaFreq) (err error) {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return err
 	}
@@ -465,12 +491,15 @@ func (h *DocStore) WriteLemmaOptimization(docID string, sentences []storage.Sent
 			return nil
 		},
 	})
+	if err != nil {
+		return err
+	}
 
-	return err
+	return insertLemmaFreq(conn, docID, freqs)
 }
 
-func (h *DocStore) HasLabelsOptimization(id string) (bool, error) {
-	conn, err := h.pool.Take(context.TODO())
+func (h *DocStore) HasLabelsOptimization(ctx context.Context, id string) (bool, error) {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -488,8 +517,8 @@ func (h *DocStore) HasLabelsOptimization(id string) (bool, error) {
 	return has, err
 }
 
-func (h *DocStore) HasLemmaOptimization(id string) (bool, error) {
-	conn, err := h.pool.Take(context.TODO())
+func (h *DocStore) HasLemmaOptimization(ctx context.Context, id string) (bool, error) {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -508,8 +537,8 @@ func (h *DocStore) HasLemmaOptimization(id string) (bool, error) {
 }
 
 // Exists returns true if a document with the given ID is present in the docs table.
-func (h *DocStore) Exists(id string) (bool, error) {
-	conn, err := h.pool.Take(context.TODO())
+func (h *DocStore) Exists(ctx context.Context, id string) (bool, error) {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -531,8 +560,8 @@ func (h *DocStore) Exists(id string) (bool, error) {
 // SentenceRowidRange returns the min and max rowid for a specific label.
 // It uses subqueries to exploit the existing idx_label_rowid index and
 // avoid the query planner pitfall of a full table scan.
-func (h *DocStore) SentenceRowidRange(labelID int) (minRowid int64, maxRowid int64, err error) {
-	conn, err := h.pool.Take(context.TODO())
+func (h *DocStore) SentenceRowidRange(ctx context.Context, labelID int) (minRowid int64, maxRowid int64, err error) {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -556,26 +585,399 @@ func (h *DocStore) SentenceRowidRange(labelID int) (minRowid int64, maxRowid int
 	return minRowid, maxRowid, nil
 }
 
-// DeleteLemmaOptimization removes sentence_lemmas rows for docID.
-func (h *DocStore) DeleteLemmaOptimization(docID string) error {
-	conn, err := h.pool.Take(context.TODO())
+// SentenceCounts returns the number of sentences of each live document.
+// The count is read from idx_sentences_doc_sentence without touching the
+// sentence data.
+func (h *DocStore) SentenceCounts(ctx context.Context) (map[string]int, error) {
+	conn, err := h.pool.Take(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer h.pool.Put(conn)
+
+	counts := make(map[string]int)
+	err = sqlitex.Execute(conn,
+		`SELECT doc_id, COUNT(*) FROM sentences WHERE doc_id IN (`+liveDocs+`) GROUP BY doc_id`,
+		&sqlitex.ExecOptions{
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				counts[stmt.ColumnText(0)] = stmt.ColumnInt(1)
+				return nil
+			},
+		})
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// CountSentences counts the sentences of the live documents having all
+// labelIDs. The first label drives the scan of idx_label_rowid, the others
+// are EXISTS probes.
+func (h *DocStore) CountSentences(ctx context.Context, labelIDs []int) (int, error) {
+	conn, err := h.pool.Take(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer h.pool.Put(conn)
+
+	query := "SELECT COUNT(*) FROM sentences WHERE doc_id IN (" + liveDocs + ")"
+	var args []interface{}
+	if len(labelIDs) > 0 {
+		var queryBuilder strings.Builder
+		queryBuilder.WriteString("SELECT COUNT(*) FROM sentence_labels AS s_outer WHERE label_id = ?")
+		args = append(args, labelIDs[0])
+		for _, labelID := range labelIDs[1:] {
+			queryBuilder.WriteString(" AND EXISTS (SELECT 1 FROM sentence_labels WHERE sentence_rowid = s_outer.sentence_rowid AND label_id = ?)")
+			args = append(args, labelID)
+		}
+		queryBuilder.WriteString(" AND (SELECT doc_id FROM sentences WHERE rowid = s_outer.sentence_rowid) IN (" + liveDocs + ")")
+		query = queryBuilder.String()
+	}
+
+	var n int
+	err = sqlitex.Execute(conn, query, &sqlitex.ExecOptions{
+		Args: args,
+		ResultFunc: func(stmt *sqlite.Stmt) error {
+			n = stmt.ColumnInt(0)
+			return nil
+		},
+	})
+	return n, err
+}
+
+// lemmaBatch bounds the lemmas of an IN list, below the SQLite limit of
+// variables per statement.
+const lemmaBatch = 10000
+
+// LemmaSentenceCounts reads the counts from lemma_freq without labels, and
+// otherwise counts the sentences of the lemmas on idx_lemma_rowid in one
+// grouped query, the labels being EXISTS probes.
+func (h *DocStore) LemmaSentenceCounts(ctx context.Context, lemmas []string, labelIDs []int) (map[string]int, error) {
+	conn, err := h.pool.Take(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer h.pool.Put(conn)
+
+	counts := make(map[string]int, len(lemmas))
+	for len(lemmas) > 0 {
+		batch := lemmas[:min(len(lemmas), lemmaBatch)]
+		lemmas = lemmas[len(batch):]
+
+		var queryBuilder strings.Builder
+		var args []interface{}
+		if len(labelIDs) == 0 {
+			queryBuilder.WriteString("SELECT lemma, sentences FROM lemma_freq WHERE lemma IN (")
+		} else {
+			queryBuilder.WriteString("SELECT lemma, COUNT(*) FROM sentence_lemmas AS s_outer WHERE lemma IN (")
+		}
+		for i, lemma := range batch {
+			if i > 0 {
+				queryBuilder.WriteString(", ")
+			}
+			queryBuilder.WriteString("?")
+			args = append(args, lemma)
+		}
+		queryBuilder.WriteString(")")
+		if len(labelIDs) > 0 {
+			for _, labelID := range labelIDs {
+				queryBuilder.WriteString(" AND EXISTS (SELECT 1 FROM sentence_labels WHERE sentence_rowid = s_outer.sentence_rowid AND label_id = ?)")
+				args = append(args, labelID)
+			}
+			queryBuilder.WriteString(" GROUP BY lemma")
+		}
+
+		err = sqlitex.Execute(conn, queryBuilder.String(), &sqlitex.ExecOptions{
+			Args: args,
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				if n := stmt.ColumnInt(1); n > 0 {
+					counts[stmt.ColumnText(0)] = n
+				}
+				return nil
+			},
+		})
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return counts, nil
+}
+
+// LemmaCounts counts the sentences of every lemma in a label subcorpus. The
+// first label drives the scan, the lemmas are read from idx_rowid_lemma.
+func (h *DocStore) LemmaCounts(ctx context.Context, labelIDs []int) (map[string]int, error) {
+	if len(labelIDs) == 0 {
+		return nil, errors.New("lemma counts need at least one label")
+	}
+
+	conn, err := h.pool.Take(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer h.pool.Put(conn)
+
+	var queryBuilder strings.Builder
+	args := []interface{}{labelIDs[0]}
+	queryBuilder.WriteString("SELECT sl.lemma, COUNT(*) FROM sentence_labels AS s_outer JOIN sentence_lemmas AS sl ON sl.sentence_rowid = s_outer.sentence_rowid WHERE s_outer.label_id = ?")
+	for _, labelID := range labelIDs[1:] {
+		queryBuilder.WriteString(" AND EXISTS (SELECT 1 FROM sentence_labels WHERE sentence_rowid = s_outer.sentence_rowid AND label_id = ?)")
+		args = append(args, labelID)
+	}
+	queryBuilder.WriteString(" GROUP BY sl.lemma")
+
+	counts := make(map[string]int)
+	err = sqlitex.Execute(conn, queryBuilder.String(), &sqlitex.ExecOptions{
+		Args: args,
+		ResultFunc: func(stmt *sqlite.Stmt) error {
+			counts[stmt.ColumnText(0)] = stmt.ColumnInt(1)
+			return nil
+		},
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// LabelSentences pages through the sentences of the live documents of a
+// label subcorpus. The first label drives the query, like the first lemma in
+// FindCandidates.
+func (h *DocStore) LabelSentences(ctx context.Context, labelIDs []int, after storage.Cursor, limit int, onSentence func(sent.Sentence) error) (storage.Cursor, error) {
+	conn, err := h.pool.Take(ctx)
+	if err != nil {
+		return after, err
+	}
+	defer h.pool.Put(conn)
+
+	var queryBuilder strings.Builder
+	args := []interface{}{int64(after)}
+	if len(labelIDs) == 0 {
+		queryBuilder.WriteString("SELECT rowid FROM sentences WHERE rowid > ? AND doc_id IN (" + liveDocs + ") ORDER BY rowid ASC LIMIT ?")
+	} else {
+		queryBuilder.WriteString("SELECT sentence_rowid FROM sentence_labels AS s_outer WHERE sentence_rowid > ? AND label_id = ?")
+		args = append(args, labelIDs[0])
+		for _, labelID := range labelIDs[1:] {
+			queryBuilder.WriteString(" AND EXISTS (SELECT 1 FROM sentence_labels WHERE sentence_rowid = s_outer.sentence_rowid AND label_id = ?)")
+			args = append(args, labelID)
+		}
+		queryBuilder.WriteString(" AND (SELECT doc_id FROM sentences WHERE rowid = s_outer.sentence_rowid) IN (" + liveDocs + ")")
+		queryBuilder.WriteString(" ORDER BY sentence_rowid ASC LIMIT ?")
+	}
+	args = append(args, limit)
+
+	var rowIDs []int64
+	err = sqlitex.Execute(conn, queryBuilder.String(), &sqlitex.ExecOptions{
+		Args: args,
+		ResultFunc: func(stmt *sqlite.Stmt) error {
+			rowIDs = append(rowIDs, stmt.ColumnInt64(0))
+			return nil
+		},
+	})
+	if ctx.Err() != nil {
+		return after, ctx.Err()
+	}
+	if err != nil {
+		return after, err
+	}
+
+	if len(rowIDs) == 0 {
+		return after, nil
+	}
+
+	return fetchSentences(ctx, conn, rowIDs, after, onSentence)
+}
+
+// MissingLemmaFreq selects the live documents without lemma_doc_freq rows.
+func (h *DocStore) MissingLemmaFreq(ctx context.Context) ([]string, error) {
+	conn, err := h.pool.Take(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer h.pool.Put(conn)
+
+	var ids []string
+	err = sqlitex.Execute(conn,
+		`SELECT id FROM docs
+		 WHERE id IN (`+liveDocs+`)
+		 AND NOT EXISTS (SELECT 1 FROM lemma_doc_freq WHERE doc_id = docs.id)
+		 ORDER BY source`,
+		&sqlitex.ExecOptions{
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				ids = append(ids, stmt.ColumnText(0))
+				return nil
+			},
+		})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// LemmaFreqs reads lemma_freq, or sums lemma_doc_freq over docIDs. The
+// prefix is a range on the lemma key, so that it does not depend on LIKE
+// escaping.
+func (h *DocStore) LemmaFreqs(ctx context.Context, prefix string, docIDs []string, limit int) ([]storage.LemmaFreq, error) {
+	conn, err := h.pool.Take(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer h.pool.Put(conn)
+
+	var queryBuilder strings.Builder
+	var args []interface{}
+	if len(docIDs) == 0 {
+		queryBuilder.WriteString("SELECT lemma, tokens, sentences, docs FROM lemma_freq WHERE 1")
+	} else {
+		queryBuilder.WriteString("SELECT lemma, SUM(tokens), SUM(sentences), COUNT(*) FROM lemma_doc_freq WHERE doc_id IN (")
+		for i, id := range docIDs {
+			if i > 0 {
+				queryBuilder.WriteString(", ")
+			}
+			queryBuilder.WriteString("?")
+			args = append(args, id)
+		}
+		queryBuilder.WriteString(")")
+	}
+
+	if prefix != "" {
+		queryBuilder.WriteString(" AND lemma >= ? AND lemma < ?")
+		args = append(args, prefix, prefix+"\U0010FFFF")
+	}
+
+	if len(docIDs) > 0 {
+		queryBuilder.WriteString(" GROUP BY lemma ORDER BY SUM(tokens) DESC, lemma ASC")
+	} else {
+		queryBuilder.WriteString(" ORDER BY tokens DESC, lemma ASC")
+	}
+
+	if limit > 0 {
+		queryBuilder.WriteString(" LIMIT ?")
+		args = append(args, limit)
+	}
+
+	var freqs []storage.LemmaFreq
+	err = sqlitex.Execute(conn, queryBuilder.String(), &sqlitex.ExecOptions{
+		Args: args,
+		ResultFunc: func(stmt *sqlite.Stmt) error {
+			freqs = append(freqs, storage.LemmaFreq{
+				Lemma:     stmt.ColumnText(0),
+				Tokens:    stmt.ColumnInt(1),
+				Sentences: stmt.ColumnInt(2),
+				Docs:      stmt.ColumnInt(3),
+			})
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return freqs, nil
+}
+
+// WriteLemmaFreq inserts the lemma_doc_freq rows of docID and adds them to
+// lemma_freq in the same transaction.
+func (h *DocStore) WriteLemmaFreq(ctx context.Context, docID string, freqs []storage.LemmaFreq) (err error) {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return err
 	}
 	defer h.pool.Put(conn)
 
+	defer sqlitex.Save(conn)(&err)
+
+	return insertLemmaFreq(conn, docID, freqs)
+}
+
+// insertLemmaFreq inserts the lemma_doc_freq rows of docID and adds them to
+// lemma_freq. It runs within the transaction of the caller.
+func insertLemmaFreq(conn *sqlite.Conn, docID string, freqs []storage.LemmaFreq) error {
+	for _, f := range freqs {
+		err := sqlitex.Execute(conn,
+			`INSERT INTO lemma_doc_freq (doc_id, lemma, tokens, sentences) VALUES (?, ?, ?, ?)`,
+			&sqlitex.ExecOptions{Args: []interface{}{docID, f.Lemma, f.Tokens, f.Sentences}})
+		if err != nil {
+			return fmt.Errorf("failed to insert lemma frequency: %w", err)
+		}
+
+		err = sqlitex.Execute(conn,
+			`INSERT INTO lemma_freq (lemma, tokens, sentences, docs)
+			 VALUES (?, ?, ?, 1)
+			 ON CONFLICT(lemma) DO UPDATE SET
+			     tokens = tokens + excluded.tokens,
+			     sentences = sentences + excluded.sentences,
+			     docs = docs + 1`,
+			&sqlitex.ExecOptions{Args: []interface{}{f.Lemma, f.Tokens, f.Sentences}})
+		if err != nil {
+			return fmt.Errorf("failed to update lemma frequency: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// deleteLemmaFreq subtracts the lemma_doc_freq rows of docID from lemma_freq,
+// drops the lemmas left in no document and deletes the rows. It runs within
+// the transaction of the caller.
+func deleteLemmaFreq(conn *sqlite.Conn, docID string) error {
+	err := sqlitex.Execute(conn,
+		`UPDATE lemma_freq SET
+		     tokens = lemma_freq.tokens - d.tokens,
+		     sentences = lemma_freq.sentences - d.sentences,
+		     docs = lemma_freq.docs - 1
+		 FROM (SELECT lemma, tokens, sentences FROM lemma_doc_freq WHERE doc_id = ?) AS d
+		 WHERE lemma_freq.lemma = d.lemma`,
+		&sqlitex.ExecOptions{Args: []interface{}{docID}})
+	if err != nil {
+		return fmt.Errorf("failed to update lemma frequency: %w", err)
+	}
+
+	err = sqlitex.Execute(conn, `DELETE FROM lemma_freq WHERE docs <= 0`, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete lemma frequency: %w", err)
+	}
+
+	err = sqlitex.Execute(conn, `DELETE FROM lemma_doc_freq WHERE doc_id = ?`,
+		&sqlitex.ExecOptions{Args: []interface{}{docID}})
+	if err != nil {
+		return fmt.Errorf("failed to delete lemma frequency: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteLemmaOptimization removes sentence_lemmas rows for docID and its
+// lemma frequencies in the same transaction.
+func (h *DocStore) DeleteLemmaOptimization(ctx context.Context, docID string) (err error) {
+	conn, err := h.pool.Take(ctx)
+	if err != nil {
+		return err
+	}
+	defer h.pool.Put(conn)
+
+	defer sqlitex.Save(conn)(&err)
+
 	err = sqlitex.Execute(conn,
 		`DELETE FROM sentence_lemmas WHERE sentence_rowid IN (SELECT rowid FROM sentences WHERE doc_id = ?)`,
 		&sqlitex.ExecOptions{Args: []interface{}{docID}})
 	if err != nil {
 		return fmt.Errorf("failed to delete lemma optimization: %w", err)
 	}
-	return nil
+
+	return deleteLemmaFreq(conn, docID)
 }
 
 // DeleteLabelsOptimization removes sentence_labels rows for docID.
-func (h *DocStore) DeleteLabelsOptimization(docID string) error {
-	conn, err := h.pool.Take(context.TODO())
+func (h *DocStore) DeleteLabelsOptimization(ctx context.Context, docID string) error {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return err
 	}
@@ -591,8 +993,8 @@ func (h *DocStore) DeleteLabelsOptimization(docID string) error {
 }
 
 // DeleteNlpData removes all sentences rows for docID.
-func (h *DocStore) DeleteNlpData(docID string) error {
-	conn, err := h.pool.Take(context.TODO())
+func (h *DocStore) DeleteNlpData(ctx context.Context, docID string) error {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return err
 	}
@@ -609,8 +1011,8 @@ func (h *DocStore) DeleteNlpData(docID string) error {
 
 // DeleteMeta removes the docs row for docID.
 // Rows in the labels table are shared across documents and are not touched.
-func (h *DocStore) DeleteMeta(docID string) error {
-	conn, err := h.pool.Take(context.TODO())
+func (h *DocStore) DeleteMeta(ctx context.Context, docID string) error {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,86 @@
+package zombiezen
+
+import (
+	"context"
+
+	"github.com/revelaction/segrob/storage"
+	"zombiezen.com/go/sqlite"
+	"zombiezen.com/go/sqlite/sqlitex"
+)
+
+type DrillStore struct {
+	pool *sqlitex.Pool
+}
+
+var _ storage.DrillRepository = (*DrillStore)(nil)
+
+func NewDrillStore(pool *sqlitex.Pool) *DrillStore {
+	return &DrillStore{pool: pool}
+}
+
+func (h *DrillStore) ReadAll(ctx context.Context, userID string) ([]storage.DrillCard, error) {
+	conn, err := h.pool.Take(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer h.pool.Put(conn)
+
+	var cards []storage.DrillCard
+	query := `SELECT doc_id, sentence_id, ease, interval, reps, correct, wrong, due
+		FROM drill_cards WHERE user_id = ?`
+	err = sqlitex.Execute(conn, query, &sqlitex.ExecOptions{
+		Args: []interface{}{userID},
+		ResultFunc: func(stmt *sqlite.Stmt) error {
+			due, parseErr := storage.TimeParse(stmt.ColumnText(7))
+			if parseErr != nil {
+				return parseErr
+			}
+
+			cards = append(cards, storage.DrillCard{
+				DocID:      stmt.ColumnText(0),
+				SentenceID: stmt.ColumnInt(1),
+				Ease:       stmt.ColumnFloat(2),
+				Interval:   stmt.ColumnInt(3),
+				Reps:       stmt.ColumnInt(4),
+				Correct:    stmt.ColumnInt(5),
+				Wrong:      stmt.ColumnInt(6),
+				Due:        due,
+			})
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cards, nil
+}
+
+func (h *DrillStore) Upsert(ctx context.Context, userID string, card storage.DrillCard) error {
+	conn, err := h.pool.Take(ctx)
+	if err != nil {
+		return err
+	}
+	defer h.pool.Put(conn)
+
+	query := `
+		INSERT INTO drill_cards (user_id, doc_id, sentence_id, ease, interval, reps, correct, wrong, due, updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+		ON CONFLICT(user_id, doc_id, sentence_id) DO UPDATE SET
+			ease     = excluded.ease,
+			interval = excluded.interval,
+			reps     = excluded.reps,
+			correct  = excluded.correct,
+			wrong    = excluded.wrong,
+			due      = excluded.due,
+			updated  = excluded.updated
+	`
+
+	return sqlitex.Execute(conn, query, &sqlitex.ExecOptions{
+		Args: []interface{}{
+			userID, card.DocID, card.SentenceID,
+			card.Ease, card.Interval, card.Reps, card.Correct, card.Wrong,
+			storage.TimeFormat(card.Due),
+		},
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"path"
 
+	"zombiezen.com/go/sqlite"
 	"zombiezen.com/go/sqlite/sqlitex"
 )
 
@@ -22,8 +23,99 @@ func NewSchemaManager(pool *sqlitex.Pool) *SchemaManager {
 	return &SchemaManager{pool: pool}
 }
 
+// liveTables are the tables of the live schema (live_canonical.sql and
+// live_optimization.sql).
+var liveTables = []string{
+	"docs", "labels", "sentences", "topics", "drill_cards",
+	"sentence_lemmas", "sentence_labels", "sentence_topics", "sentence_topics_state",
+	"lemma_doc_freq", "lemma_freq",
+}
+
 // Create initializes the database with the given schema.
-func (m *SchemaManager) Create(schemaName string) error {
+func (m *SchemaManager) Create(ctx context.Context, schemaName string) error {
+	// Acquire a connection from the pool; ctx interrupts the script.
+	conn, err := m.pool.Take(ctx)
+	if err != nil {
+		return err
+	}
+	defer m.pool.Put(conn)
+
+	return execScript(conn, schemaName)
+}
+
+// liveColumns are the columns added to the tables of the live schema after
+// their creation.
+var liveColumns = []struct {
+	table, name, def string
+}{
+	{"sentence_topics_state", "exprs", "TEXT NOT NULL DEFAULT ''"},
+}
+
+// Upgrade creates the tables and columns of the live schema missing from a
+// live database initialized by an earlier version. A database without a docs
+// table is not a live database and is left untouched; so is a complete one,
+// without taking the write lock.
+func (m *SchemaManager) Upgrade(ctx context.Context) error {
+	conn, err := m.pool.Take(ctx)
+	if err != nil {
+		return err
+	}
+	defer m.pool.Put(conn)
+
+	tables := make(map[string]bool)
+	err = sqlitex.Execute(conn, "SELECT name FROM sqlite_master WHERE type = 'table'", &sqlitex.ExecOptions{
+		ResultFunc: func(stmt *sqlite.Stmt) error {
+			tables[stmt.ColumnText(0)] = true
+			return nil
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read the schema: %w", err)
+	}
+
+	if !tables["docs"] {
+		return nil
+	}
+
+	complete := true
+	for _, name := range liveTables {
+		complete = complete && tables[name]
+	}
+	if !complete {
+		for _, schemaName := range []string{"live_canonical.sql", "live_optimization.sql"} {
+			if err := execScript(conn, schemaName); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, c := range liveColumns {
+		has := false
+		err = sqlitex.Execute(conn, "SELECT 1 FROM pragma_table_info(?) WHERE name = ?", &sqlitex.ExecOptions{
+			Args: []interface{}{c.table, c.name},
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				has = true
+				return nil
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to read the schema: %w", err)
+		}
+		if has {
+			continue
+		}
+
+		err = sqlitex.Execute(conn, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.name, c.def), nil)
+		if err != nil {
+			return fmt.Errorf("failed to add column %s.%s: %w", c.table, c.name, err)
+		}
+	}
+
+	return nil
+}
+
+// execScript runs an embedded SQL script.
+func execScript(conn *sqlite.Conn, schemaName string) error {
 	// Construct the path within the embedded FS.
 	scriptPath := path.Join("sql", schemaName)
 
@@ -33,13 +125,6 @@ func (m *SchemaManager) Create(schemaName string) error {
 		return fmt.Errorf("failed to read embedded sql file %s: %w", scriptPath, err)
 	}
 
-	// Acquire a connection from the pool using context.TODO().
-	conn, err := m.pool.Take(context.TODO())
-	if err != nil {
-		return err
-	}
-	defer m.pool.Put(conn)
-
 	// Execute the entire script. ExecuteScript handles multi-statement strings.
 	if err := sqlitex.ExecuteScript(conn, string(script), nil); err != nil {
 		return fmt.Errorf("failed to execute script %s: %w", schemaName, err)
@@ -34,3 +34,19 @@ CREATE TABLE IF NOT EXISTS topics (
 );
 
 CREATE INDEX IF NOT EXISTS idx_topics_user_id ON topics(user_id);
+
+-- Drill state: one row per user and drilled sentence. Holds the score and the
+-- spaced-repetition (SM-2) schedule of the sentence for that user.
+CREATE TABLE IF NOT EXISTS drill_cards (
+    user_id     TEXT NOT NULL DEFAULT '',
+    doc_id      TEXT NOT NULL,
+    sentence_id INTEGER NOT NULL,
+    ease        REAL NOT NULL DEFAULT 2.5,
+    interval    INTEGER NOT NULL DEFAULT 0, -- days until the next review
+    reps        INTEGER NOT NULL DEFAULT 0, -- consecutive successful reviews
+    correct     INTEGER NOT NULL DEFAULT 0,
+    wrong       INTEGER NOT NULL DEFAULT 0,
+    due         TEXT NOT NULL,
+    updated     TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    PRIMARY KEY (user_id, doc_id, sentence_id)
+);
@@ -18,3 +18,49 @@ CREATE INDEX IF NOT EXISTS idx_label_rowid ON sentence_labels(label_id, sentence
 -- Reverse indexes (for EXISTS probes in FindCandidates)
 CREATE INDEX IF NOT EXISTS idx_rowid_lemma ON sentence_lemmas(sentence_rowid, lemma);
 CREATE INDEX IF NOT EXISTS idx_rowid_label ON sentence_labels(sentence_rowid, label_id);
+
+-- Materialized sentence→topic index of the default topics (user_id '').
+-- One row per topic and matching sentence. Rows follow a renamed topic (same
+-- id) and are removed with it.
+CREATE TABLE IF NOT EXISTS sentence_topics (
+    topic_id        INTEGER NOT NULL,
+    sentence_rowid  INTEGER NOT NULL,
+    PRIMARY KEY (topic_id, sentence_rowid),
+    FOREIGN KEY (topic_id)       REFERENCES topics(id) ON DELETE CASCADE,
+    FOREIGN KEY (sentence_rowid) REFERENCES sentences(rowid)
+) WITHOUT ROWID;
+
+CREATE INDEX IF NOT EXISTS idx_rowid_topic ON sentence_topics(sentence_rowid, topic_id);
+
+-- Index state per topic: the topics.updated and topics.exprs values the rows
+-- were computed from. The topic is stale when either differs: updated has
+-- second resolution, two edits in the same second only differ in exprs.
+CREATE TABLE IF NOT EXISTS sentence_topics_state (
+    topic_id  INTEGER PRIMARY KEY,
+    updated   TEXT NOT NULL,
+    exprs     TEXT NOT NULL DEFAULT '',
+    indexed   TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    FOREIGN KEY (topic_id) REFERENCES topics(id) ON DELETE CASCADE
+);
+
+-- Lemma frequencies per document, written by publish and removed by
+-- unpublish. tokens counts the occurrences, sentences the sentences
+-- containing the lemma (as in sentence_lemmas).
+CREATE TABLE IF NOT EXISTS lemma_doc_freq (
+    doc_id     TEXT NOT NULL,
+    lemma      TEXT NOT NULL,
+    tokens     INTEGER NOT NULL,
+    sentences  INTEGER NOT NULL,
+    PRIMARY KEY (doc_id, lemma),
+    FOREIGN KEY (doc_id) REFERENCES docs(id)
+) WITHOUT ROWID;
+
+-- Corpus-wide totals of lemma_doc_freq, kept in step with it.
+CREATE TABLE IF NOT EXISTS lemma_freq (
+    lemma      TEXT PRIMARY KEY,
+    tokens     INTEGER NOT NULL,
+    sentences  INTEGER NOT NULL,
+    docs       INTEGER NOT NULL
+) WITHOUT ROWID;
+
+CREATE INDEX IF NOT EXISTS idx_lemma_freq_tokens ON lemma_freq(tokens);
@@ -0,0 +1,372 @@
+package zombiezen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	sent "github.com/revelaction/segrob/sentence"
+	"github.com/revelaction/segrob/storage"
+	"github.com/revelaction/segrob/topic"
+	"zombiezen.com/go/sqlite"
+	"zombiezen.com/go/sqlite/sqlitex"
+)
+
+// TopicIndexStore reads and writes the sentence→topic index of the live
+// database (sentence_topics and sentence_topics_state).
+type TopicIndexStore struct {
+	pool *sqlitex.Pool
+}
+
+var _ storage.TopicIndexRepository = (*TopicIndexStore)(nil)
+
+func NewTopicIndexStore(pool *sqlitex.Pool) *TopicIndexStore {
+	return &TopicIndexStore{pool: pool}
+}
+
+func (h *TopicIndexStore) IndexState(ctx context.Context) ([]storage.TopicIndexState, error) {
+	conn, err := h.pool.Take(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer h.pool.Put(conn)
+
+	var states []storage.TopicIndexState
+	err = sqlitex.Execute(conn,
+		`SELECT t.name, t.updated, COALESCE(s.updated, ''),
+		        (SELECT COUNT(*) FROM sentence_topics WHERE topic_id = t.id),
+		        COALESCE(s.exprs = t.exprs, 0)
+		 FROM topics AS t
+		 LEFT JOIN sentence_topics_state AS s ON s.topic_id = t.id
+		 WHERE t.user_id = ''
+		 ORDER BY t.name`,
+		&sqlitex.ExecOptions{
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				updated, err := storage.TimeParse(stmt.ColumnText(1))
+				if err != nil {
+					return err
+				}
+				indexed, err := storage.TimeParse(stmt.ColumnText(2))
+				if err != nil {
+					return err
+				}
+				states = append(states, storage.TopicIndexState{
+					Name:      stmt.ColumnText(0),
+					Updated:   updated,
+					Indexed:   indexed,
+					SameExprs: stmt.ColumnBool(4),
+					Sentences: stmt.ColumnInt(3),
+				})
+				return nil
+			},
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return states, nil
+}
+
+func (h *TopicIndexStore) SentenceTopics(ctx context.Context, docID string, sentenceID int) ([]string, error) {
+	conn, err := h.pool.Take(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer h.pool.Put(conn)
+
+	var names []string
+	err = sqlitex.Execute(conn,
+		`SELECT t.name
+		 FROM sentences AS s
+		 JOIN sentence_topics AS st ON st.sentence_rowid = s.rowid
+		 JOIN topics AS t ON t.id = st.topic_id
+		 WHERE s.doc_id = ? AND s.sentence_id = ? AND s.doc_id IN (`+liveDocs+`)
+		 ORDER BY t.name`,
+		&sqlitex.ExecOptions{
+			Args: []interface{}{docID, sentenceID},
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				names = append(names, stmt.ColumnText(0))
+				return nil
+			},
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+func (h *TopicIndexStore) TopicSentences(ctx context.Context, name string, labelIDs []int, after storage.Cursor, limit int, onSentence func(sent.Sentence) error) (storage.Cursor, error) {
+	conn, err := h.pool.Take(ctx)
+	if err != nil {
+		return after, err
+	}
+	defer h.pool.Put(conn)
+
+	topicID, _, _, err := h.readTopic(conn, name)
+	if err != nil {
+		return after, err
+	}
+
+	// Same shape as the candidate query of DocStore: the topic drives the
+	// scan, labels are EXISTS probes on the reverse index.
+	var queryBuilder strings.Builder
+	args := []interface{}{topicID, int64(after)}
+	queryBuilder.WriteString("SELECT sentence_rowid FROM sentence_topics AS s_outer WHERE topic_id = ? AND sentence_rowid > ?")
+	queryBuilder.WriteString(" AND (SELECT doc_id FROM sentences WHERE rowid = s_outer.sentence_rowid) IN (" + liveDocs + ")")
+	for _, labelID := range labelIDs {
+		queryBuilder.WriteString(" AND EXISTS (SELECT 1 FROM sentence_labels WHERE sentence_rowid = s_outer.sentence_rowid AND label_id = ?)")
+		args = append(args, labelID)
+	}
+	queryBuilder.WriteString(" ORDER BY sentence_rowid ASC LIMIT ?")
+	args = append(args, limit)
+
+	var rowIDs []int64
+	err = sqlitex.Execute(conn, queryBuilder.String(), &sqlitex.ExecOptions{
+		Args: args,
+		ResultFunc: func(stmt *sqlite.Stmt) error {
+			rowIDs = append(rowIDs, stmt.ColumnInt64(0))
+			return nil
+		},
+	})
+	if ctx.Err() != nil {
+		return after, ctx.Err()
+	}
+	if err != nil {
+		return after, err
+	}
+
+	if len(rowIDs) == 0 {
+		return after, nil
+	}
+
+	return fetchSentences(ctx, conn, rowIDs, after, onSentence)
+}
+
+func (h *TopicIndexStore) TopicDocCounts(ctx context.Context, name string) (map[string]int, error) {
+	conn, err := h.pool.Take(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer h.pool.Put(conn)
+
+	topicID, _, _, err := h.readTopic(conn, name)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	err = sqlitex.Execute(conn,
+		`SELECT s.doc_id, COUNT(*)
+		 FROM sentence_topics AS st
+		 JOIN sentences AS s ON s.rowid = st.sentence_rowid
+		 WHERE st.topic_id = ? AND s.doc_id IN (`+liveDocs+`)
+		 GROUP BY s.doc_id`,
+		&sqlitex.ExecOptions{
+			Args: []interface{}{topicID},
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				counts[stmt.ColumnText(0)] = stmt.ColumnInt(1)
+				return nil
+			},
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+func (h *TopicIndexStore) HasTopicIndex(ctx context.Context, docID string) (bool, error) {
+	conn, err := h.pool.Take(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer h.pool.Put(conn)
+
+	var has bool
+	err = sqlitex.Execute(conn,
+		`SELECT 1 FROM sentence_topics
+         WHERE sentence_rowid IN (SELECT rowid FROM sentences WHERE doc_id = ?)
+         LIMIT 1`,
+		&sqlitex.ExecOptions{
+			Args:       []interface{}{docID},
+			ResultFunc: func(stmt *sqlite.Stmt) error { has = true; return nil },
+		})
+	return has, err
+}
+
+func (h *TopicIndexStore) WriteTopicIndex(ctx context.Context, tp topic.Topic, rowids []int64) (err error) {
+	conn, err := h.pool.Take(ctx)
+	if err != nil {
+		return err
+	}
+	defer h.pool.Put(conn)
+
+	defer sqlitex.Save(conn)(&err)
+
+	topicID, updated, exprs, err := h.readTopic(conn, tp.Name)
+	if err != nil {
+		return err
+	}
+
+	same, err := sameExprs(exprs, tp.Exprs)
+	if err != nil {
+		return err
+	}
+	if !same {
+		return fmt.Errorf("%w: %s", storage.ErrTopicChanged, tp.Name)
+	}
+
+	err = sqlitex.Execute(conn, "DELETE FROM sentence_topics WHERE topic_id = ?", &sqlitex.ExecOptions{
+		Args: []interface{}{topicID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete topic index: %w", err)
+	}
+
+	for _, rowid := range rowids {
+		err = sqlitex.Execute(conn, "INSERT OR IGNORE INTO sentence_topics (topic_id, sentence_rowid) VALUES (?, ?)", &sqlitex.ExecOptions{
+			Args: []interface{}{topicID, rowid},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to insert topic index: %w", err)
+		}
+	}
+
+	return sqlitex.Execute(conn,
+		`INSERT INTO sentence_topics_state (topic_id, updated, exprs)
+		 SELECT id, ?, exprs FROM topics WHERE id = ?
+		 ON CONFLICT(topic_id) DO UPDATE SET
+		     updated = excluded.updated,
+		     exprs = excluded.exprs,
+		     indexed = excluded.indexed`,
+		&sqlitex.ExecOptions{
+			Args: []interface{}{updated, topicID},
+		})
+}
+
+func (h *TopicIndexStore) WriteDocTopicIndex(ctx context.Context, docID string, matches []storage.TopicSentenceIDs) (err error) {
+	conn, err := h.pool.Take(ctx)
+	if err != nil {
+		return err
+	}
+	defer h.pool.Put(conn)
+
+	defer sqlitex.Save(conn)(&err)
+
+	for _, m := range matches {
+		var topicID int64
+		var exprsJSON string
+		found := false
+		err = sqlitex.Execute(conn,
+			`SELECT t.id, t.exprs FROM topics AS t
+			 JOIN sentence_topics_state AS s ON s.topic_id = t.id
+			 WHERE t.user_id = '' AND t.name = ?`,
+			&sqlitex.ExecOptions{
+				Args: []interface{}{m.Topic.Name},
+				ResultFunc: func(stmt *sqlite.Stmt) error {
+					topicID = stmt.ColumnInt64(0)
+					exprsJSON = stmt.ColumnText(1)
+					found = true
+					return nil
+				},
+			})
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+
+		var exprs []topic.TopicExpr
+		if err = json.Unmarshal([]byte(exprsJSON), &exprs); err != nil {
+			return err
+		}
+		var same bool
+		same, err = sameExprs(exprs, m.Topic.Exprs)
+		if err != nil {
+			return err
+		}
+		if !same {
+			continue
+		}
+
+		for _, sentenceID := range m.SentenceIDs {
+			err = sqlitex.Execute(conn,
+				`INSERT OR IGNORE INTO sentence_topics (topic_id, sentence_rowid)
+				 SELECT ?, rowid FROM sentences WHERE doc_id = ? AND sentence_id = ?`,
+				&sqlitex.ExecOptions{
+					Args: []interface{}{topicID, docID, sentenceID},
+				})
+			if err != nil {
+				return fmt.Errorf("failed to insert topic index: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// DeleteDocTopicIndex removes sentence_topics rows for docID.
+func (h *TopicIndexStore) DeleteDocTopicIndex(ctx context.Context, docID string) error {
+	conn, err := h.pool.Take(ctx)
+	if err != nil {
+		return err
+	}
+	defer h.pool.Put(conn)
+
+	err = sqlitex.Execute(conn,
+		`DELETE FROM sentence_topics WHERE sentence_rowid IN (SELECT rowid FROM sentences WHERE doc_id = ?)`,
+		&sqlitex.ExecOptions{Args: []interface{}{docID}})
+	if err != nil {
+		return fmt.Errorf("failed to delete topic index: %w", err)
+	}
+	return nil
+}
+
+// readTopic returns the id, updated timestamp and expressions of a default
+// topic.
+func (h *TopicIndexStore) readTopic(conn *sqlite.Conn, name string) (int64, string, []topic.TopicExpr, error) {
+	var id int64
+	var updated, exprsJSON string
+	found := false
+	err := sqlitex.Execute(conn, "SELECT id, updated, exprs FROM topics WHERE user_id = '' AND name = ?", &sqlitex.ExecOptions{
+		Args: []interface{}{name},
+		ResultFunc: func(stmt *sqlite.Stmt) error {
+			id = stmt.ColumnInt64(0)
+			updated = stmt.ColumnText(1)
+			exprsJSON = stmt.ColumnText(2)
+			found = true
+			return nil
+		},
+	})
+	if err != nil {
+		return 0, "", nil, err
+	}
+	if !found {
+		return 0, "", nil, fmt.Errorf("topic %w: %s", storage.ErrNotFound, name)
+	}
+
+	var exprs []topic.TopicExpr
+	if err := json.Unmarshal([]byte(exprsJSON), &exprs); err != nil {
+		return 0, "", nil, err
+	}
+
+	return id, updated, exprs, nil
+}
+
+// sameExprs compares two expression lists by their JSON encoding, the form
+// they are stored in.
+func sameExprs(a, b []topic.TopicExpr) (bool, error) {
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(ja, jb), nil
+}
@@ -28,8 +28,8 @@ func NewCorpusTopicStore(pool *sqlitex.Pool) *TopicStore {
 	return &TopicStore{pool: pool, tableName: "corpus_topics"}
 }
 
-func (h *TopicStore) ReadAll(userID string) (topic.Library, error) {
-	conn, err := h.pool.Take(context.TODO())
+func (h *TopicStore) ReadAll(ctx context.Context, userID string) (topic.Library, error) {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -61,8 +61,8 @@ func (h *TopicStore) ReadAll(userID string) (topic.Library, error) {
 	return topics, nil
 }
 
-func (h *TopicStore) Read(userID string, name string) (topic.Topic, error) {
-	conn, err := h.pool.Take(context.TODO())
+func (h *TopicStore) Read(ctx context.Context, userID string, name string) (topic.Topic, error) {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return topic.Topic{}, err
 	}
@@ -94,7 +94,7 @@ func (h *TopicStore) Read(userID string, name string) (topic.Topic, error) {
 	}
 
 	if !found {
-		return topic.Topic{}, fmt.Errorf("topic not found: %s", name)
+		return topic.Topic{}, fmt.Errorf("topic %w: %s", storage.ErrNotFound, name)
 	}
 
 	return t, nil
@@ -108,7 +108,7 @@ func (h *TopicStore) Read(userID string, name string) (topic.Topic, error) {
 //     the result. If fn returns storage.ErrNoChange the write is skipped.
 //
 //     Example — append an expression:
-//     store.Upsert(userID, topic.Topic{Name: "my-topic"}, func(t topic.Topic) (topic.Topic, error) {
+//     store.Upsert(ctx, userID, topic.Topic{Name: "my-topic"}, func(t topic.Topic) (topic.Topic, error) {
 //         t.Exprs = append(t.Exprs, newExpr)
 //         return t, nil
 //     })
@@ -117,8 +117,8 @@ func (h *TopicStore) Read(userID string, name string) (topic.Topic, error) {
 //     DO UPDATE, which fully replaces the exprs column when a row with the same user_id +
 //     name already exists. No SELECT reads the current row. Intended for bulk ingestion
 //     where the input is the sole source of truth for every topic it contains.
-func (h *TopicStore) Upsert(userID string, tp topic.Topic, fn func(topic.Topic) (topic.Topic, error)) (result topic.Topic, err error) {
-	conn, err := h.pool.Take(context.TODO())
+func (h *TopicStore) Upsert(ctx context.Context, userID string, tp topic.Topic, fn func(topic.Topic) (topic.Topic, error)) (result topic.Topic, err error) {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return topic.Topic{}, err
 	}
@@ -191,8 +191,8 @@ func (h *TopicStore) Upsert(userID string, tp topic.Topic, fn func(topic.Topic)
 	return result, nil
 }
 
-func (h *TopicStore) Rename(userID string, oldName string, newName string) error {
-	conn, err := h.pool.Take(context.TODO())
+func (h *TopicStore) Rename(ctx context.Context, userID string, oldName string, newName string) error {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return err
 	}
@@ -210,8 +210,8 @@ func (h *TopicStore) Rename(userID string, oldName string, newName string) error
 	})
 }
 
-func (h *TopicStore) CopyDefault(userID string) error {
-	conn, err := h.pool.Take(context.TODO())
+func (h *TopicStore) CopyDefault(ctx context.Context, userID string) error {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return err
 	}
@@ -230,8 +230,8 @@ func (h *TopicStore) CopyDefault(userID string) error {
 	return err
 }
 
-func (h *TopicStore) Delete(userID string, name string) error {
-	conn, err := h.pool.Take(context.TODO())
+func (h *TopicStore) Delete(ctx context.Context, userID string, name string) error {
+	conn, err := h.pool.Take(ctx)
 	if err != nil {
 		return err
 	}
@@ -1,8 +1,10 @@
 package storage
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"sort"
 	"time"
 
 	sent "github.com/revelaction/segrob/sentence"
@@ -12,19 +14,19 @@ import (
 // TopicReader defines read operations for topic storage
 type TopicReader interface {
 	// ReadAll returns all topics from storage
-	ReadAll(userID string) (topic.Library, error)
+	ReadAll(ctx context.Context, userID string) (topic.Library, error)
 
 	// Read returns a single topic by name
-	Read(userID string, name string) (topic.Topic, error)
+	Read(ctx context.Context, userID string, name string) (topic.Topic, error)
 }
 
 // TopicWriter defines write operations for topic storage
 type TopicWriter interface {
-	Upsert(userID string, tp topic.Topic, fn func(topic.Topic) (topic.Topic, error)) (topic.Topic, error)
-	Rename(userID string, oldName string, newName string) error
-	CopyDefault(userID string) error
+	Upsert(ctx context.Context, userID string, tp topic.Topic, fn func(topic.Topic) (topic.Topic, error)) (topic.Topic, error)
+	Rename(ctx context.Context, userID string, oldName string, newName string) error
+	CopyDefault(ctx context.Context, userID string) error
 	// Delete removes a topic from storage by name
-	Delete(userID string, name string) error
+	Delete(ctx context.Context, userID string, name string) error
 }
 
 // TopicRepository combines read and write operations
@@ -33,10 +35,123 @@ type TopicRepository interface {
 	TopicWriter
 }
 
+// TopicIndexState is the state of a default topic in the sentence→topic
+// index.
+type TopicIndexState struct {
+	Name      string
+	Updated   time.Time // last change of the topic
+	Indexed   time.Time // Updated of the topic when it was indexed, zero if never
+	SameExprs bool      // the topic had its current expressions when indexed
+	Sentences int       // number of indexed sentences
+}
+
+// IsIndexed reports whether the topic has been indexed at all.
+func (s TopicIndexState) IsIndexed() bool {
+	return !s.Indexed.IsZero()
+}
+
+// Fresh reports whether the index rows of the topic reflect its current
+// expressions. Updated has second resolution, so the expressions are
+// compared too.
+func (s TopicIndexState) Fresh() bool {
+	return s.IsIndexed() && s.SameExprs && s.Indexed.Equal(s.Updated)
+}
+
+// TopicSentenceIDs are the sentences of a document matching a topic.
+type TopicSentenceIDs struct {
+	Topic       topic.Topic
+	SentenceIDs []int
+}
+
+// TopicIndexReader defines read operations for the sentence→topic index.
+// Only the default topics (user_id "") are indexed. Publish writes the rows
+// of a document before its live switch, so the reads skip the documents that
+// are not live.
+type TopicIndexReader interface {
+	// IndexState returns the index state of every default topic.
+	IndexState(ctx context.Context) ([]TopicIndexState, error)
+
+	// SentenceTopics returns the names of the indexed topics matching the
+	// sentence.
+	SentenceTopics(ctx context.Context, docID string, sentenceID int) ([]string, error)
+
+	// TopicSentences is the inverse query: it passes the indexed sentences
+	// of the topic having ALL labelIDs to onSentence, in rowid order, with
+	// the cursor semantics of DocReader.FindCandidates.
+	TopicSentences(ctx context.Context, name string, labelIDs []int, after Cursor, limit int, onSentence func(sent.Sentence) error) (Cursor, error)
+
+	// TopicDocCounts returns the number of indexed sentences of the topic
+	// per document ID.
+	TopicDocCounts(ctx context.Context, name string) (map[string]int, error)
+
+	// HasTopicIndex returns true if at least one sentence_topics row exists
+	// for the given doc ID.
+	HasTopicIndex(ctx context.Context, docID string) (bool, error)
+}
+
+// TopicIndexWriter defines write operations for the sentence→topic index.
+type TopicIndexWriter interface {
+	// WriteTopicIndex replaces the rows of the topic with the given
+	// sentence rowids and marks the topic fresh. It fails with
+	// ErrTopicChanged if the expressions of tp are no longer those stored.
+	WriteTopicIndex(ctx context.Context, tp topic.Topic, rowids []int64) error
+
+	// WriteDocTopicIndex adds the rows of a document for already indexed
+	// topics. Topics that are not indexed, or whose expressions changed, are
+	// skipped: their index is missing or stale anyway.
+	WriteDocTopicIndex(ctx context.Context, docID string, matches []TopicSentenceIDs) error
+
+	// DeleteDocTopicIndex removes all sentence_topics rows for the given docID.
+	DeleteDocTopicIndex(ctx context.Context, docID string) error
+}
+
+// TopicIndexRepository combines read and write operations
+type TopicIndexRepository interface {
+	TopicIndexReader
+	TopicIndexWriter
+}
+
+// DrillCard holds the drill score and the spaced-repetition schedule of a
+// sentence for a user. Sentences are identified by (DocID, SentenceID), which
+// survive a republish of the document, unlike the sentence rowid.
+type DrillCard struct {
+	DocID      string
+	SentenceID int
+	Ease       float64 // SM-2 easiness factor
+	Interval   int     // days until the next review
+	Reps       int     // consecutive successful reviews
+	Correct    int
+	Wrong      int
+	Due        time.Time
+}
+
+// DrillReader defines read operations for the drill state
+type DrillReader interface {
+	// ReadAll returns all the drill cards of the user
+	ReadAll(ctx context.Context, userID string) ([]DrillCard, error)
+}
+
+// DrillWriter defines write operations for the drill state
+type DrillWriter interface {
+	// Upsert creates or replaces the drill card of the user for
+	// (card.DocID, card.SentenceID).
+	Upsert(ctx context.Context, userID string, card DrillCard) error
+}
+
+// DrillRepository combines read and write operations
+type DrillRepository interface {
+	DrillReader
+	DrillWriter
+}
+
 // SchemaManager defines operations for managing the database schema/lifecycle.
 type SchemaManager interface {
 	// Create applies the necessary schema definitions to the database.
-	Create(schemaName string) error
+	Create(ctx context.Context, schemaName string) error
+
+	// Upgrade brings a live database initialized by an earlier version to
+	// the current schema. Other databases are left untouched.
+	Upgrade(ctx context.Context) error
 }
 
 // Cursor for paginated lemma-based queries
@@ -49,77 +164,173 @@ type SentenceIngest struct {
 	Tokens json.RawMessage `json:"tokens"` // Avoids unmarshaling tokens early
 }
 
+// LemmaFreq is the frequency of a lemma in a document or in the corpus.
+type LemmaFreq struct {
+	Lemma     string `json:"lemma"`
+	Tokens    int    `json:"tokens"`    // occurrences
+	Sentences int    `json:"sentences"` // sentences containing the lemma
+	Docs      int    `json:"docs"`      // documents containing the lemma
+}
+
+// CountLemmas returns the lemma frequencies of the sentences of a document,
+// in lemma order. The lemmas of a sentence are the distinct lemmas of its
+// tokens, as the NLP scripts list them.
+func CountLemmas(sentences []sent.Sentence) []LemmaFreq {
+	counts := lemmaCounts{}
+	for _, s := range sentences {
+		seen := make(map[string]bool)
+		for _, t := range s.Tokens {
+			if t.Lemma != "" && !seen[t.Lemma] {
+				seen[t.Lemma] = true
+				counts.get(t.Lemma).Sentences++
+			}
+		}
+		counts.addTokens(s.Tokens)
+	}
+
+	return counts.sorted()
+}
+
+// lemmaCounts are the frequencies of the lemmas of a document.
+type lemmaCounts map[string]*LemmaFreq
+
+func (c lemmaCounts) get(lemma string) *LemmaFreq {
+	f, ok := c[lemma]
+	if !ok {
+		f = &LemmaFreq{Lemma: lemma, Docs: 1}
+		c[lemma] = f
+	}
+	return f
+}
+
+func (c lemmaCounts) addTokens(tokens []sent.Token) {
+	for _, t := range tokens {
+		if t.Lemma != "" {
+			c.get(t.Lemma).Tokens++
+		}
+	}
+}
+
+func (c lemmaCounts) sorted() []LemmaFreq {
+	freqs := make([]LemmaFreq, 0, len(c))
+	for _, f := range c {
+		freqs = append(freqs, *f)
+	}
+	sort.Slice(freqs, func(i, j int) bool { return freqs[i].Lemma < freqs[j].Lemma })
+	return freqs
+}
+
 // DocReader defines read operations for document storage
 type DocReader interface {
 	// List returns document identity metadata (Id, Source).
-	List() ([]sent.Meta, error)
+	List(ctx context.Context) ([]sent.Meta, error)
 
 	// Nlp returns sentences for a document by ID. Labels are not loaded.
 	// sentenceOffset defines the inclusive range limit relative to sentenceStartIndex.
 	// If sentenceOffset is nil, retrieves all sentences from sentenceStartIndex to the end.
-	Nlp(id string, sentenceStartIndex int, sentenceOffset *int) ([]sent.Sentence, error)
+	Nlp(ctx context.Context, id string, sentenceStartIndex int, sentenceOffset *int) ([]sent.Sentence, error)
 
 	// FindCandidates returns sentence candidates matching ALL given lemmas
 	// AND ALL labelIDs. The caller uses ListLabels() to obtain IDs.
-	FindCandidates(lemmas []string, labelIDs []int, after Cursor, limit int, onCandidate func(sent.Sentence) error) (Cursor, error)
+	// Cancelling ctx interrupts the scan; the returned error is then
+	// ctx.Err() and the cursor is the one of the last completed batch.
+	FindCandidates(ctx context.Context, lemmas []string, labelIDs []int, after Cursor, limit int, onCandidate func(sent.Sentence) error) (Cursor, error)
 
 	// ListLabels returns all labels (ID and Name). If labelSubStr is not empty,
 	// only labels whose name contains the substring are returned.
-	ListLabels(labelSubStr string) (sent.Labels, error)
+	ListLabels(ctx context.Context, labelSubStr string) (sent.Labels, error)
 
 	// HasSentences returns true if at least one sentence exists for the given doc ID.
-	HasSentences(id string) (bool, error)
+	HasSentences(ctx context.Context, id string) (bool, error)
 
 	// HasLabelsOptimization returns true if at least one sentence_labels row exists for the given doc ID.
-	HasLabelsOptimization(id string) (bool, error)
+	HasLabelsOptimization(ctx context.Context, id string) (bool, error)
 
 	// HasLemmaOptimization returns true if at least one sentence_lemmas row exists for the given doc ID.
-	HasLemmaOptimization(id string) (bool, error)
+	HasLemmaOptimization(ctx context.Context, id string) (bool, error)
 
 	// Exists returns true if a document with the given ID is present in the docs table.
-	Exists(id string) (bool, error)
+	Exists(ctx context.Context, id string) (bool, error)
 
 	// SentenceRowidRange returns the minimum and maximum sentence_rowid
 	// for the given labelID. This defines the absolute boundaries for
 	// a book (label), allowing samplers to initialize random cursors
 	// and optimize FindCandidates scanning.
-	SentenceRowidRange(labelID int) (minRowid int64, maxRowid int64, err error)
+	SentenceRowidRange(ctx context.Context, labelID int) (minRowid int64, maxRowid int64, err error)
+
+	// SentenceCounts returns the number of sentences of each live document,
+	// keyed by doc ID. Documents that are not live are absent.
+	SentenceCounts(ctx context.Context) (map[string]int, error)
+
+	// CountSentences returns the number of sentences of the live documents
+	// having ALL labelIDs, or of all of them if labelIDs is empty.
+	CountSentences(ctx context.Context, labelIDs []int) (int, error)
+
+	// LemmaSentenceCounts returns the number of sentences containing each
+	// of the given lemmas, among the sentences having ALL labelIDs. Lemmas
+	// found in no sentence are absent from the map. Without labelIDs the
+	// counts are the lemma frequencies of the live documents (LemmaFreqs).
+	LemmaSentenceCounts(ctx context.Context, lemmas []string, labelIDs []int) (map[string]int, error)
+
+	// LemmaCounts returns the number of sentences containing each lemma,
+	// among the sentences having ALL labelIDs (at least one).
+	LemmaCounts(ctx context.Context, labelIDs []int) (map[string]int, error)
+
+	// MissingLemmaFreq returns the IDs of the live documents without lemma
+	// frequencies.
+	MissingLemmaFreq(ctx context.Context) ([]string, error)
+
+	// LemmaFreqs returns the frequencies of the lemmas starting with prefix,
+	// most frequent first, at most limit (0 = all). If docIDs is empty the
+	// corpus-wide totals are returned, otherwise the sums over the docs.
+	LemmaFreqs(ctx context.Context, prefix string, docIDs []string, limit int) ([]LemmaFreq, error)
+
+	// LabelSentences returns the sentences of the live documents having ALL
+	// labelIDs in rowid order, like FindCandidates without lemmas. If
+	// labelIDs is empty, all of them are returned.
+	LabelSentences(ctx context.Context, labelIDs []int, after Cursor, limit int, onSentence func(sent.Sentence) error) (Cursor, error)
 }
 
 // DocWriter defines write operations for document storage
 type DocWriter interface {
 	// WriteMeta persists document metadata (id, source) and its labels.
-	WriteMeta(id string, source string, labels []string) ([]int, error)
+	WriteMeta(ctx context.Context, id string, source string, labels []string) ([]int, error)
 
 	// UpdateLabels upserts the given labels into the labels table, then updates
 	// the docs row for docID with the new comma-separated label_ids.
 	// Returns the resolved label IDs in the same order as labels.
 	// Note: This operation may leave orphaned entries in the labels table if they
 	// are no longer referenced by any document.
-	UpdateLabels(docID string, labels []string) ([]int, error)
+	UpdateLabels(ctx context.Context, docID string, labels []string) ([]int, error)
 
 	// WriteNlpData persists sentences for the given docID.
-	WriteNlpData(docID string, sentences []SentenceIngest) error
+	WriteNlpData(ctx context.Context, docID string, sentences []SentenceIngest) error
 
 	// WriteLabelsOptimization writes sentence_labels rows for the given docID.
-	WriteLabelsOptimization(docID string, labelIDs []int) error
+	WriteLabelsOptimization(ctx context.Context, docID string, labelIDs []int) error
+
+	// WriteLemmaOptimization writes sentence_lemmas rows for the given docID
+	// and its lemma frequencies (see WriteLemmaFreq) in one transaction.
+	WriteLemmaOptimization(ctx context.Context, docID string, sentences []SentenceIngest, freqs []LemmaFreq) error
 
-	// WriteLemmaOptimization writes sentence_lemmas rows for the given docID.
-	WriteLemmaOptimization(docID string, sentences []SentenceIngest) error
+	// WriteLemmaFreq writes the lemma frequencies of docID and adds them to
+	// the corpus-wide totals.
+	WriteLemmaFreq(ctx context.Context, docID string, freqs []LemmaFreq) error
 
-	// DeleteLemmaOptimization removes all sentence_lemmas rows for the given docID.
+	// DeleteLemmaOptimization removes all sentence_lemmas rows for the given
+	// docID and subtracts its lemma frequencies from the corpus-wide totals.
 	// This is the live switch: after this call the document disappears from FindCandidates.
-	DeleteLemmaOptimization(docID string) error
+	DeleteLemmaOptimization(ctx context.Context, docID string) error
 
 	// DeleteLabelsOptimization removes all sentence_labels rows for the given docID.
-	DeleteLabelsOptimization(docID string) error
+	DeleteLabelsOptimization(ctx context.Context, docID string) error
 
 	// DeleteNlpData removes all sentences rows for the given docID.
-	DeleteNlpData(docID string) error
+	DeleteNlpData(ctx context.Context, docID string) error
 
 	// DeleteMeta removes the docs row for the given docID.
 	// Labels in the labels table are shared and are not removed.
-	DeleteMeta(docID string) error
+	DeleteMeta(ctx context.Context, docID string) error
 }
 
 // DocRepository combines read and write operations
@@ -155,23 +366,23 @@ type CorpusMeta struct {
 // CorpusReader defines read operations for corpus storage
 type CorpusReader interface {
 	// List returns records (metadata only).
-	List() ([]CorpusMeta, error)
+	List(ctx context.Context) ([]CorpusMeta, error)
 
 	// ReadMeta retrieves full metadata for a given document ID.
-	ReadMeta(id string) (CorpusMeta, error)
+	ReadMeta(ctx context.Context, id string) (CorpusMeta, error)
 
 	// ReadTxt retrieves the txt field for a given document ID as raw bytes.
-	ReadTxt(id string) ([]byte, error)
+	ReadTxt(ctx context.Context, id string) ([]byte, error)
 
 	// ReadNlp retrieves the raw NLP JSON payload for a given document ID.
-	ReadNlp(id string) ([]byte, error)
+	ReadNlp(ctx context.Context, id string) ([]byte, error)
 
 	// ListLabels returns all labels (unique names) found in the corpus.
 	// If labelSubStr is not empty, only labels whose name contains the substring are returned.
-	ListLabels(labelSubStr string) ([]string, error)
+	ListLabels(ctx context.Context, labelSubStr string) ([]string, error)
 
 	// Exists returns true if a record with the given ID is present in the docs table.
-	Exists(id string) (bool, error)
+	Exists(ctx context.Context, id string) (bool, error)
 }
 
 // CorpusRecord holds all data collected for a single epub that will be
@@ -224,31 +435,31 @@ func (m CorpusMeta) HasAck() bool {
 // CorpusWriter defines write operations for corpus storage
 type CorpusWriter interface {
 	// WriteStream inserts corpus records yielded by the iterator.
-	WriteStream(seq func(yield func(CorpusRecord, error) bool)) error
+	WriteStream(ctx context.Context, seq func(yield func(CorpusRecord, error) bool)) error
 
 	// WriteNlp stores the NLP JSON payload for the given document ID.
-	WriteNlp(id string, nlp []byte) error
+	WriteNlp(ctx context.Context, id string, nlp []byte) error
 
 	// ClearNlp sets the nlp field to NULL for the given document ID.
-	ClearNlp(id string) error
+	ClearNlp(ctx context.Context, id string) error
 
 	// UpdateTxt updates the txt field and its associated metadata for the given document ID.
-	UpdateTxt(id string, txt []byte, txtHash string, by string, notes string) error
+	UpdateTxt(ctx context.Context, id string, txt []byte, txtHash string, by string, notes string) error
 
 	// AckTxt updates the txt_ack fields for the given document ID.
-	AckTxt(id string, by string) error
+	AckTxt(ctx context.Context, id string, by string) error
 
 	// AckNlp updates the nlp_ack fields for the given document ID.
-	AckNlp(id string, by string) error
+	AckNlp(ctx context.Context, id string, by string) error
 
 	// AddLabel adds labels to a document in the corpus.
-	AddLabel(id string, labels ...string) error
+	AddLabel(ctx context.Context, id string, labels ...string) error
 
 	// DeleteLabel deletes labels from a document in the corpus.
-	DeleteLabel(id string, labels ...string) error
+	DeleteLabel(ctx context.Context, id string, labels ...string) error
 
 	// Delete removes a document from the corpus by its ID.
-	Delete(id string) error
+	Delete(ctx context.Context, id string) error
 }
 
 // CorpusRepository combines read and write operations
@@ -268,3 +479,10 @@ var ErrStopScan = errors.New("stop scan")
 // ErrNoChange signals that the mutation function made no changes to the topic.
 // The store skips the write and returns the current state.
 var ErrNoChange = errors.New("no change")
+
+// ErrNotFound signals that the requested record does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrTopicChanged signals that a topic was modified while it was being
+// indexed.
+var ErrTopicChanged = errors.New("topic changed")
@@ -0,0 +1,122 @@
+// Package vert writes sentences in the vertical format of CWB/CQP and
+// (No)SketchEngine: a token per line, with XML-like structures for the
+// documents and the sentences.
+package vert
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	sent "github.com/revelaction/segrob/sentence"
+)
+
+// Writer writes sentences as a vertical file. Documents are <doc>
+// structures with the doc id and the labels as attributes; sentences are <s>
+// structures with the sentence id. The token columns are word, lemma, POS,
+// tag and dep.
+type Writer struct {
+	w      *bufio.Writer
+	labels map[string][]string
+	docId  string
+	inDoc  bool
+}
+
+// NewWriter returns a Writer taking the doc attributes from labels, the
+// label names of each document keyed by doc ID.
+func NewWriter(w io.Writer, labels map[string][]string) *Writer {
+	return &Writer{w: bufio.NewWriter(w), labels: labels}
+}
+
+// Write writes a sentence, closing the previous document and opening a new
+// one if its document differs from the previous sentence's. Sentences of a
+// document are expected to be consecutive.
+//
+// The words of a multi-word token repeat its text, as segrob stores them.
+func (vw *Writer) Write(s sent.Sentence) error {
+	if !vw.inDoc || s.DocId != vw.docId {
+		if vw.inDoc {
+			fmt.Fprintln(vw.w, "</doc>")
+		}
+		fmt.Fprintf(vw.w, "<doc%s>\n", attrs(s.DocId, vw.labels[s.DocId]))
+		vw.docId = s.DocId
+		vw.inDoc = true
+	}
+
+	fmt.Fprintf(vw.w, "<s id=\"%s-%d\">\n", escape(s.DocId), s.SentenceId)
+	for _, t := range s.Tokens {
+		cols := []string{t.Text, t.Lemma, t.Pos, t.Tag, t.Dep}
+		for i, c := range cols {
+			cols[i] = field(c)
+		}
+		fmt.Fprintln(vw.w, strings.Join(cols, "\t"))
+	}
+	_, err := fmt.Fprintln(vw.w, "</s>")
+	return err
+}
+
+// Flush closes the open document and writes the buffered data to the
+// underlying writer.
+func (vw *Writer) Flush() error {
+	if vw.inDoc {
+		fmt.Fprintln(vw.w, "</doc>")
+		vw.inDoc = false
+	}
+	return vw.w.Flush()
+}
+
+// invalidAttr matches the characters not allowed in attribute names.
+var invalidAttr = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// attrs returns the attributes of a <doc>: the id, then a key per label
+// prefix (creator:borges becomes creator="borges"), in label order. Values of
+// a repeated key are joined with |; labels without prefix go in label.
+func attrs(docId string, labels []string) string {
+	var keys []string
+	values := make(map[string][]string)
+	for _, l := range labels {
+		key, value, ok := strings.Cut(l, ":")
+		if !ok {
+			key, value = "label", l
+		}
+		key = invalidAttr.ReplaceAllString(strings.ToLower(key), "_")
+		if key == "" || key == "id" {
+			key = "label"
+		}
+		if _, seen := values[key]; !seen {
+			keys = append(keys, key)
+		}
+		values[key] = append(values[key], value)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, " id=\"%s\"", escape(docId))
+	for _, k := range keys {
+		fmt.Fprintf(&b, " %s=\"%s\"", k, escape(strings.Join(values[k], "|")))
+	}
+	return b.String()
+}
+
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\"", "&quot;")
+
+// escape escapes the XML special characters, decoded by cwb-encode -x.
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// field escapes a token column: tabs and line breaks become spaces, an empty
+// value is _.
+func field(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return ' '
+		}
+		return r
+	}, s)
+	if s == "" {
+		return "_"
+	}
+	return escape(s)
+}
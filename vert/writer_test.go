@@ -0,0 +1,58 @@
+package vert
+
+import (
+	"strings"
+	"testing"
+
+	sent "github.com/revelaction/segrob/sentence"
+)
+
+func TestWrite(t *testing.T) {
+	labels := map[string][]string{
+		"doc1": {"title:a<b", "creator:borges", "genre:cuento", "genre:ensayo", "favorito"},
+	}
+	sentences := []sent.Sentence{
+		{DocId: "doc1", SentenceId: 0, Tokens: []sent.Token{
+			{Text: "Dámelo", Lemma: "dar", Pos: "VERB", Tag: "VERB__Mood=Imp", Dep: "root"},
+			{Text: "&", Lemma: "&", Pos: "SYM", Tag: "SYM", Dep: "punct"},
+		}},
+		{DocId: "doc1", SentenceId: 1, Tokens: []sent.Token{
+			{Text: "Sí", Lemma: "sí", Pos: "ADV", Tag: "ADV", Dep: "root"},
+		}},
+		{DocId: "doc2", SentenceId: 0, Tokens: []sent.Token{
+			{Text: "No", Lemma: "", Pos: "ADV", Tag: "ADV", Dep: "root"},
+		}},
+	}
+
+	var b strings.Builder
+	w := NewWriter(&b, labels)
+	for _, s := range sentences {
+		if err := w.Write(s); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	want := strings.Join([]string{
+		`<doc id="doc1" title="a&lt;b" creator="borges" genre="cuento|ensayo" label="favorito">`,
+		`<s id="doc1-0">`,
+		"Dámelo\tdar\tVERB\tVERB__Mood=Imp\troot",
+		"&amp;\t&amp;\tSYM\tSYM\tpunct",
+		"</s>",
+		`<s id="doc1-1">`,
+		"Sí\tsí\tADV\tADV\troot",
+		"</s>",
+		"</doc>",
+		`<doc id="doc2">`,
+		`<s id="doc2-0">`,
+		"No\t_\tADV\tADV\troot",
+		"</s>",
+		"</doc>",
+		"",
+	}, "\n")
+	if b.String() != want {
+		t.Fatalf("Write =\n%s\nwant\n%s", b.String(), want)
+	}
+}